@@ -0,0 +1,168 @@
+package sqlittle
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	sdb "github.com/hackborn/sqlittle/db"
+)
+
+// affinity is SQLite's column affinity: the preferred type of the values in a
+// column. See https://sqlite.org/datatype3.html#type_affinity
+type affinity int
+
+const (
+	affBlob affinity = iota // also known as NONE
+	affText
+	affNumeric
+	affInteger
+	affReal
+)
+
+// affinityOf gives the affinity of a declared column type, with the rules
+// from "3.1. Determination Of Column Affinity".
+func affinityOf(typ string) affinity {
+	t := strings.ToUpper(typ)
+	switch {
+	case strings.Contains(t, "INT"):
+		return affInteger
+	case strings.Contains(t, "CHAR"), strings.Contains(t, "CLOB"), strings.Contains(t, "TEXT"):
+		return affText
+	case strings.Contains(t, "BLOB"), t == "":
+		return affBlob
+	case strings.Contains(t, "REAL"), strings.Contains(t, "FLOA"), strings.Contains(t, "DOUB"):
+		return affReal
+	default:
+		return affNumeric
+	}
+}
+
+// apply converts a value the way SQLite does when it's stored in a column with
+// this affinity. SQLite stores REAL values without a fractional part as
+// integers, and this converts them back.
+func (a affinity) apply(v interface{}) interface{} {
+	switch a {
+	case affText:
+		switch v := v.(type) {
+		case int64:
+			return strconv.FormatInt(v, 10)
+		case float64:
+			return realText(v)
+		}
+	case affNumeric, affInteger:
+		switch vt := v.(type) {
+		case string:
+			if n, ok := numericText(vt); ok {
+				return toInteger(n)
+			}
+		case float64:
+			return toInteger(vt)
+		}
+	case affReal:
+		switch vt := v.(type) {
+		case int64:
+			return float64(vt)
+		case string:
+			if n, ok := numericText(vt); ok {
+				if i, ok := n.(int64); ok {
+					return float64(i)
+				}
+				return n
+			}
+		}
+	}
+	return v
+}
+
+// toInteger gives a float64 as an int64 if that doesn't lose anything. int64s
+// are returned as they are.
+func toInteger(v interface{}) interface{} {
+	if f, ok := v.(float64); ok && f >= -1<<63 && f < 1<<63 && f == math.Trunc(f) {
+		return int64(f)
+	}
+	return v
+}
+
+// numericText parses text which is a well-formed number, with optional
+// spaces around it. Integers which fit are an int64, everything else is a
+// float64.
+func numericText(s string) (interface{}, bool) {
+	s = strings.TrimSpace(s)
+	// [+-]digits[.digits][e[+-]digits], with at least one digit before the
+	// exponent
+	i, digits := 0, 0
+	if i < len(s) && (s[i] == '+' || s[i] == '-') {
+		i++
+	}
+	for ; i < len(s) && s[i] >= '0' && s[i] <= '9'; i++ {
+		digits++
+	}
+	isInt := true
+	if i < len(s) && s[i] == '.' {
+		isInt = false
+		for i++; i < len(s) && s[i] >= '0' && s[i] <= '9'; i++ {
+			digits++
+		}
+	}
+	if digits == 0 {
+		return nil, false
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		isInt = false
+		i++
+		if i < len(s) && (s[i] == '+' || s[i] == '-') {
+			i++
+		}
+		exp := 0
+		for ; i < len(s) && s[i] >= '0' && s[i] <= '9'; i++ {
+			exp++
+		}
+		if exp == 0 {
+			return nil, false
+		}
+	}
+	if i != len(s) {
+		return nil, false
+	}
+	if isInt {
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n, true
+		}
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil && !math.IsInf(f, 0) {
+		return nil, false
+	}
+	return f, true
+}
+
+// compare converts a value which is compared with a column with this
+// affinity, as SQLite does in "4.2. Type Conversions Prior To Comparison":
+// numeric columns apply NUMERIC affinity to the value, and TEXT columns apply
+// TEXT affinity.
+func (a affinity) compare(v interface{}) interface{} {
+	switch a {
+	case affInteger, affReal, affNumeric:
+		return affNumeric.apply(v)
+	case affText:
+		return affText.apply(v)
+	default:
+		return v
+	}
+}
+
+// columnAffinity gives the affinity of a column. The rowid is an INTEGER, and
+// unknown columns have no affinity.
+func columnAffinity(s *sdb.Schema, name string) affinity {
+	if n := s.Column(name); n >= 0 {
+		if s.Columns[n].Rowid {
+			return affInteger
+		}
+		return affinityOf(s.Columns[n].Type)
+	}
+	if !s.WithoutRowid && isRowidName(name) {
+		return affInteger
+	}
+	return affBlob
+}
@@ -0,0 +1,85 @@
+package sqlittle
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestAffinityOf(t *testing.T) {
+	for typ, want := range map[string]affinity{
+		"":                 affBlob,
+		"INT":              affInteger,
+		"integer":          affInteger,
+		"TINYINT":          affInteger,
+		"UNSIGNED BIG INT": affInteger,
+		"CHARACTER(20)":    affText,
+		"varchar(255)":     affText,
+		"NATIVE CHARACTER": affText,
+		"CLOB":             affText,
+		"TEXT":             affText,
+		"BLOB":             affBlob,
+		"REAL":             affReal,
+		"DOUBLE PRECISION": affReal,
+		"float":            affReal,
+		"NUMERIC":          affNumeric,
+		"DECIMAL(10,5)":    affNumeric,
+		"BOOLEAN":          affNumeric,
+		"DATETIME":         affNumeric,
+		"STRING":           affNumeric,
+		"FLOATING POINT":   affInteger, // "POINT" has an "INT"
+	} {
+		if have := affinityOf(typ); have != want {
+			t.Errorf("%q: have %d, want %d", typ, have, want)
+		}
+	}
+}
+
+func TestAffinityApply(t *testing.T) {
+	for _, c := range []struct {
+		aff  affinity
+		v    interface{}
+		want interface{}
+	}{
+		{affBlob, int64(1), int64(1)},
+		{affBlob, "12", "12"},
+		{affBlob, 1.0, 1.0},
+
+		{affText, int64(12), "12"},
+		{affText, 1.5, "1.5"},
+		{affText, 2.0, "2.0"},
+		{affText, []byte("b"), []byte("b")},
+		{affText, nil, nil},
+
+		{affNumeric, "12", int64(12)},
+		{affNumeric, " -12 ", int64(-12)},
+		{affNumeric, "3.0", int64(3)},
+		{affNumeric, "1e3", int64(1000)},
+		{affNumeric, "3.5", 3.5},
+		{affNumeric, ".5", 0.5},
+		{affNumeric, "99999999999999999999", 1e20},
+		{affNumeric, 4.0, int64(4)},
+		{affNumeric, 4.25, 4.25},
+		{affNumeric, "12abc", "12abc"},
+		{affNumeric, "1e", "1e"},
+		{affNumeric, ".", "."},
+		{affNumeric, "inf", "inf"},
+		{affNumeric, "0x10", "0x10"},
+		{affNumeric, []byte("12"), []byte("12")},
+		{affNumeric, nil, nil},
+
+		{affInteger, "7", int64(7)},
+		{affInteger, 1e30, 1e30},
+		{affInteger, math.Inf(1), math.Inf(1)},
+
+		{affReal, int64(3), 3.0},
+		{affReal, "3", 3.0},
+		{affReal, "3.25", 3.25},
+		{affReal, "three", "three"},
+		{affReal, 1.5, 1.5},
+	} {
+		if have := c.aff.apply(c.v); !reflect.DeepEqual(have, c.want) {
+			t.Errorf("%d %#v: have %#v, want %#v", c.aff, c.v, have, c.want)
+		}
+	}
+}
@@ -0,0 +1,719 @@
+package sqlittle
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	sdb "github.com/hackborn/sqlittle/db"
+	"github.com/hackborn/sqlittle/sql"
+)
+
+// AggFunc is an aggregate function, for Agg.
+type AggFunc int
+
+const (
+	AggCount AggFunc = iota // COUNT(column), or COUNT(*)
+	AggMin                  // MIN(column)
+	AggMax                  // MAX(column)
+	AggSum                  // SUM(column)
+	AggAvg                  // AVG(column)
+)
+
+func (f AggFunc) String() string {
+	switch f {
+	case AggCount:
+		return "COUNT"
+	case AggMin:
+		return "MIN"
+	case AggMax:
+		return "MAX"
+	case AggSum:
+		return "SUM"
+	case AggAvg:
+		return "AVG"
+	default:
+		return "?"
+	}
+}
+
+// Agg is an aggregate function on a column, for Aggregate().
+type Agg struct {
+	Func   AggFunc
+	Column string // "*" or "" for COUNT(*)
+}
+
+// Count is COUNT(column). Count("*") counts all rows.
+func Count(column string) Agg { return Agg{AggCount, column} }
+
+// Min is MIN(column): the lowest value which isn't NULL, using the collate
+// function of the column.
+func Min(column string) Agg { return Agg{AggMin, column} }
+
+// Max is MAX(column): the highest value which isn't NULL, using the collate
+// function of the column.
+func Max(column string) Agg { return Agg{AggMax, column} }
+
+// Sum is SUM(column). The result is an int64 if all values are integers, and a
+// float64 otherwise. Sum of only NULLs is NULL.
+func Sum(column string) Agg { return Agg{AggSum, column} }
+
+// Avg is AVG(column). The result is a float64, or NULL if there are only NULLs.
+func Avg(column string) Agg { return Agg{AggAvg, column} }
+
+func (a Agg) star() bool {
+	return a.Func == AggCount && (a.Column == "" || a.Column == "*")
+}
+
+// Count returns the number of rows in a table. Same as `SELECT COUNT(*)`.
+//
+// This reads the btree with the fewest columns: an index if there is one which
+// is smaller than the table.
+func (db *DB) Count(table string) (int, error) {
+	return db.CountContext(context.Background(), table)
+}
+
+// CountContext is Count() with a context. See SelectContext().
+func (db *DB) CountContext(ctx context.Context, table string) (n int, err error) {
+	d, c, err := db.rlock(ctx, "Count", table, "")
+	if err != nil {
+		return 0, err
+	}
+	defer c.end(&err)
+
+	s, err := d.Schema(table)
+	if err != nil {
+		return 0, err
+	}
+	n, err = countRows(d, s)
+	c.rows = int64(n) // every row is counted
+	return n, err
+}
+
+// Aggregate calculates the aggregates for every group of rows which have the
+// same groupBy values. The row given to the callback has the groupBy values,
+// followed by the aggregate results. Same as:
+//
+//	SELECT groupBy..., aggs... FROM table GROUP BY groupBy...
+//
+// Values are grouped with the collate function of the column, and all NULLs
+// form a single group. Without groupBy columns there is always a single row,
+// even for an empty table.
+//
+// If there is an index which starts with the groupBy columns (in any order) the
+// groups are read in a single pass, in index order. Otherwise all groups are
+// kept in memory, and they are returned sorted on the groupBy values.
+//
+// Without groupBy columns COUNT(*) reads the smallest btree, and MIN() and
+// MAX() read a single record from an index on the column, when possible.
+//
+//	db.Aggregate("words", []string{"length"}, []Agg{Count("*"), Max("word")}, cb)
+func (db *DB) Aggregate(table string, groupBy []string, aggs []Agg, cb RowCB) error {
+	return db.AggregateContext(context.Background(), table, groupBy, aggs, cb)
+}
+
+// AggregateContext is Aggregate() with a context. See SelectContext().
+func (db *DB) AggregateContext(ctx context.Context, table string, groupBy []string, aggs []Agg, cb RowCB) (err error) {
+	d, c, err := db.rlock(ctx, "Aggregate", table, "")
+	if err != nil {
+		return err
+	}
+	defer c.end(&err)
+	cb = c.count(cb)
+
+	s, err := d.Schema(table)
+	if err != nil {
+		return err
+	}
+
+	a, err := newAggregator(s, groupBy, aggs, cb)
+	if err != nil {
+		return err
+	}
+	if len(groupBy) == 0 {
+		row, ok, err := a.quick(d)
+		if err != nil {
+			return err
+		}
+		if ok {
+			cb(row)
+			return nil
+		}
+	}
+
+	p := groupPlan(s, groupBy, a.columns)
+	a.hash = p == nil
+	if a.hash {
+		p = &plan{kind: planScan, schema: s}
+	}
+	e := &emitter{ncols: len(a.columns), cb: a.add}
+	c.e = e
+	if err := p.run(d, e, a.columns); err != nil {
+		return err
+	}
+	return a.flush()
+}
+
+// agg is an Agg with the column looked up.
+type agg struct {
+	Agg
+	pos     int // in the row, -1 for COUNT(*)
+	collate string
+}
+
+// aggregator puts rows in groups, and keeps the aggregate state per group.
+type aggregator struct {
+	schema   *sdb.Schema
+	ngroup   int
+	collates []string // for the groupBy columns
+	aggs     []agg
+	columns  []string // columns to load: the groupBy columns, then the agg columns
+
+	cb     RowCB
+	hash   bool
+	groups map[string]*group // for hashing
+	order  []*group          // for hashing, in the order they were found
+	last   *group            // without hashing: the current group
+	err    error
+}
+
+type group struct {
+	values Row
+	states []aggState
+}
+
+func newAggregator(s *sdb.Schema, groupBy []string, aggs []Agg, cb RowCB) (*aggregator, error) {
+	a := &aggregator{
+		schema:  s,
+		cb:      cb,
+		ngroup:  len(groupBy),
+		columns: append([]string(nil), groupBy...),
+		groups:  map[string]*group{},
+	}
+	for _, g := range groupBy {
+		_, _, collate, err := lookupColumn(s, g)
+		if err != nil {
+			return nil, err
+		}
+		a.collates = append(a.collates, collate)
+	}
+	for _, ag := range aggs {
+		if ag.Func < AggCount || ag.Func > AggAvg {
+			return nil, fmt.Errorf("invalid aggregate function: %d", ag.Func)
+		}
+		if ag.star() {
+			a.aggs = append(a.aggs, agg{Agg: ag, pos: -1})
+			continue
+		}
+		_, _, collate, err := lookupColumn(s, ag.Column)
+		if err != nil {
+			return nil, err
+		}
+		a.aggs = append(a.aggs, agg{Agg: ag, pos: len(a.columns), collate: collate})
+		a.columns = append(a.columns, ag.Column)
+	}
+	return a, nil
+}
+
+// quick calculates the aggregates without looking at every row, if all of
+// them are COUNT(*), or MIN() or MAX() on an indexed column. False if that's
+// not possible.
+func (a *aggregator) quick(db *sdb.Database) (Row, bool, error) {
+	for _, ag := range a.aggs {
+		switch {
+		case ag.star():
+		case ag.Func == AggMin || ag.Func == AggMax:
+			if edgeIndex(a.schema, ag.Column) == nil && !edgeRowid(a.schema, ag.Column) {
+				return nil, false, nil
+			}
+		default:
+			return nil, false, nil
+		}
+	}
+
+	row := make(Row, 0, len(a.aggs))
+	for _, ag := range a.aggs {
+		var (
+			v   interface{}
+			err error
+		)
+		if ag.star() {
+			var n int
+			n, err = countRows(db, a.schema)
+			v = int64(n)
+		} else {
+			v, err = edge(db, a.schema, ag.Column, ag.Func == AggMax)
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		row = append(row, v)
+	}
+	return row, true, nil
+}
+
+// add gets a row with the groupBy values followed by the agg values.
+func (a *aggregator) add(r Row) {
+	if a.err != nil {
+		return
+	}
+	g := a.group(r[:a.ngroup])
+	for i, ag := range a.aggs {
+		var v interface{}
+		if ag.pos >= 0 {
+			v = r[ag.pos]
+		}
+		g.states[i].step(ag, v)
+	}
+}
+
+// group finds or makes the group for the values. Without hashing the rows
+// come grouped, so only the last group can match, and the one before is
+// complete.
+func (a *aggregator) group(vs Row) *group {
+	if a.hash {
+		k := groupKey(vs, a.collates)
+		g, ok := a.groups[k]
+		if !ok {
+			g = a.newGroup(vs)
+			a.groups[k] = g
+			a.order = append(a.order, g)
+		}
+		return g
+	}
+	if a.last != nil {
+		if a.same(a.last.values, vs) {
+			return a.last
+		}
+		a.err = a.emit(a.last)
+	}
+	a.last = a.newGroup(vs)
+	return a.last
+}
+
+func (a *aggregator) newGroup(vs Row) *group {
+	return &group{
+		values: copyRow(vs),
+		states: make([]aggState, len(a.aggs)),
+	}
+}
+
+func (a *aggregator) same(x, y Row) bool {
+	for i := range x {
+		if (x[i] == nil) != (y[i] == nil) || sdb.Compare(x[i], y[i], a.collates[i]) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// emit calls the callback for a complete group.
+func (a *aggregator) emit(g *group) error {
+	row := make(Row, 0, a.ngroup+len(a.aggs))
+	row = append(row, g.values...)
+	for i, ag := range a.aggs {
+		v, err := g.states[i].result(ag)
+		if err != nil {
+			return err
+		}
+		row = append(row, v)
+	}
+	a.cb(row)
+	return nil
+}
+
+// flush emits the groups which are left. Call this after the scan is done.
+func (a *aggregator) flush() error {
+	if a.err != nil {
+		return a.err
+	}
+	if !a.hash {
+		if a.last == nil && a.ngroup == 0 {
+			a.last = a.newGroup(nil)
+		}
+		if a.last == nil {
+			return nil
+		}
+		return a.emit(a.last)
+	}
+
+	sort.SliceStable(a.order, func(i, j int) bool {
+		x, y := a.order[i].values, a.order[j].values
+		for k := range x {
+			if n := sdb.Compare(x[k], y[k], a.collates[k]); n != 0 {
+				return n < 0
+			}
+		}
+		return false
+	})
+	for _, g := range a.order {
+		if err := a.emit(g); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// groupKey makes a key from the group values. Values which compare equal give
+// the same key. Unlike in joins NULLs are equal to each other.
+func groupKey(vs Row, collates []string) string {
+	var b strings.Builder
+	for i, v := range vs {
+		writeKey(&b, v, collates[i])
+	}
+	return b.String()
+}
+
+// copyRow copies a row, including []byte values, which might point into a
+// database page.
+func copyRow(r Row) Row {
+	c := make(Row, len(r))
+	for i, v := range r {
+		c[i] = copyValue(v)
+	}
+	return c
+}
+
+func copyValue(v interface{}) interface{} {
+	if b, ok := v.([]byte); ok {
+		return append([]byte(nil), b...)
+	}
+	return v
+}
+
+// aggState is the running state of one aggregate function. Sums follow
+// SQLite's sum(): integers are added as integers until there is a non-integer
+// value, and from then on the sum is a float with Kahan-Babuska-Neumaier
+// compensation.
+type aggState struct {
+	count      int64 // rows, or values which aren't NULL
+	isum       int64
+	rsum, rerr float64
+	approx     bool // the sum is a float
+	overflow   bool // the integer sum overflowed
+	value      interface{}
+}
+
+func (st *aggState) step(a agg, v interface{}) {
+	if a.star() {
+		st.count++
+		return
+	}
+	if v == nil {
+		return
+	}
+	st.count++
+	switch a.Func {
+	case AggMin, AggMax:
+		if st.count == 1 {
+			st.value = copyValue(v)
+			return
+		}
+		n := sdb.Compare(v, st.value, a.collate)
+		if (a.Func == AggMin && n < 0) || (a.Func == AggMax && n > 0) {
+			st.value = copyValue(v)
+		}
+	case AggSum, AggAvg:
+		i, f, isInt := numeric(v)
+		switch {
+		case !st.approx && isInt:
+			if s, ok := addInt64(st.isum, i); ok {
+				st.isum = s
+				return
+			}
+			st.overflow = true
+			st.initFloat(st.isum)
+			st.approx = true
+			st.addInt(i)
+		case !st.approx:
+			st.initFloat(st.isum)
+			st.approx = true
+			st.addFloat(f)
+		case isInt:
+			st.addInt(i)
+		default:
+			st.overflow = false
+			st.addFloat(f)
+		}
+	}
+}
+
+func (st *aggState) result(a agg) (interface{}, error) {
+	switch a.Func {
+	case AggCount:
+		return st.count, nil
+	case AggMin, AggMax:
+		return st.value, nil
+	}
+	if st.count == 0 {
+		return nil, nil
+	}
+	var r float64
+	if st.approx {
+		if st.overflow && a.Func == AggSum {
+			return nil, fmt.Errorf("integer overflow in %s(%s)", a.Func, a.Column)
+		}
+		r = st.rsum
+		if !math.IsInf(st.rerr, 0) && !math.IsNaN(st.rerr) {
+			r += st.rerr
+		}
+	} else {
+		if a.Func == AggSum {
+			return st.isum, nil
+		}
+		r = float64(st.isum)
+	}
+	if a.Func == AggAvg {
+		return r / float64(st.count), nil
+	}
+	return r, nil
+}
+
+// big is the limit from where float64 can't represent every integer anymore.
+const big = 1 << 52
+
+func (st *aggState) initFloat(i int64) {
+	if i <= -big || i >= big {
+		sm := i % 16384
+		st.rsum, st.rerr = float64(i-sm), float64(sm)
+		return
+	}
+	st.rsum, st.rerr = float64(i), 0
+}
+
+func (st *aggState) addInt(i int64) {
+	if i <= -big || i >= big {
+		sm := i % 16384
+		st.addFloat(float64(i - sm))
+		st.addFloat(float64(sm))
+		return
+	}
+	st.addFloat(float64(i))
+}
+
+func (st *aggState) addFloat(f float64) {
+	s := st.rsum
+	t := s + f
+	if math.Abs(s) > math.Abs(f) {
+		st.rerr += (s - t) + f
+	} else {
+		st.rerr += (f - t) + s
+	}
+	st.rsum = t
+}
+
+func addInt64(a, b int64) (int64, bool) {
+	s := a + b
+	if (b > 0 && s < a) || (b < 0 && s > a) {
+		return 0, false
+	}
+	return s, true
+}
+
+var (
+	intPattern  = regexp.MustCompile(`^[ \t\n\f\r\v]*[+-]?[0-9]+[ \t\n\f\r\v]*$`)
+	realPattern = regexp.MustCompile(`^[ \t\n\f\r\v]*[+-]?([0-9]+\.?[0-9]*|\.[0-9]+)([eE][+-]?[0-9]+)?`)
+)
+
+// numeric converts a value the way SQLite does for sum() and avg(). Integers,
+// and text which is an integer, give an int64. Everything else is converted to
+// a float64, using as much of the text as looks like a number.
+func numeric(v interface{}) (int64, float64, bool) {
+	var s string
+	switch v := v.(type) {
+	case int64:
+		return v, 0, true
+	case float64:
+		return 0, v, false
+	case string:
+		if intPattern.MatchString(v) {
+			if i, err := strconv.ParseInt(strings.Trim(v, " \t\n\f\r\v"), 10, 64); err == nil {
+				return i, 0, true
+			}
+		}
+		s = v
+	case []byte:
+		s = string(v)
+	}
+	m := realPattern.FindString(s)
+	if m == "" {
+		return 0, 0, false
+	}
+	f, _ := strconv.ParseFloat(strings.TrimLeft(m, " \t\n\f\r\v"), 64)
+	return 0, f, false
+}
+
+// countRows counts the rows in a table, using the btree with the fewest
+// columns. Partial indexes don't have all rows.
+func countRows(db *sdb.Database, s *sdb.Schema) (int, error) {
+	var best *sdb.SchemaIndex
+	for i := range s.Indexes {
+		ind := &s.Indexes[i]
+		if ind.Where != nil {
+			continue
+		}
+		if best == nil || len(ind.Columns) < len(best.Columns) {
+			best = ind
+		}
+	}
+	if best == nil || len(best.Columns) >= len(s.Columns) {
+		if s.WithoutRowid {
+			t, err := db.NonRowidTable(s.Table)
+			if err != nil {
+				return 0, err
+			}
+			return t.Count()
+		}
+		t, err := db.Table(s.Table)
+		if err != nil {
+			return 0, err
+		}
+		return t.Count()
+	}
+	ind, err := db.Index(best.Index)
+	if err != nil {
+		return 0, err
+	}
+	return ind.Count()
+}
+
+// edgeRowid is true if the column is the rowid of a rowid table.
+func edgeRowid(s *sdb.Schema, column string) bool {
+	_, rowid, _, err := lookupColumn(s, column)
+	return err == nil && rowid && !s.WithoutRowid
+}
+
+// edgeIndex finds an index which starts with the column, with the same
+// collate function. For WITHOUT ROWID tables the primary key is used when
+// possible; it has an empty index name.
+func edgeIndex(s *sdb.Schema, column string) *sdb.SchemaIndex {
+	name, _, collate, err := lookupColumn(s, column)
+	if err != nil {
+		return nil
+	}
+	starts := func(cols []sdb.IndexColumn) bool {
+		return len(cols) > 0 && cols[0].Column != "" &&
+			strings.EqualFold(cols[0].Column, name) &&
+			normCollate(cols[0].Collate) == collate
+	}
+	if s.WithoutRowid && starts(s.PK) {
+		return &sdb.SchemaIndex{Columns: s.PK}
+	}
+	for i := range s.Indexes {
+		ind := &s.Indexes[i]
+		if ind.Where == nil && starts(ind.Columns) {
+			return ind
+		}
+	}
+	return nil
+}
+
+// edge reads MIN() or MAX() from one end of the rowid, or of an index which
+// starts with the column. NULLs are skipped, and the value gets the affinity of
+// the column, since a REAL column stores 3.0 as the integer 3. Check with
+// edgeRowid() and edgeIndex() first.
+func edge(db *sdb.Database, s *sdb.Schema, column string, max bool) (interface{}, error) {
+	if edgeRowid(s, column) {
+		t, err := db.Table(s.Table)
+		if err != nil {
+			return nil, err
+		}
+		var v interface{}
+		cb := func(rowid int64, _ sdb.Record) bool {
+			v = rowid
+			return true
+		}
+		if max {
+			err = t.ScanReverse(cb)
+		} else {
+			err = t.Scan(cb)
+		}
+		return v, err
+	}
+
+	si := edgeIndex(s, column)
+	var (
+		ind *sdb.Index
+		err error
+	)
+	if si.Index == "" {
+		ind, err = db.NonRowidTable(s.Table)
+	} else {
+		ind, err = db.Index(si.Index)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var v interface{}
+	cb := func(r sdb.Record) bool {
+		if len(r) == 0 || r[0] == nil {
+			return false
+		}
+		v = columnAffinity(s, column).apply(copyValue(r[0]))
+		return true
+	}
+	desc := si.Columns[0].SortOrder == sql.Desc
+	switch {
+	case max == desc && !desc:
+		// NULLs sort first, so start after them
+		err = ind.ScanMin(sdb.Key{{V: math.Inf(-1)}}, cb)
+	case max == desc:
+		err = ind.Scan(cb)
+	default:
+		err = ind.ScanReverse(cb)
+	}
+	return v, err
+}
+
+// groupPlan finds an index which returns the rows grouped: its first columns
+// are the groupBy columns, in any order, with the same collate functions. A
+// single rowid column is grouped by a table scan. Returns nil if there is no
+// such index.
+func groupPlan(s *sdb.Schema, groupBy []string, columns []string) *plan {
+	if len(groupBy) == 0 || (len(groupBy) == 1 && edgeRowid(s, groupBy[0])) {
+		return &plan{kind: planScan, schema: s}
+	}
+
+	var best *plan
+	if s.WithoutRowid && groups(s, s.PK, groupBy) {
+		best = &plan{kind: planIndex, schema: s, index: &sdb.SchemaIndex{Columns: s.PK}, pk: true}
+	}
+	for i := range s.Indexes {
+		ind := &s.Indexes[i]
+		if ind.Where != nil || !groups(s, ind.Columns, groupBy) {
+			continue
+		}
+		p := &plan{kind: planIndex, schema: s, index: ind, covering: isCovering(s, ind, columns)}
+		if best == nil || p.score() > best.score() {
+			best = p
+		}
+	}
+	return best
+}
+
+// groups is true if the first index columns are the groupBy columns, in any
+// order.
+func groups(s *sdb.Schema, cols []sdb.IndexColumn, groupBy []string) bool {
+	if len(groupBy) > len(cols) {
+		return false
+	}
+	seen := map[string]bool{}
+	for _, g := range groupBy {
+		name, _, collate, err := lookupColumn(s, g)
+		if err != nil {
+			return false
+		}
+		seen[strings.ToLower(name)+"\x00"+collate] = true
+	}
+	for _, ic := range cols[:len(groupBy)] {
+		if ic.Column == "" || !seen[strings.ToLower(ic.Column)+"\x00"+normCollate(ic.Collate)] {
+			return false
+		}
+	}
+	return len(seen) == len(groupBy)
+}
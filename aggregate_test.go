@@ -0,0 +1,279 @@
+package sqlittle
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/andreyvit/diff"
+	"github.com/davecgh/go-spew/spew"
+)
+
+func TestCount(t *testing.T) {
+	for file, want := range map[string]int{
+		"testdata/words.sqlite":        1000,
+		"testdata/withoutrowid.sqlite": 1000,
+		"testdata/empty.sqlite":        0,
+	} {
+		db, err := Open(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		table := "words"
+		if file == "testdata/empty.sqlite" {
+			table = "foo"
+		}
+		n, err := db.Count(table)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if have := n; have != want {
+			t.Errorf("%s: have %d, want %d", file, have, want)
+		}
+		db.Close()
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	db, err := Open("testdata/words.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var rows []Row
+	cb := func(r Row) {
+		rows = append(rows, r)
+	}
+
+	// all from the btree edges
+	if err := db.Aggregate("words", nil, []Agg{
+		Count("*"),
+		Min("word"),
+		Max("word"),
+		Min("length"),
+		Max("length"),
+		Min("rowid"),
+		Max("rowid"),
+	}, cb); err != nil {
+		t.Fatal(err)
+	}
+	want := []Row{
+		{int64(1000), "Adams", "yeshivahs", int64(2), int64(18), int64(1), int64(1000)},
+	}
+	if have := rows; !reflect.DeepEqual(have, want) {
+		t.Errorf("diff:\n%s", diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+	}
+
+	// needs a scan
+	rows = nil
+	if err := db.Aggregate("words", nil, []Agg{
+		Count("word"),
+		Min("word"),
+		Sum("length"),
+		Avg("length"),
+	}, cb); err != nil {
+		t.Fatal(err)
+	}
+	want = []Row{
+		{int64(1000), "Adams", int64(8599), 8.599},
+	}
+	if have := rows; !reflect.DeepEqual(have, want) {
+		t.Errorf("diff:\n%s", diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+	}
+
+	// grouped by index words_index_2
+	rows = nil
+	if err := db.Aggregate("words", []string{"length"}, []Agg{
+		Count("*"),
+		Min("word"),
+		Max("word"),
+	}, cb); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := len(rows), 17; have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+	want = []Row{
+		{int64(2), int64(1), "am", "am"},
+		{int64(3), int64(8), "Amy", "pat"},
+	}
+	if have := rows[:2]; !reflect.DeepEqual(have, want) {
+		t.Errorf("diff:\n%s", diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+	}
+	want = []Row{
+		{int64(18), int64(2), "bloodthirstiness's", "internationalism's"},
+	}
+	if have := rows[16:]; !reflect.DeepEqual(have, want) {
+		t.Errorf("diff:\n%s", diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+	}
+
+	cb = func(r Row) {}
+	if have, want := db.Aggregate("words", nil, []Agg{Min("nosuch")}, cb), errors.New(`no such column: "nosuch"`); !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+	if have, want := db.Aggregate("words", []string{"nosuch"}, nil, cb), errors.New(`no such column: "nosuch"`); !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+	if have, want := db.Aggregate("words", nil, []Agg{{Func: 42, Column: "word"}}, cb), errors.New(`invalid aggregate function: 42`); !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+}
+
+func TestAggregateHash(t *testing.T) {
+	db, err := Open("testdata/music.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var rows []Row
+	cb := func(r Row) {
+		rows = append(rows, r)
+	}
+
+	// no index on tracks.album
+	if err := db.Aggregate("tracks", []string{"album"}, []Agg{
+		Count("*"),
+		Sum("length"),
+		Avg("length"),
+		Min("name"),
+	}, cb); err != nil {
+		t.Fatal(err)
+	}
+	want := []Row{
+		{int64(1), int64(3), int64(464), 464.0 / 3, "Drive My Car"},
+		{int64(2), int64(3), int64(648), 216.0, "Come Together"},
+	}
+	if have := rows; !reflect.DeepEqual(have, want) {
+		t.Errorf("diff:\n%s", diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+	}
+
+	// empty groups
+	rows = nil
+	if err := db.Aggregate("artists", nil, []Agg{Count("*"), Sum("name")}, cb); err != nil {
+		t.Fatal(err)
+	}
+	want = []Row{
+		{int64(1), 0.0},
+	}
+	if have := rows; !reflect.DeepEqual(have, want) {
+		t.Errorf("diff:\n%s", diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+	}
+}
+
+func TestAggregateEmpty(t *testing.T) {
+	db, err := Open("testdata/empty.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	s, err := db.db.Schema("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	col := s.Columns[0].Column
+
+	var rows []Row
+	cb := func(r Row) {
+		rows = append(rows, r)
+	}
+	if err := db.Aggregate("foo", nil, []Agg{Count("*"), Count(col), Min(col), Sum(col), Avg(col)}, cb); err != nil {
+		t.Fatal(err)
+	}
+	want := []Row{
+		{int64(0), int64(0), nil, nil, nil},
+	}
+	if have := rows; !reflect.DeepEqual(have, want) {
+		t.Errorf("diff:\n%s", diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+	}
+
+	rows = nil
+	if err := db.Aggregate("foo", []string{col}, []Agg{Count("*")}, cb); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := len(rows), 0; have != want {
+		t.Errorf("have %d, want %d", have, want)
+	}
+}
+
+func TestAggState(t *testing.T) {
+	type cas struct {
+		f      AggFunc
+		values []interface{}
+		want   interface{}
+	}
+	for i, c := range []cas{
+		{AggSum, nil, nil},
+		{AggSum, []interface{}{nil, nil}, nil},
+		{AggSum, []interface{}{int64(1), nil, int64(2)}, int64(3)},
+		{AggSum, []interface{}{int64(1), 0.5}, 1.5},
+		{AggSum, []interface{}{"12", " 3 ", int64(1)}, int64(16)},
+		{AggSum, []interface{}{"1.5", "abc", "2x", []byte("1e1")}, 13.5},
+		{AggSum, []interface{}{0.1, 0.2, 0.3}, 0.6},
+		{AggSum, []interface{}{int64(math.MaxInt64), int64(-1), int64(1)}, int64(math.MaxInt64)},
+		{AggSum, []interface{}{int64(math.MaxInt64), int64(1), 1.0}, 9223372036854775808.0},
+		{AggAvg, []interface{}{int64(1), int64(2)}, 1.5},
+		{AggAvg, []interface{}{nil}, nil},
+		{AggCount, []interface{}{nil, "a", int64(0)}, int64(2)},
+		{AggMin, []interface{}{nil, "a", int64(3), 2.5, []byte("x")}, 2.5},
+		{AggMax, []interface{}{nil, "a", int64(3), 2.5, "b"}, "b"},
+		{AggMax, []interface{}{nil}, nil},
+	} {
+		a := agg{Agg: Agg{Func: c.f, Column: "x"}, collate: "binary"}
+		var st aggState
+		for _, v := range c.values {
+			st.step(a, v)
+		}
+		have, err := st.result(a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := c.want; !reflect.DeepEqual(have, want) {
+			t.Errorf("case %d: have %#v, want %#v", i, have, want)
+		}
+	}
+
+	a := agg{Agg: Sum("x")}
+	var st aggState
+	st.step(a, int64(math.MaxInt64))
+	st.step(a, int64(1))
+	if _, err := st.result(a); err == nil {
+		t.Errorf("expected an overflow")
+	}
+}
+
+func TestGroupPlan(t *testing.T) {
+	db, err := Open("testdata/words.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	s, err := db.db.Schema("words")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		groupBy []string
+		want    string
+	}{
+		{nil, "SCAN words"},
+		{[]string{"rowid"}, "SCAN words"},
+		{[]string{"word"}, "SCAN words USING INDEX words_index_1"},
+		{[]string{"length"}, "SCAN words USING COVERING INDEX words_index_2"},
+		{[]string{"word", "length"}, "SCAN words USING COVERING INDEX words_index_2"},
+		{[]string{"word", "rowid"}, ""},
+	} {
+		p := groupPlan(s, c.groupBy, []string{"word", "length"})
+		have := ""
+		if p != nil {
+			have = p.String()
+		}
+		if want := c.want; have != want {
+			t.Errorf("%v: have %q, want %q", c.groupBy, have, want)
+		}
+	}
+}
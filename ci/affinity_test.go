@@ -0,0 +1,171 @@
+//go:build ci
+// +build ci
+
+package ci
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/andreyvit/diff"
+	"github.com/davecgh/go-spew/spew"
+	"github.com/hackborn/sqlittle"
+)
+
+func TestAffinity(t *testing.T) {
+	file, close := tmpfile(t)
+	defer close()
+
+	create(t, file, `
+CREATE TABLE aff (i INTEGER, t TEXT, b BLOB, r REAL, n NUMERIC, x);
+CREATE TABLE affpk (i INTEGER, r REAL, n NUMERIC, PRIMARY KEY (r, i)) WITHOUT ROWID;
+INSERT INTO aff VALUES (1, 1, 1, 1, 1, 1);
+INSERT INTO aff VALUES (1.0, 1.0, 1.0, 1.0, 1.0, 1.0);
+INSERT INTO aff VALUES (1.5, 1.5, 1.5, 1.5, 1.5, 1.5);
+INSERT INTO aff VALUES ('12', '12', '12', '12', '12', '12');
+INSERT INTO aff VALUES ('3.0', '3.0', '3.0', '3.0', '3.0', '3.0');
+INSERT INTO aff VALUES (' 7 ', ' 7 ', ' 7 ', ' 7 ', ' 7 ', ' 7 ');
+INSERT INTO aff VALUES ('1e3', '1e3', '1e3', '1e3', '1e3', '1e3');
+INSERT INTO aff VALUES ('abc', 'abc', 'abc', 'abc', 'abc', 'abc');
+INSERT INTO aff VALUES (x'31', x'31', x'31', x'31', x'31', x'31');
+INSERT INTO aff VALUES (NULL, NULL, NULL, NULL, NULL, NULL);
+INSERT INTO aff VALUES (1e30, 1e30, 1e30, 1e30, 1e30, 1e30);
+INSERT INTO affpk VALUES (1, 2, '3');
+INSERT INTO affpk VALUES (4.0, '5', 6.0);
+CREATE INDEX aff_r ON aff (r);
+`)
+
+	db, err := sqlittle.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	typeOf := func(r sqlittle.Row) []string {
+		res := make([]string, len(r))
+		for i, v := range r {
+			switch v.(type) {
+			case nil:
+				res[i] = "null"
+			case int64:
+				res[i] = "integer"
+			case float64:
+				res[i] = "real"
+			case string:
+				res[i] = "text"
+			case []byte:
+				res[i] = "blob"
+			}
+		}
+		return res
+	}
+
+	for _, c := range []struct {
+		table   string
+		columns []string
+	}{
+		{"aff", []string{"i", "t", "b", "r", "n", "x"}},
+		{"affpk", []string{"i", "r", "n"}},
+	} {
+		q := "SELECT "
+		for i, col := range c.columns {
+			if i > 0 {
+				q += ", "
+			}
+			q += "typeof(" + col + ")"
+		}
+		want := execute(t, file, q+" FROM "+c.table)
+
+		var have [][]string
+		if err := db.Select(c.table, func(r sqlittle.Row) {
+			have = append(have, typeOf(r))
+		}, c.columns...); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(have, want) {
+			t.Errorf("%s diff:\n%s", c.table, diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+		}
+	}
+
+	// covering index
+	want := execute(t, file, "SELECT typeof(r) FROM aff INDEXED BY aff_r ORDER BY r")
+	var have [][]string
+	if err := db.IndexedSelect("aff", "aff_r", func(r sqlittle.Row) {
+		have = append(have, typeOf(r))
+	}, "r"); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("index diff:\n%s", diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+	}
+}
+
+func TestKeyAffinity(t *testing.T) {
+	file, close := tmpfile(t)
+	defer close()
+
+	create(t, file, `
+CREATE TABLE k (i INTEGER, t TEXT, r REAL, n NUMERIC, x);
+CREATE INDEX k_i ON k (i);
+CREATE INDEX k_t ON k (t);
+CREATE INDEX k_r ON k (r);
+CREATE INDEX k_n ON k (n, x);
+CREATE TABLE kpk (t TEXT PRIMARY KEY, i INTEGER);
+WITH RECURSIVE n(v) AS (SELECT 1 UNION ALL SELECT v+1 FROM n WHERE v < 50)
+	INSERT INTO k SELECT v, v, v / 2.0, v % 7, v FROM n;
+INSERT INTO kpk VALUES (12, 1), ('abc', 2), (3.5, 3);
+`)
+
+	db, err := sqlittle.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for _, c := range []struct {
+		conds []sqlittle.Cond
+		where string
+	}{
+		{[]sqlittle.Cond{sqlittle.Eq("i", "42")}, "i = '42'"},
+		{[]sqlittle.Cond{sqlittle.Lt("i", "5")}, "i < '5'"},
+		{[]sqlittle.Cond{sqlittle.Eq("t", 42)}, "t = 42"},
+		{[]sqlittle.Cond{sqlittle.Gt("t", 45)}, "t > 45"},
+		{[]sqlittle.Cond{sqlittle.Eq("r", 3)}, "r = 3"},
+		{[]sqlittle.Cond{sqlittle.Eq("r", "3.5")}, "r = '3.5'"},
+		{[]sqlittle.Cond{sqlittle.Eq("n", "3"), sqlittle.Gt("x", "30")}, "n = '3' AND x > '30'"},
+		{[]sqlittle.Cond{sqlittle.Eq("x", "30")}, "x = '30'"},
+		{[]sqlittle.Cond{sqlittle.Le("rowid", "3")}, "rowid <= '3'"},
+	} {
+		want := execute(t, file, "SELECT rowid FROM k WHERE "+c.where)
+		var have [][]string
+		if err := db.Where("k", c.conds, func(r sqlittle.Row) {
+			have = append(have, r.ScanStrings())
+		}, "rowid"); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(have, want) {
+			t.Errorf("%s diff:\n%s", c.where, diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+		}
+	}
+
+	for _, c := range []struct {
+		key   sqlittle.Key
+		where string
+	}{
+		{sqlittle.Key{12}, "t = 12"},
+		{sqlittle.Key{"12"}, "t = '12'"},
+		{sqlittle.Key{3.5}, "t = 3.5"},
+		{sqlittle.Key{"abc"}, "t = 'abc'"},
+	} {
+		want := execute(t, file, "SELECT i FROM kpk WHERE "+c.where)
+		var have [][]string
+		if err := db.PKSelect("kpk", c.key, func(r sqlittle.Row) {
+			have = append(have, r.ScanStrings())
+		}, "i"); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(have, want) {
+			t.Errorf("%s diff:\n%s", c.where, diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+		}
+	}
+}
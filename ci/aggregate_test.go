@@ -0,0 +1,144 @@
+// +build ci
+
+package ci
+
+import (
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/andreyvit/diff"
+	"github.com/davecgh/go-spew/spew"
+	"github.com/hackborn/sqlittle"
+)
+
+func TestAggregate(t *testing.T) {
+	file, close := tmpfile(t)
+	defer close()
+
+	create(t, file, `
+CREATE TABLE g (a, b COLLATE nocase, c);
+CREATE INDEX g_b ON g (b);
+CREATE INDEX g_cb ON g (c DESC, b);
+CREATE TABLE w (k, v, PRIMARY KEY (k)) WITHOUT ROWID;
+CREATE TABLE r (x REAL);
+CREATE INDEX r_x ON r (x);
+INSERT INTO r VALUES (2.5), (1), (NULL), (3.0);
+WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i+1 FROM n WHERE i < 500)
+	INSERT INTO g SELECT
+		CASE i % 6
+			WHEN 0 THEN NULL
+			WHEN 1 THEN i % 7
+			WHEN 2 THEN (i % 9) / 4.0 + 0.1
+			WHEN 3 THEN char(97 + i % 5)
+			WHEN 4 THEN CAST(i % 4 AS TEXT)
+			ELSE 1 << 60
+		END,
+		CASE WHEN i % 11 = 0 THEN NULL ELSE char(65 + i % 3 + (i % 2) * 32, 97 + i % 4) END,
+		CASE WHEN i % 13 = 0 THEN NULL ELSE i % 8 END
+	FROM n;
+WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i+1 FROM n WHERE i < 300)
+	INSERT INTO w SELECT char(97 + i % 26, 97 + i / 26), i % 10 FROM n;
+`)
+
+	db, err := sqlittle.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	sqlName := regexp.MustCompile(`^(\w+)\((.*)\)$`)
+	// fmt formats values the same as format() below does
+	fmt := func(x string) string {
+		return "CASE typeof(" + x + ") WHEN 'real' THEN printf('%.14e', " + x + ") WHEN 'text' THEN lower(" + x + ") ELSE " + x + " END"
+	}
+	format := func(r sqlittle.Row) []string {
+		var res []string
+		for _, v := range r {
+			switch v := v.(type) {
+			case nil:
+				res = append(res, "")
+			case int64:
+				res = append(res, strconv.FormatInt(v, 10))
+			case float64:
+				res = append(res, strconv.FormatFloat(v, 'e', 14, 64))
+			case string:
+				res = append(res, strings.ToLower(v))
+			default:
+				t.Fatalf("unexpected value %#v", v)
+			}
+		}
+		return res
+	}
+
+	type cas struct {
+		table   string
+		groupBy []string
+		aggs    []string
+	}
+	for _, c := range []cas{
+		{"g", nil, []string{"count(*)", "count(a)", "min(a)", "max(a)", "sum(a)", "avg(a)"}},
+		{"g", nil, []string{"min(c)", "max(c)", "count(*)"}},
+		{"g", nil, []string{"min(b)", "max(b)"}},
+		{"g", nil, []string{"min(rowid)", "max(rowid)"}},
+		{"g", []string{"b"}, []string{"count(*)", "sum(c)", "min(a)"}},
+		{"g", []string{"a"}, []string{"count(*)", "sum(c)", "avg(c)", "max(b)"}},
+		{"g", []string{"c", "b"}, []string{"count(c)", "avg(a)", "min(a)"}},
+		{"g", []string{"c", "a"}, []string{"count(b)", "max(b)"}},
+		{"w", nil, []string{"min(k)", "max(k)", "count(*)", "sum(v)"}},
+		{"w", []string{"k"}, []string{"count(*)", "sum(v)"}},
+		{"w", []string{"v"}, []string{"count(*)", "min(k)", "max(k)"}},
+		{"r", nil, []string{"min(x)", "max(x)"}},
+	} {
+		var (
+			cols []string
+			aggs []sqlittle.Agg
+		)
+		for _, g := range c.groupBy {
+			cols = append(cols, fmt(g))
+		}
+		for _, a := range c.aggs {
+			cols = append(cols, fmt(a))
+			m := sqlName.FindStringSubmatch(a)
+			var f sqlittle.AggFunc
+			switch m[1] {
+			case "count":
+				f = sqlittle.AggCount
+			case "min":
+				f = sqlittle.AggMin
+			case "max":
+				f = sqlittle.AggMax
+			case "sum":
+				f = sqlittle.AggSum
+			case "avg":
+				f = sqlittle.AggAvg
+			}
+			aggs = append(aggs, sqlittle.Agg{Func: f, Column: m[2]})
+		}
+		q := "SELECT " + strings.Join(cols, ", ") + " FROM " + c.table
+		if len(c.groupBy) > 0 {
+			q += " GROUP BY " + strings.Join(c.groupBy, ", ")
+		}
+		want := execute(t, file, q)
+
+		var have [][]string
+		if err := db.Aggregate(c.table, c.groupBy, aggs, func(r sqlittle.Row) {
+			have = append(have, format(r))
+		}); err != nil {
+			t.Fatalf("%s: %s", q, err)
+		}
+		if have, want := sortRows(have), sortRows(want); !reflect.DeepEqual(have, want) {
+			t.Errorf("%s: diff:\n%s", q, diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+		}
+	}
+
+	n, err := db.Count("g")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := n, 500; have != want {
+		t.Errorf("have %d, want %d", have, want)
+	}
+}
@@ -0,0 +1,98 @@
+// +build ci
+
+package ci
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/andreyvit/diff"
+	"github.com/davecgh/go-spew/spew"
+	"github.com/hackborn/sqlittle"
+)
+
+func TestDistinct(t *testing.T) {
+	file, close := tmpfile(t)
+	defer close()
+
+	create(t, file, `
+CREATE TABLE orders (id INTEGER PRIMARY KEY, customer COLLATE nocase, created, amount);
+CREATE INDEX orders_cc ON orders (customer, created DESC);
+WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i+1 FROM n WHERE i < 2000)
+	INSERT INTO orders (customer, created, amount) SELECT
+		CASE
+			WHEN i % 17 = 0 THEN NULL
+			WHEN i % 2 THEN char(65 + i % 5)
+			ELSE char(97 + i % 5)
+		END,
+		CASE WHEN i % 23 = 0 THEN NULL ELSE i % 30 END,
+		i
+	FROM n;
+`)
+
+	db, err := sqlittle.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for _, c := range []struct {
+		ncols int
+		sql   string
+	}{
+		{1, "SELECT DISTINCT ifnull(lower(customer), 'NULL') FROM orders ORDER BY customer"},
+		{2, "SELECT DISTINCT lower(customer), created FROM orders ORDER BY customer, created DESC"},
+	} {
+		want := execute(t, file, c.sql)
+		var have [][]string
+		if err := db.Distinct("orders", "orders_cc", c.ncols, func(r sqlittle.Row) {
+			row := r.ScanStrings()
+			switch s := r[0].(type) {
+			case nil:
+				if c.ncols == 1 {
+					row[0] = "NULL"
+				}
+			case string:
+				row[0] = strings.ToLower(s)
+			}
+			have = append(have, row)
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(have, want) {
+			t.Errorf("%s: diff:\n%s", c.sql, diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+		}
+	}
+
+	for _, c := range []struct {
+		created interface{}
+		where   string
+	}{
+		{int64(0), "created = 0"},
+		{int64(7), "created = 7"},
+		{int64(29), "created = 29"},
+		{int64(30), "created = 30"},
+		{nil, "created IS NULL"},
+	} {
+		q := "SELECT id, amount FROM orders WHERE " + c.where + " ORDER BY customer, created DESC, id"
+		want := execute(t, file, q)
+		var have [][]string
+		if err := db.IndexedSelectEqWith(
+			"orders",
+			"orders_cc",
+			sqlittle.Key{c.created},
+			sqlittle.Options{SkipScan: 1},
+			func(r sqlittle.Row) {
+				have = append(have, r.ScanStrings())
+			},
+			"id",
+			"amount",
+		); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(have, want) {
+			t.Errorf("%s: diff:\n%s", q, diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+		}
+	}
+}
@@ -0,0 +1,91 @@
+//go:build ci
+// +build ci
+
+package ci
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/andreyvit/diff"
+	"github.com/davecgh/go-spew/spew"
+	_ "github.com/hackborn/sqlittle/driver"
+)
+
+func TestDriver(t *testing.T) {
+	file, close := tmpfile(t)
+	defer close()
+
+	create(t, file, `
+CREATE TABLE people (name, age INTEGER, city TEXT);
+CREATE INDEX people_city ON people (city, age);
+INSERT INTO people VALUES ('alice', 31, 'Amsterdam');
+INSERT INTO people VALUES ('bob', 25, 'Berlin');
+INSERT INTO people VALUES ('carol', 42, 'Amsterdam');
+INSERT INTO people VALUES ('dave', NULL, 'Amsterdam');
+INSERT INTO people VALUES ('eve', 25, NULL);
+INSERT INTO people VALUES ('frank', 58, 'Berlin');
+INSERT INTO people VALUES ('grace', 19, 'amsterdam');
+`)
+
+	db, err := sql.Open("sqlittle", file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for _, q := range []string{
+		`SELECT * FROM people`,
+		`SELECT rowid, name FROM people WHERE rowid >= 3 AND rowid < 6`,
+		`SELECT name, age FROM people WHERE city = 'Amsterdam'`,
+		`SELECT name FROM people WHERE city = 'Amsterdam' AND age > 30`,
+		`SELECT name FROM people WHERE 30 > age`,
+		`SELECT name FROM people WHERE age != 25`,
+		`SELECT name FROM people WHERE city = NULL`,
+		`SELECT name, age FROM people ORDER BY age DESC, name`,
+		`SELECT name FROM people ORDER BY city, name DESC LIMIT 3 OFFSET 2`,
+		`SELECT name FROM people WHERE age >= 25 ORDER BY name LIMIT 2`,
+		`SELECT name FROM people LIMIT -1 OFFSET 5`,
+	} {
+		want := execute(t, file, q)
+		rows, err := db.Query(q)
+		if err != nil {
+			t.Fatalf("%s: %s", q, err)
+		}
+		have := scanAll(t, rows)
+		if !reflect.DeepEqual(have, want) {
+			t.Errorf("%s diff:\n%s", q, diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+		}
+	}
+}
+
+// scanAll reads all rows the way sqlite3 prints them.
+func scanAll(t *testing.T, rows *sql.Rows) [][]string {
+	t.Helper()
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res [][]string
+	for rows.Next() {
+		vs := make([]sql.NullString, len(cols))
+		ps := make([]interface{}, len(cols))
+		for i := range vs {
+			ps[i] = &vs[i]
+		}
+		if err := rows.Scan(ps...); err != nil {
+			t.Fatal(err)
+		}
+		row := make([]string, len(cols))
+		for i, v := range vs {
+			row[i] = v.String
+		}
+		res = append(res, row)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
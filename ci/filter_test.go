@@ -0,0 +1,117 @@
+// +build ci
+
+package ci
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/andreyvit/diff"
+	"github.com/davecgh/go-spew/spew"
+	"github.com/hackborn/sqlittle"
+)
+
+func TestFilter(t *testing.T) {
+	file, close := tmpfile(t)
+	defer close()
+
+	create(t, file, `
+CREATE TABLE foo (a, b TEXT COLLATE nocase, c REAL, d COLLATE rtrim);
+CREATE INDEX foo_ba ON foo (b, a);
+CREATE TABLE bar (a, b COLLATE nocase, c, PRIMARY KEY (a, b)) WITHOUT ROWID;
+WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i+1 FROM n WHERE i < 400)
+	INSERT INTO foo SELECT
+		CASE WHEN i % 10 = 0 THEN NULL ELSE i % 9 END,
+		char(65 + i % 4, 97 + i % 3, 120 + i % 2),
+		CASE WHEN i % 7 = 0 THEN NULL ELSE i / 8.0 END,
+		CASE i % 3 WHEN 0 THEN 'x' WHEN 1 THEN 'x  ' ELSE i END
+	FROM n;
+INSERT INTO foo VALUES ("text", "A_b", x'4142', "%");
+WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i+1 FROM n WHERE i < 300)
+	INSERT INTO bar SELECT i % 17, char(65 + i % 5, 97 + i % 7), i % 4 FROM n;
+`)
+
+	db, err := sqlittle.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	type cas struct {
+		filter sqlittle.Filter
+		where  string
+	}
+	for _, c := range []cas{
+		{sqlittle.Eq("a", 3), "a = 3"},
+		{sqlittle.Ne("a", 3), "a != 3"},
+		{sqlittle.Not(sqlittle.Eq("a", 3)), "NOT a = 3"},
+		{sqlittle.Eq("b", "aBx"), "b = 'aBx'"},
+		{sqlittle.Lt("b", "B"), "b < 'B'"},
+		{sqlittle.Ge("c", 20.5), "c >= 20.5"},
+		{sqlittle.Ge("c", 20), "c >= 20"},
+		{sqlittle.Between("a", 2, 4), "a BETWEEN 2 AND 4"},
+		{sqlittle.Between("b", "b", "c"), "b BETWEEN 'b' AND 'c'"},
+		{sqlittle.In("a", 1, 5, "text"), "a IN (1, 5, 'text')"},
+		{sqlittle.In("b", "cay", "DBX"), "b IN ('cay', 'DBX')"},
+		{sqlittle.Not(sqlittle.In("a", 1, nil)), "NOT a IN (1, NULL)"},
+		{sqlittle.IsNull("c"), "c IS NULL"},
+		{sqlittle.Not(sqlittle.IsNull("a")), "a IS NOT NULL"},
+		{sqlittle.Eq("d", "x"), "d = 'x'"},
+		{sqlittle.Gt("d", 100), "d > 100"},
+		{sqlittle.Like("b", "a%"), "b LIKE 'a%'"},
+		{sqlittle.Like("b", "_B_"), "b LIKE '_B_'"},
+		{sqlittle.Like("c", "1_.5"), "c LIKE '1_.5'"},
+		{sqlittle.Like("c", "%.0"), "c LIKE '%.0'"},
+		{sqlittle.Like("d", "x %"), "d LIKE 'x %'"},
+		{sqlittle.Like("d", "\\%"), "d LIKE '\\%'"},
+		{sqlittle.Glob("b", "A*"), "b GLOB 'A*'"},
+		{sqlittle.Glob("b", "[AC]?[^y]"), "b GLOB '[AC]?[^y]'"},
+		{sqlittle.Glob("a", "[1-3]"), "a GLOB '[1-3]'"},
+		{sqlittle.And(sqlittle.Eq("a", 2), sqlittle.Like("b", "b%")), "a = 2 AND b LIKE 'b%'"},
+		{sqlittle.Or(sqlittle.Eq("a", 2), sqlittle.Gt("c", 45)), "a = 2 OR c > 45"},
+		{sqlittle.Not(sqlittle.Or(sqlittle.Eq("a", 2), sqlittle.Gt("c", 45))), "NOT (a = 2 OR c > 45)"},
+		{sqlittle.Not(sqlittle.And(sqlittle.Eq("a", 2), sqlittle.IsNull("c"))), "NOT (a = 2 AND c IS NULL)"},
+		{sqlittle.Lt("rowid", 10), "rowid < 10"},
+	} {
+		want := execute(t, file, "SELECT a, b FROM foo WHERE "+c.where)
+
+		var have [][]string
+		cb := func(r sqlittle.Row) {
+			have = append(have, r.ScanStrings())
+		}
+		if err := db.SelectWith("foo", sqlittle.Options{Where: c.filter}, cb, "a", "b"); err != nil {
+			t.Fatal(err)
+		}
+		if have, want := sortRows(have), sortRows(want); !reflect.DeepEqual(have, want) {
+			t.Errorf("%s: diff:\n%s", c.where, diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+		}
+
+		have = nil
+		if err := db.IndexedSelectWith("foo", "foo_ba", sqlittle.Options{Where: c.filter}, cb, "a", "b"); err != nil {
+			t.Fatal(err)
+		}
+		if have, want := sortRows(have), sortRows(want); !reflect.DeepEqual(have, want) {
+			t.Errorf("indexed %s: diff:\n%s", c.where, diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+		}
+	}
+
+	for _, c := range []cas{
+		{sqlittle.Eq("a", 3), "a = 3"},
+		{sqlittle.Like("b", "c%"), "b LIKE 'c%'"},
+		{sqlittle.In("b", "bb", "EE"), "b IN ('bb', 'EE')"},
+		{sqlittle.Or(sqlittle.Lt("a", 2), sqlittle.Eq("c", 3)), "a < 2 OR c = 3"},
+	} {
+		want := execute(t, file, "SELECT a, b FROM bar WHERE "+c.where)
+
+		var have [][]string
+		cb := func(r sqlittle.Row) {
+			have = append(have, r.ScanStrings())
+		}
+		if err := db.SelectWith("bar", sqlittle.Options{Where: c.filter}, cb, "a", "b"); err != nil {
+			t.Fatal(err)
+		}
+		if have, want := sortRows(have), sortRows(want); !reflect.DeepEqual(have, want) {
+			t.Errorf("%s: diff:\n%s", c.where, diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+		}
+	}
+}
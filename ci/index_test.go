@@ -0,0 +1,77 @@
+// +build ci
+
+package ci
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hackborn/sqlittle"
+)
+
+func TestIndexes(t *testing.T) {
+	indexList := func(t *testing.T, db *sqlittle.DB) [][]string {
+		inds, err := db.Indexes("foo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var rows [][]string
+		for _, ind := range inds {
+			unique, partial := "0", "0"
+			if ind.Unique {
+				unique = "1"
+			}
+			if ind.Where != "" {
+				partial = "1"
+			}
+			rows = append(rows, []string{ind.Name, unique, ind.Origin, partial})
+		}
+		sort.Slice(rows, func(i, j int) bool { return rows[i][0] < rows[j][0] })
+		return rows
+	}
+	Compare(
+		t,
+		`
+CREATE TABLE foo (a, b NOT NULL PRIMARY KEY, c, d, UNIQUE(c, a), UNIQUE(d));
+CREATE INDEX foo_a ON foo (a);
+CREATE UNIQUE INDEX foo_d ON foo (d DESC, c);
+CREATE INDEX foo_partial ON foo (c) WHERE a > 10;
+`,
+		`SELECT name, "unique", origin, partial FROM pragma_index_list('foo') ORDER BY name`,
+		indexList,
+	)
+	// the primary key shares the index of the UNIQUE constraint
+	Compare(
+		t,
+		`CREATE TABLE foo (a UNIQUE, b, PRIMARY KEY (a));`,
+		`SELECT name, "unique", origin, partial FROM pragma_index_list('foo') ORDER BY name`,
+		indexList,
+	)
+}
+
+func TestFindIndex(t *testing.T) {
+	Compare(
+		t,
+		`
+CREATE TABLE foo (a, b, c, UNIQUE(b, c), UNIQUE(c COLLATE nocase));
+`,
+		`SELECT il.name, ii.name, upper(ii.coll) FROM pragma_index_list('foo') il, pragma_index_xinfo(il.name) ii WHERE ii.cid >= 0 ORDER BY il.name, ii.seqno`,
+		func(t *testing.T, db *sqlittle.DB) [][]string {
+			var rows [][]string
+			for _, cols := range [][]string{{"b", "c"}, {"c"}} {
+				ind, err := db.FindIndex("foo", cols...)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if ind == nil {
+					t.Fatalf("no index for %v", cols)
+				}
+				for _, c := range ind.Columns {
+					rows = append(rows, []string{ind.Name, c.Column, strings.ToUpper(c.Collate)})
+				}
+			}
+			return rows
+		},
+	)
+}
@@ -0,0 +1,105 @@
+// +build ci
+
+package ci
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/andreyvit/diff"
+	"github.com/davecgh/go-spew/spew"
+	"github.com/hackborn/sqlittle"
+)
+
+func TestJoin(t *testing.T) {
+	file, close := tmpfile(t)
+	defer close()
+
+	create(t, file, `
+CREATE TABLE l (a, b COLLATE nocase, c);
+CREATE TABLE r (id INTEGER PRIMARY KEY, a, b, c);
+CREATE INDEX r_ab ON r (a, b);
+CREATE INDEX r_b ON r (b COLLATE nocase);
+CREATE TABLE w (a, b, c, PRIMARY KEY (b, a)) WITHOUT ROWID;
+WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i+1 FROM n WHERE i < 200)
+	INSERT INTO l SELECT
+		CASE WHEN i % 9 = 0 THEN NULL ELSE i % 13 END,
+		char(65 + i % 3, 97 + i % 4),
+		CASE WHEN i % 2 THEN i % 7 ELSE (i % 7) * 1.0 END
+	FROM n;
+WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i+1 FROM n WHERE i < 150)
+	INSERT INTO r (a, b, c) SELECT
+		CASE WHEN i % 11 = 0 THEN NULL ELSE i % 17 END,
+		CASE WHEN i % 2 THEN char(97 + i % 3, 97 + i % 4) ELSE char(65 + i % 3, 65 + i % 4) END,
+		i % 5
+	FROM n;
+WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i+1 FROM n WHERE i < 100)
+	INSERT INTO w SELECT i % 25, char(65 + i % 3, 97 + i % 4), i FROM n;
+`)
+
+	db, err := sqlittle.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	type cas struct {
+		left, right string
+		on          []sqlittle.ColumnPair
+		sql         string
+	}
+	for _, c := range []cas{
+		// index r_ab
+		{"l", "r", []sqlittle.ColumnPair{{Left: "a", Right: "a"}}, "l.a = r.a"},
+		{"l", "r", []sqlittle.ColumnPair{{Left: "b", Right: "b"}, {Left: "a", Right: "a"}}, "l.a = r.a AND l.b = r.b"},
+		// index r_b, since l.b is nocase
+		{"l", "r", []sqlittle.ColumnPair{{Left: "b", Right: "b"}}, "l.b = r.b"},
+		// hash join, binary
+		{"r", "l", []sqlittle.ColumnPair{{Left: "b", Right: "b"}}, "r.b = l.b"},
+		// hash join, numbers
+		{"l", "r", []sqlittle.ColumnPair{{Left: "c", Right: "c"}}, "l.c = r.c"},
+		{"r", "l", []sqlittle.ColumnPair{{Left: "c", Right: "c"}, {Left: "a", Right: "a"}}, "r.c = l.c AND r.a = l.a"},
+		// rowid
+		{"l", "r", []sqlittle.ColumnPair{{Left: "a", Right: "id"}}, "l.a = r.id"},
+		{"l", "r", []sqlittle.ColumnPair{{Left: "c", Right: "rowid"}}, "l.c = r.rowid"},
+		// primary key of a WITHOUT ROWID table
+		{"r", "w", []sqlittle.ColumnPair{{Left: "a", Right: "a"}, {Left: "b", Right: "b"}}, "r.a = w.a AND r.b = w.b"},
+		{"l", "w", []sqlittle.ColumnPair{{Left: "b", Right: "b"}}, "l.b = w.b"},
+		{"w", "l", []sqlittle.ColumnPair{{Left: "a", Right: "a"}}, "w.a = l.a"},
+	} {
+		for _, outer := range []bool{false, true} {
+			join := "JOIN"
+			f := db.Join
+			if outer {
+				join = "LEFT JOIN"
+				f = db.LeftJoin
+			}
+			q := "SELECT " + c.left + ".rowid, " + c.left + ".a, " + c.right + ".b FROM " +
+				c.left + " " + join + " " + c.right + " ON " + c.sql
+			if c.left == "w" {
+				q = "SELECT w.b, w.a, " + c.right + ".b FROM w " + join + " " + c.right + " ON " + c.sql
+			}
+			want := execute(t, file, q)
+
+			var have [][]string
+			cb := func(r sqlittle.Row) {
+				have = append(have, r.ScanStrings())
+			}
+			leftCols := []string{"rowid", "a"}
+			if c.left == "w" {
+				leftCols = []string{"b", "a"}
+			}
+			if err := f(
+				sqlittle.JoinSpec{Table: c.left, Columns: leftCols},
+				sqlittle.JoinSpec{Table: c.right, Columns: []string{"b"}},
+				c.on,
+				cb,
+			); err != nil {
+				t.Fatal(err)
+			}
+			if have, want := sortRows(have), sortRows(want); !reflect.DeepEqual(have, want) {
+				t.Errorf("%s: diff:\n%s", q, diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+			}
+		}
+	}
+}
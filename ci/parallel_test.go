@@ -0,0 +1,66 @@
+// +build ci
+
+package ci
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/andreyvit/diff"
+	"github.com/davecgh/go-spew/spew"
+	"github.com/hackborn/sqlittle"
+)
+
+func TestSelectParallel(t *testing.T) {
+	file, close := tmpfile(t)
+	defer close()
+
+	// long values use overflow pages
+	create(t, file, `
+CREATE TABLE big (id INTEGER PRIMARY KEY, v);
+CREATE TABLE nr (k, v, PRIMARY KEY (k)) WITHOUT ROWID;
+WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i+1 FROM n WHERE i < 20000)
+	INSERT INTO big SELECT i, CASE WHEN i % 50 = 0 THEN printf('%.*c', 3000 + i % 7, 'x') ELSE i END FROM n;
+WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i+1 FROM n WHERE i < 20000)
+	INSERT INTO nr SELECT printf('%06d', i), CASE WHEN i % 50 = 0 THEN printf('%.*c', 3000 + i % 7, 'y') ELSE i END FROM n;
+`)
+
+	db, err := sqlittle.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for _, c := range []struct {
+		table, key string
+	}{
+		{"big", "id"},
+		{"nr", "k"},
+	} {
+		want := execute(t, file, "SELECT "+c.key+", length(v) FROM "+c.table+" ORDER BY 1")
+
+		var (
+			mu   sync.Mutex
+			have [][]string
+		)
+		if err := db.SelectParallel(c.table, 8, func(r sqlittle.Row) {
+			s := r.ScanStrings()
+			mu.Lock()
+			defer mu.Unlock()
+			have = append(have, []string{s[0], strconv.Itoa(len(s[1]))})
+		}, c.key, "v"); err != nil {
+			t.Fatal(err)
+		}
+		sort.Slice(have, func(i, j int) bool {
+			a, _ := strconv.Atoi(have[i][0])
+			b, _ := strconv.Atoi(have[j][0])
+			return a < b
+		})
+		if !reflect.DeepEqual(have, want) {
+			t.Errorf("%s: diff:\n%s", c.table, diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+		}
+	}
+}
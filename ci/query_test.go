@@ -0,0 +1,71 @@
+//go:build ci
+// +build ci
+
+package ci
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/andreyvit/diff"
+	"github.com/davecgh/go-spew/spew"
+	"github.com/hackborn/sqlittle"
+)
+
+func TestQuery(t *testing.T) {
+	file, close := tmpfile(t)
+	defer close()
+
+	create(t, file, `
+CREATE TABLE people (name, age INTEGER, city TEXT);
+CREATE INDEX people_city ON people (city, age);
+CREATE INDEX people_age ON people (age);
+INSERT INTO people VALUES ('alice', 31, 'Amsterdam');
+INSERT INTO people VALUES ('bob', 25, 'Berlin');
+INSERT INTO people VALUES ('carol', 42, 'Amsterdam');
+INSERT INTO people VALUES ('dave', NULL, 'Amsterdam');
+INSERT INTO people VALUES ('eve', 25, NULL);
+INSERT INTO people VALUES ('frank', 58, 'Berlin');
+INSERT INTO people VALUES ('grace', 19, 'amsterdam');
+`)
+
+	db, err := sqlittle.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for _, q := range []string{
+		`SELECT * FROM people`,
+		`SELECT rowid, name FROM people WHERE rowid > 2 AND rowid <= 6`,
+		`SELECT name, age FROM people WHERE city = 'Amsterdam'`,
+		`SELECT name FROM people WHERE city = 'Berlin' AND age >= 25 AND age < 58`,
+		`SELECT name, age FROM people WHERE age > 20 AND 50 > age`,
+		`SELECT name FROM people WHERE age = 25 AND city != 'Berlin'`,
+		`SELECT name FROM people WHERE name > 'c' ORDER BY age DESC`,
+		`SELECT city, name FROM people ORDER BY city DESC, name LIMIT 4 OFFSET 1`,
+		`SELECT name FROM people WHERE age < 100 ORDER BY name DESC LIMIT 3`,
+		`SELECT age FROM people WHERE age = NULL`,
+	} {
+		want := execute(t, file, q)
+		rows, err := db.Query(q)
+		if err != nil {
+			t.Fatalf("%s: %s", q, err)
+		}
+		var have [][]string
+		for rows.Next() {
+			row := make([]string, len(rows.Columns()))
+			ps := make([]interface{}, len(row))
+			for i := range row {
+				ps[i] = &row[i]
+			}
+			if err := rows.Scan(ps...); err != nil {
+				t.Fatal(err)
+			}
+			have = append(have, row)
+		}
+		if !reflect.DeepEqual(have, want) {
+			t.Errorf("%s diff:\n%s", q, diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+		}
+	}
+}
@@ -0,0 +1,72 @@
+//go:build ci
+// +build ci
+
+package ci
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hackborn/sqlittle"
+)
+
+func TestReopen(t *testing.T) {
+	file, close := tmpfile(t)
+	defer close()
+	next, closeNext := tmpfile(t)
+	defer closeNext()
+
+	config := func(file string, version int) {
+		create(t, file, fmt.Sprintf(`
+CREATE TABLE config (k, v);
+CREATE UNIQUE INDEX config_k ON config (k);
+INSERT INTO config VALUES ('version', %d);
+`, version))
+	}
+	config(file, 1)
+
+	db, err := sqlittle.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	reopened := 0
+	db.SetReopen(true, func() { reopened++ })
+
+	version := func() int {
+		t.Helper()
+		v := 0
+		if err := db.IndexedSelectEq("config", "config_k", sqlittle.Key{"version"}, func(r sqlittle.Row) {
+			r.Scan(&v)
+		}, "v"); err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	if have, want := version(), 1; have != want {
+		t.Errorf("have %d, want %d", have, want)
+	}
+
+	for i := 2; i < 5; i++ {
+		config(next, i)
+		if err := os.Rename(next, file); err != nil {
+			t.Fatal(err)
+		}
+		if have, want := version(), i; have != want {
+			t.Errorf("have %d, want %d", have, want)
+		}
+	}
+	if have, want := reopened, 3; have != want {
+		t.Errorf("have %d, want %d", have, want)
+	}
+
+	// normal writes don't reopen
+	execute(t, file, `UPDATE config SET v = 42;`)
+	if have, want := version(), 42; have != want {
+		t.Errorf("have %d, want %d", have, want)
+	}
+	if have, want := reopened, 3; have != want {
+		t.Errorf("have %d, want %d", have, want)
+	}
+}
@@ -0,0 +1,71 @@
+// +build ci
+
+package ci
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/hackborn/sqlittle"
+)
+
+func TestSample(t *testing.T) {
+	file, close := tmpfile(t)
+	defer close()
+
+	create(t, file, `
+CREATE TABLE big (id INTEGER PRIMARY KEY, payload);
+CREATE TABLE wide (k, v, PRIMARY KEY (k)) WITHOUT ROWID;
+WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i+1 FROM n WHERE i < 100000)
+	INSERT INTO big SELECT i, CASE WHEN i % 3 = 0 THEN randomblob(200) ELSE i END FROM n;
+WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i+1 FROM n WHERE i < 50000)
+	INSERT INTO wide SELECT i, hex(randomblob(i % 50)) FROM n;
+`)
+
+	db, err := sqlittle.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for table, want := range map[string]int{
+		"big":  100000,
+		"wide": 50000,
+	} {
+		n, err := db.EstimateCount(table)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n < want*6/10 || n > want*14/10 {
+			t.Errorf("%s: have %d, want about %d", table, n, want)
+		}
+
+		// the average of a uniform sample is in the middle
+		var (
+			total int64
+			count int
+		)
+		rnd := rand.New(rand.NewSource(1))
+		col := "id"
+		if table == "wide" {
+			col = "k"
+		}
+		if err := db.SampleRand(table, 2000, rnd, func(r sqlittle.Row) {
+			var id int64
+			if err := r.Scan(&id); err != nil {
+				t.Fatal(err)
+			}
+			total += id
+			count++
+		}, col); err != nil {
+			t.Fatal(err)
+		}
+		if count != 2000 {
+			t.Errorf("%s: have %d rows", table, count)
+		}
+		mean := float64(total) / float64(count) / float64(want)
+		if mean < 0.4 || mean > 0.6 {
+			t.Errorf("%s: not uniform: %f", table, mean)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// +build ci
+
+package ci
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/andreyvit/diff"
+	"github.com/davecgh/go-spew/spew"
+	"github.com/hackborn/sqlittle"
+)
+
+func TestOrderBy(t *testing.T) {
+	file, close := tmpfile(t)
+	defer close()
+
+	create(t, file, `
+CREATE TABLE foo (a, b COLLATE nocase, c);
+CREATE INDEX foo_c ON foo (c);
+WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i+1 FROM n WHERE i < 3000)
+	INSERT INTO foo SELECT
+		CASE i % 5
+			WHEN 0 THEN NULL
+			WHEN 1 THEN i % 13
+			WHEN 2 THEN (i % 17) / 4.0 + 0.1
+			WHEN 3 THEN char(97 + i % 7, 65 + i % 3)
+			ELSE CAST(char(48 + i % 10, 120) AS BLOB)
+		END,
+		char(65 + i % 4, 97 + i % 3) || CASE WHEN i % 2 THEN ' ' ELSE '' END,
+		i % 10
+	FROM n;
+`)
+
+	db, err := sqlittle.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	type cas struct {
+		order   []sqlittle.Order
+		orderBy string
+	}
+	for _, c := range []cas{
+		{[]sqlittle.Order{sqlittle.Asc("a")}, "a"},
+		{[]sqlittle.Order{sqlittle.Desc("a")}, "a DESC"},
+		{[]sqlittle.Order{sqlittle.Asc("b")}, "b"},
+		{[]sqlittle.Order{sqlittle.Desc("b")}, "b DESC"},
+		{[]sqlittle.Order{{Column: "b", Collate: "binary"}}, "b COLLATE binary"},
+		{[]sqlittle.Order{{Column: "b", Collate: "rtrim", Desc: true}}, "b COLLATE rtrim DESC"},
+		{[]sqlittle.Order{{Column: "a", Collate: "nocase"}}, "a COLLATE nocase"},
+		{[]sqlittle.Order{sqlittle.Asc("c"), sqlittle.Desc("a")}, "c, a DESC"},
+	} {
+		order := append(c.order, sqlittle.Asc("rowid"))
+		want := execute(t, file, "SELECT rowid, a, b FROM foo ORDER BY "+c.orderBy+", rowid")
+
+		for _, mem := range []int{0, 10000} {
+			var have [][]string
+			cb := func(r sqlittle.Row) {
+				have = append(have, r.ScanStrings())
+			}
+			opts := sqlittle.Options{OrderBy: order, SortMemory: mem}
+			if err := db.SelectWith("foo", opts, cb, "rowid", "a", "b"); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(have, want) {
+				t.Errorf("%s (mem %d): diff:\n%s", c.orderBy, mem, diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+			}
+
+			have = nil
+			if err := db.IndexedSelectWith("foo", "foo_c", opts, cb, "rowid", "a", "b"); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(have, want) {
+				t.Errorf("indexed %s (mem %d): diff:\n%s", c.orderBy, mem, diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+			}
+		}
+	}
+}
@@ -0,0 +1,180 @@
+//go:build ci
+// +build ci
+
+package ci
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hackborn/sqlittle"
+)
+
+func TestTail(t *testing.T) {
+	file, close := tmpfile(t)
+	defer close()
+
+	create(t, file, `
+CREATE TABLE log (msg);
+INSERT INTO log VALUES ('one');
+INSERT INTO log VALUES ('two');
+`)
+	st, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	size := st.Size()
+
+	db, err := sqlittle.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	rows := make(chan string, 100)
+	done := make(chan error)
+	go func() {
+		done <- db.Tail(ctx, "log", 1, func(r sqlittle.Row) {
+			var msg string
+			r.Scan(&msg)
+			rows <- msg
+		}, "msg")
+	}()
+
+	next := func() string {
+		t.Helper()
+		select {
+		case m := <-rows:
+			return m
+		case err := <-done:
+			t.Fatalf("Tail stopped: %v", err)
+		case <-ctx.Done():
+			t.Fatal("no row")
+		}
+		return ""
+	}
+
+	if have, want := next(), "two"; have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+
+	// big rows, so the file grows
+	for i := 0; i < 5; i++ {
+		execute(t, file, fmt.Sprintf(`INSERT INTO log VALUES ('%d' || hex(zeroblob(10000)));`, i))
+	}
+	for i := 0; i < 5; i++ {
+		m := next()
+		if have, want := m[:1], fmt.Sprintf("%d", i); have != want {
+			t.Errorf("have %q, want %q", have, want)
+		}
+		if have, want := len(m), 20001; have != want {
+			t.Errorf("have %d, want %d", have, want)
+		}
+	}
+
+	st, err = os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Size() <= size {
+		t.Errorf("file didn't grow")
+	}
+
+	// changes which don't append rows
+	execute(t, file, `DELETE FROM log WHERE rowid = 1;`)
+	execute(t, file, `INSERT INTO log VALUES ('last');`)
+	if have, want := next(), "last"; have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+
+	cancel()
+	if have, want := <-done, context.Canceled; have != want {
+		t.Errorf("have %v, want %v", have, want)
+	}
+}
+
+func TestTailReplaced(t *testing.T) {
+	file, close := tmpfile(t)
+	defer close()
+	next, closeNext := tmpfile(t)
+	defer closeNext()
+
+	create(t, file, `
+CREATE TABLE log (msg);
+INSERT INTO log VALUES ('old');
+`)
+	// keep a path to the old file, to write to it after it's replaced
+	link := file + ".link"
+	if err := os.Link(file, link); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(link)
+
+	db, err := sqlittle.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	rows := make(chan string, 100)
+	done := make(chan error)
+	go func() {
+		done <- db.Tail(ctx, "log", 0, func(r sqlittle.Row) {
+			var msg string
+			r.Scan(&msg)
+			rows <- msg
+		}, "msg")
+	}()
+
+	nextRow := func() string {
+		t.Helper()
+		select {
+		case m := <-rows:
+			return m
+		case err := <-done:
+			t.Fatalf("Tail stopped: %v", err)
+		case <-ctx.Done():
+			t.Fatal("no row")
+		}
+		return ""
+	}
+	if have, want := nextRow(), "old"; have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+
+	create(t, next, `
+CREATE TABLE log (msg);
+WITH RECURSIVE n(v) AS (SELECT 1 UNION ALL SELECT v+1 FROM n WHERE v < 10)
+	INSERT INTO log SELECT 'new' || hex(zeroblob(10000)) FROM n;
+`)
+	if err := os.Rename(next, file); err != nil {
+		t.Fatal(err)
+	}
+
+	// the old file grows, and Tail keeps reading it
+	for i := 0; i < 3; i++ {
+		execute(t, link, fmt.Sprintf(`INSERT INTO log VALUES ('old%d' || hex(zeroblob(10000)));`, i))
+	}
+	execute(t, link, `INSERT INTO log VALUES ('last');`)
+	for i := 0; i < 3; i++ {
+		m := nextRow()
+		if have, want := m[:4], fmt.Sprintf("old%d", i); have != want {
+			t.Errorf("have %q, want %q", have, want)
+		}
+	}
+	if have, want := nextRow(), "last"; have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+
+	cancel()
+	if have, want := <-done, context.Canceled; have != want {
+		t.Errorf("have %v, want %v", have, want)
+	}
+}
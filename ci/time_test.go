@@ -0,0 +1,64 @@
+//go:build ci
+// +build ci
+
+package ci
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/andreyvit/diff"
+	"github.com/davecgh/go-spew/spew"
+	"github.com/hackborn/sqlittle"
+)
+
+func TestTime(t *testing.T) {
+	file, close := tmpfile(t)
+	defer close()
+
+	create(t, file, `
+CREATE TABLE times (v);
+INSERT INTO times VALUES ('2018-03-04');
+INSERT INTO times VALUES ('2018-03-04 12:34');
+INSERT INTO times VALUES ('2018-03-04 12:34:56');
+INSERT INTO times VALUES ('2018-03-04T12:34:56.789');
+INSERT INTO times VALUES ('2018-03-04 12:34:56.7891');
+INSERT INTO times VALUES ('2018-03-04 12:34:56Z');
+INSERT INTO times VALUES ('2018-03-04 12:34:56+02:00');
+INSERT INTO times VALUES ('2018-03-04 12:34:56.5 -05:30');
+INSERT INTO times VALUES ('12:34');
+INSERT INTO times VALUES ('12:34:56.25');
+INSERT INTO times VALUES ('2458182.024259259');
+INSERT INTO times VALUES (2458182.024259259);
+INSERT INTO times VALUES (0.0);
+INSERT INTO times VALUES (1520166896);
+INSERT INTO times VALUES (-86400);
+`)
+
+	want := execute(t, file, `
+SELECT CASE typeof(v)
+	WHEN 'integer' THEN strftime('%Y-%m-%d %H:%M:%f', v, 'unixepoch')
+	ELSE strftime('%Y-%m-%d %H:%M:%f', v)
+END FROM times`)
+
+	db, err := sqlittle.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var have [][]string
+	if err := db.Select("times", func(r sqlittle.Row) {
+		var v time.Time
+		if err := r.Scan(&v); err != nil {
+			t.Fatal(err)
+		}
+		have = append(have, []string{v.Format("2006-01-02 15:04:05.000")})
+	}, "v"); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("diff:\n%s", diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+	}
+}
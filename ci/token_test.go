@@ -0,0 +1,108 @@
+// +build ci
+
+package ci
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/andreyvit/diff"
+	"github.com/davecgh/go-spew/spew"
+	"github.com/hackborn/sqlittle"
+)
+
+func TestPaging(t *testing.T) {
+	file, close := tmpfile(t)
+	defer close()
+
+	create(t, file, `
+CREATE TABLE items (id INTEGER PRIMARY KEY, v);
+CREATE INDEX items_v ON items (v);
+WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i+1 FROM n WHERE i < 300)
+	INSERT INTO items (id, v) SELECT i * 2, i % 37 FROM n;
+`)
+
+	db, err := sqlittle.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// every page the database changes, around and on the current position
+	change := func() {
+		execute(t, file, `
+DELETE FROM items WHERE id IN (SELECT id FROM items ORDER BY random() LIMIT 3);
+INSERT INTO items (id, v) SELECT max(id) + 1, abs(random()) % 37 FROM items;
+INSERT INTO items (id, v) SELECT min(id) + 1, abs(random()) % 37 FROM items WHERE id + 1 NOT IN (SELECT id FROM items);
+`)
+	}
+
+	for _, c := range []struct {
+		index string
+		first string
+		next  string
+	}{
+		{
+			"",
+			"SELECT id, v FROM items ORDER BY id LIMIT 20",
+			"SELECT id, v FROM items WHERE id > ? ORDER BY id LIMIT 20",
+		},
+		{
+			"items_v",
+			"SELECT id, v FROM items ORDER BY v, id LIMIT 20",
+			"SELECT id, v FROM items WHERE (v, id) > (?, ?) ORDER BY v, id LIMIT 20",
+		},
+	} {
+		var (
+			next sqlittle.Token
+			last []string
+		)
+		opts := sqlittle.Options{Limit: 20, Next: &next}
+		for {
+			q := c.first
+			if last != nil {
+				q = c.next
+				if c.index == "" {
+					q = bind(q, last[0])
+				} else {
+					q = bind(q, last[1], last[0])
+				}
+			}
+			want := execute(t, file, q)
+
+			var have [][]string
+			cb := func(r sqlittle.Row) {
+				have = append(have, r.ScanStrings())
+			}
+			if c.index == "" {
+				err = db.SelectWith("items", opts, cb, "id", "v")
+			} else {
+				err = db.IndexedSelectWith("items", c.index, opts, cb, "id", "v")
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(have, want) {
+				t.Fatalf("%s: diff:\n%s", q, diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+			}
+			if next == "" {
+				if len(have) == 20 {
+					t.Fatalf("%s: no token after a full page", q)
+				}
+				break
+			}
+			last = have[len(have)-1]
+			opts.After = next
+			change()
+		}
+	}
+}
+
+// bind replaces the ? placeholders with the values, in order.
+func bind(q string, values ...string) string {
+	for _, v := range values {
+		q = strings.Replace(q, "?", v, 1)
+	}
+	return q
+}
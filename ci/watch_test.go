@@ -0,0 +1,69 @@
+// +build ci
+
+package ci
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hackborn/sqlittle"
+)
+
+func TestWatch(t *testing.T) {
+	file, close := tmpfile(t)
+	defer close()
+
+	create(t, file, `
+CREATE TABLE foo (a);
+INSERT INTO foo VALUES (1);
+`)
+
+	db, err := sqlittle.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// a long interval: changes come from inotify
+	ch := db.Watch(ctx, time.Hour)
+
+	next := func() sqlittle.Change {
+		t.Helper()
+		select {
+		case c := <-ch:
+			if c.Err != nil {
+				t.Fatal(c.Err)
+			}
+			return c
+		case <-time.After(5 * time.Second):
+			t.Fatal("no change")
+		}
+		return sqlittle.Change{}
+	}
+
+	execute(t, file, `INSERT INTO foo VALUES (2);`)
+	c := next()
+	if !c.Data || c.Schema {
+		t.Errorf("insert: %+v", c)
+	}
+
+	execute(t, file, `CREATE TABLE bar (b);`)
+	// a transaction can be seen in parts
+	for !c.Schema {
+		c = next()
+	}
+	if !c.Data {
+		t.Errorf("create: %+v", c)
+	}
+
+	var n int
+	if err := db.Select("foo", func(sqlittle.Row) { n++ }); err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("have %d rows", n)
+	}
+}
@@ -0,0 +1,88 @@
+// +build ci
+
+package ci
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/andreyvit/diff"
+	"github.com/davecgh/go-spew/spew"
+	"github.com/hackborn/sqlittle"
+)
+
+func sortRows(rows [][]string) [][]string {
+	sort.Slice(rows, func(i, j int) bool {
+		return strings.Join(rows[i], "|") < strings.Join(rows[j], "|")
+	})
+	return rows
+}
+
+func TestWhere(t *testing.T) {
+	file, close := tmpfile(t)
+	defer close()
+
+	create(t, file, `
+CREATE TABLE foo (a, b COLLATE nocase, c INTEGER, d);
+CREATE INDEX foo_ab ON foo (a, b);
+CREATE INDEX foo_b ON foo (b DESC);
+CREATE INDEX foo_c ON foo (c COLLATE rtrim);
+CREATE INDEX foo_d ON foo (d) WHERE d > 100;
+CREATE TABLE bar (a, b, c, PRIMARY KEY (a, b DESC)) WITHOUT ROWID;
+CREATE INDEX bar_c ON bar (c);
+WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i+1 FROM n WHERE i < 500)
+	INSERT INTO foo SELECT i % 7, char(65 + i % 5, 97 + i % 3), i % 11, i FROM n;
+INSERT INTO foo VALUES (NULL, NULL, NULL, NULL);
+INSERT INTO foo VALUES ("text", "ABC", "12", 3.5);
+WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i+1 FROM n WHERE i < 300)
+	INSERT INTO bar SELECT i % 13, i, i % 5 FROM n;
+`)
+
+	db, err := sqlittle.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	type cas struct {
+		table string
+		conds []sqlittle.Cond
+		where string
+	}
+	for _, c := range []cas{
+		{"foo", []sqlittle.Cond{sqlittle.Eq("a", 3)}, "a = 3"},
+		{"foo", []sqlittle.Cond{sqlittle.Eq("a", 3), sqlittle.Eq("b", "cB")}, "a = 3 AND b = 'cB'"},
+		{"foo", []sqlittle.Cond{sqlittle.Eq("a", 3), sqlittle.Gt("b", "c")}, "a = 3 AND b > 'c'"},
+		{"foo", []sqlittle.Cond{sqlittle.Ge("b", "b"), sqlittle.Lt("b", "D")}, "b >= 'b' AND b < 'D'"},
+		{"foo", []sqlittle.Cond{sqlittle.Le("b", "bz")}, "b <= 'bz'"},
+		{"foo", []sqlittle.Cond{sqlittle.Eq("c", 4)}, "c = 4"},
+		{"foo", []sqlittle.Cond{sqlittle.Gt("c", 8), sqlittle.Lt("rowid", 40)}, "c > 8 AND rowid < 40"},
+		{"foo", []sqlittle.Cond{sqlittle.Gt("d", 490)}, "d > 490"},
+		{"foo", []sqlittle.Cond{sqlittle.Gt("d", 95), sqlittle.Lt("d", 102)}, "d > 95 AND d < 102"},
+		{"foo", []sqlittle.Cond{sqlittle.IsNull("a")}, "a IS NULL"},
+		{"foo", []sqlittle.Cond{sqlittle.Gt("a", 5)}, "a > 5"},
+		{"foo", []sqlittle.Cond{sqlittle.Gt("rowid", 10.5), sqlittle.Le("rowid", 20)}, "rowid > 10.5 AND rowid <= 20"},
+		{"bar", []sqlittle.Cond{sqlittle.Eq("a", 4)}, "a = 4"},
+		{"bar", []sqlittle.Cond{sqlittle.Eq("a", 4), sqlittle.Gt("b", 200)}, "a = 4 AND b > 200"},
+		{"bar", []sqlittle.Cond{sqlittle.Eq("c", 2), sqlittle.Lt("b", 50)}, "c = 2 AND b < 50"},
+		{"bar", []sqlittle.Cond{sqlittle.Ge("a", 11)}, "a >= 11"},
+	} {
+		explain, err := db.Explain(c.table, c.conds, "a", "b")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := execute(t, file, "SELECT a, b FROM "+c.table+" WHERE "+c.where)
+		var have [][]string
+		if err := db.Where(c.table, c.conds, func(r sqlittle.Row) {
+			have = append(have, r.ScanStrings())
+		}, "a", "b"); err != nil {
+			t.Fatal(err)
+		}
+		if have, want := sortRows(have), sortRows(want); !reflect.DeepEqual(have, want) {
+			t.Errorf("%s (%s): diff:\n%s", c.where, explain, diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+		}
+	}
+}
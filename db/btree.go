@@ -10,6 +10,7 @@ package db
 import (
 	"encoding/binary"
 	"errors"
+	"math/rand"
 	"sort"
 )
 
@@ -34,8 +35,26 @@ type tableBtree interface {
 	Iter(int, *Database, iterCB) (bool, error)
 	// Scan starting from a rowid
 	IterMin(int, *Database, int64, iterCB) (bool, error)
-	// Count counts the number of records. For debugging.
+	// IterReverse goes over every record, last one first
+	IterReverse(int, *Database, iterCB) (bool, error)
+	// Count counts the number of records
 	Count(*Database) (int, error)
+	// Estimate guesses the number of records from a single random path
+	Estimate(int, *Database, *rand.Rand) (float64, error)
+	// Random gives a random record, or nil if the leaf page it ends in is
+	// empty
+	Random(int, *Database, *rand.Rand) (int64, *cellPayload, error)
+}
+
+// searchFunc is true for the records at or after the wanted position. It must
+// be false for all records before that position, and true for all after.
+type searchFunc func(Record) bool
+
+// keySearch finds the first record where the key is true.
+func keySearch(key Key) searchFunc {
+	return func(r Record) bool {
+		return Search(key, r)
+	}
 }
 
 // indexIterCB gets the Record. It returns true when the iter should be
@@ -47,8 +66,18 @@ type indexBtree interface {
 	Iter(int, *Database, indexIterCB) (bool, error)
 	// Scan starting from a key
 	IterMin(int, *Database, Key, indexIterCB) (bool, error)
-	// Count counts the number of records. For debugging.
+	// Scan starting from the first record where the search func is true
+	IterSearch(int, *Database, searchFunc, indexIterCB) (bool, error)
+	// IterReverse goes over every record, last one first
+	IterReverse(int, *Database, indexIterCB) (bool, error)
+	// Count counts the number of records
 	Count(*Database) (int, error)
+	// Estimate guesses the number of records from a single random path
+	Estimate(int, *Database, *rand.Rand) (float64, error)
+	// Random gives a random record, or nil if the leaf page it ends in is
+	// empty. The record after the leaf page is a candidate as well, so
+	// records on interior pages can be found.
+	Random(int, *Database, *rand.Rand, *cellPayload) (*cellPayload, error)
 }
 
 type tableLeafCell struct {
@@ -136,6 +165,18 @@ func (l *tableLeaf) Count(*Database) (int, error) {
 	return len(l.cells), nil
 }
 
+func (l *tableLeaf) Estimate(int, *Database, *rand.Rand) (float64, error) {
+	return float64(len(l.cells)), nil
+}
+
+func (l *tableLeaf) Random(_ int, _ *Database, rnd *rand.Rand) (int64, *cellPayload, error) {
+	if len(l.cells) == 0 {
+		return 0, nil, nil
+	}
+	c := l.cells[rnd.Intn(len(l.cells))]
+	return c.left, &c.payload, nil
+}
+
 func (l *tableLeaf) Iter(_ int, _ *Database, cb iterCB) (bool, error) {
 	for _, c := range l.cells {
 		if done, err := cb(c.left, c.payload); done || err != nil {
@@ -145,12 +186,24 @@ func (l *tableLeaf) Iter(_ int, _ *Database, cb iterCB) (bool, error) {
 	return false, nil
 }
 
+func (l *tableLeaf) IterReverse(_ int, _ *Database, cb iterCB) (bool, error) {
+	for i := len(l.cells) - 1; i >= 0; i-- {
+		c := l.cells[i]
+		if done, err := cb(c.left, c.payload); done || err != nil {
+			return done, err
+		}
+	}
+	return false, nil
+}
+
 func (l *tableLeaf) IterMin(_ int, db *Database, rowid int64, cb iterCB) (bool, error) {
 	n := sort.Search(len(l.cells), func(n int) bool {
 		return l.cells[n].left >= rowid
 	})
 	for _, c := range l.cells[n:] {
-		return cb(c.left, c.payload)
+		if done, err := cb(c.left, c.payload); done || err != nil {
+			return done, err
+		}
 	}
 	return false, nil
 }
@@ -203,7 +256,7 @@ func (l *tableInterior) cellIterMin(db *Database, rowid int64, cb interiorIterCB
 
 func (l *tableInterior) Count(db *Database) (int, error) {
 	total := 0
-	l.cellIter(db, func(p int) (bool, error) {
+	_, err := l.cellIter(db, func(p int) (bool, error) {
 		page, err := db.openTable(p)
 		if err != nil {
 			return false, err
@@ -215,7 +268,39 @@ func (l *tableInterior) Count(db *Database) (int, error) {
 		total += n
 		return false, nil
 	})
-	return total, nil
+	return total, err
+}
+
+// child picks a random child page.
+func (l *tableInterior) child(rnd *rand.Rand) int {
+	if n := rnd.Intn(len(l.cells) + 1); n < len(l.cells) {
+		return l.cells[n].left
+	}
+	return l.rightmost
+}
+
+// Estimate assumes all child pages are like the random one.
+func (l *tableInterior) Estimate(r int, db *Database, rnd *rand.Rand) (float64, error) {
+	if r == 0 {
+		return 0, ErrRecursion
+	}
+	page, err := db.openTable(l.child(rnd))
+	if err != nil {
+		return 0, err
+	}
+	n, err := page.Estimate(r-1, db, rnd)
+	return float64(len(l.cells)+1) * n, err
+}
+
+func (l *tableInterior) Random(r int, db *Database, rnd *rand.Rand) (int64, *cellPayload, error) {
+	if r == 0 {
+		return 0, nil, ErrRecursion
+	}
+	page, err := db.openTable(l.child(rnd))
+	if err != nil {
+		return 0, nil, err
+	}
+	return page.Random(r-1, db, rnd)
 }
 
 func (l *tableInterior) Iter(r int, db *Database, cb iterCB) (bool, error) {
@@ -234,6 +319,27 @@ func (l *tableInterior) Iter(r int, db *Database, cb iterCB) (bool, error) {
 	})
 }
 
+func (l *tableInterior) IterReverse(r int, db *Database, cb iterCB) (bool, error) {
+	if r == 0 {
+		return false, ErrRecursion
+	}
+	pages := make([]int, 0, len(l.cells)+1)
+	pages = append(pages, l.rightmost)
+	for i := len(l.cells) - 1; i >= 0; i-- {
+		pages = append(pages, l.cells[i].left)
+	}
+	for _, p := range pages {
+		page, err := db.openTable(p)
+		if err != nil {
+			return false, err
+		}
+		if done, err := page.IterReverse(r-1, db, cb); done || err != nil {
+			return done, err
+		}
+	}
+	return false, nil
+}
+
 func (l *tableInterior) IterMin(r int, db *Database, rowid int64, cb iterCB) (bool, error) {
 	if r == 0 {
 		return false, ErrRecursion
@@ -286,10 +392,31 @@ func (l *indexLeaf) Iter(_ int, db *Database, cb indexIterCB) (bool, error) {
 	return false, nil
 }
 
-func (l *indexLeaf) IterMin(_ int, db *Database, key Key, cb indexIterCB) (bool, error) {
+func (l *indexLeaf) IterReverse(_ int, db *Database, cb indexIterCB) (bool, error) {
+	for i := len(l.cells) - 1; i >= 0; i-- {
+		full, err := addOverflow(db, l.cells[i])
+		if err != nil {
+			return false, err
+		}
+		rec, err := parseRecord(full)
+		if err != nil {
+			return false, err
+		}
+		if done, err := cb(rec); done || err != nil {
+			return done, err
+		}
+	}
+	return false, nil
+}
+
+func (l *indexLeaf) IterMin(r int, db *Database, key Key, cb indexIterCB) (bool, error) {
+	return l.IterSearch(r, db, keySearch(key), cb)
+}
+
+func (l *indexLeaf) IterSearch(_ int, db *Database, search searchFunc, cb indexIterCB) (bool, error) {
 	var searchErr error
 	n := sort.Search(len(l.cells), func(n int) bool {
-		r, err := indexBinSearch(db, l.cells[n], key)
+		r, err := indexBinSearch(db, l.cells[n], search)
 		if err != nil {
 			searchErr = err
 		}
@@ -320,6 +447,24 @@ func (l *indexLeaf) Count(*Database) (int, error) {
 	return len(l.cells), nil
 }
 
+func (l *indexLeaf) Estimate(int, *Database, *rand.Rand) (float64, error) {
+	return float64(len(l.cells)), nil
+}
+
+func (l *indexLeaf) Random(_ int, _ *Database, rnd *rand.Rand, next *cellPayload) (*cellPayload, error) {
+	n := len(l.cells)
+	if next != nil {
+		n++
+	}
+	if n == 0 {
+		return nil, nil
+	}
+	if i := rnd.Intn(n); i < len(l.cells) {
+		return &l.cells[i], nil
+	}
+	return next, nil
+}
+
 func newInteriorIndex(
 	count int,
 	pointers []byte,
@@ -378,15 +523,57 @@ func (l *indexInterior) Iter(r int, db *Database, cb indexIterCB) (bool, error)
 	return page.Iter(r-1, db, cb)
 }
 
+func (l *indexInterior) IterReverse(r int, db *Database, cb indexIterCB) (bool, error) {
+	if r == 0 {
+		return false, ErrRecursion
+	}
+	page, err := db.openIndex(l.rightmost)
+	if err != nil {
+		return false, err
+	}
+	if done, err := page.IterReverse(r-1, db, cb); done || err != nil {
+		return done, err
+	}
+
+	for i := len(l.cells) - 1; i >= 0; i-- {
+		c := l.cells[i]
+		// the btree node has a record, which comes after its left page
+		full, err := addOverflow(db, c.payload)
+		if err != nil {
+			return false, err
+		}
+		rec, err := parseRecord(full)
+		if err != nil {
+			return false, err
+		}
+		if done, err := cb(rec); done || err != nil {
+			return done, err
+		}
+
+		page, err := db.openIndex(c.left)
+		if err != nil {
+			return false, err
+		}
+		if done, err := page.IterReverse(r-1, db, cb); done || err != nil {
+			return done, err
+		}
+	}
+	return false, nil
+}
+
 func (l *indexInterior) IterMin(r int, db *Database, key Key, cb indexIterCB) (bool, error) {
+	return l.IterSearch(r, db, keySearch(key), cb)
+}
+
+func (l *indexInterior) IterSearch(r int, db *Database, search searchFunc, cb indexIterCB) (bool, error) {
 	if r == 0 {
 		return false, ErrRecursion
 	}
 
-	// binary search the page containing the range of the key
+	// binary search the page containing the start
 	var searchErr error
 	n := sort.Search(len(l.cells), func(n int) bool {
-		r, err := indexBinSearch(db, l.cells[n].payload, key)
+		r, err := indexBinSearch(db, l.cells[n].payload, search)
 		if err != nil {
 			searchErr = err
 		}
@@ -407,7 +594,7 @@ func (l *indexInterior) IterMin(r int, db *Database, key Key, cb indexIterCB) (b
 				return done, err
 			}
 		} else {
-			if done, err := page.IterMin(r-1, db, key, cb); done || err != nil {
+			if done, err := page.IterSearch(r-1, db, search, cb); done || err != nil {
 				return done, err
 			}
 		}
@@ -434,8 +621,49 @@ func (l *indexInterior) IterMin(r int, db *Database, key Key, cb indexIterCB) (b
 	if useIter {
 		return page.Iter(r-1, db, cb)
 	} else {
-		return page.IterMin(r-1, db, key, cb)
+		return page.IterSearch(r-1, db, search, cb)
+	}
+}
+
+// Estimate assumes all child pages are like the random one. The page has a
+// record for every child page but the last.
+func (l *indexInterior) Estimate(r int, db *Database, rnd *rand.Rand) (float64, error) {
+	if r == 0 {
+		return 0, ErrRecursion
+	}
+	page, err := db.openIndex(l.child(rnd, nil))
+	if err != nil {
+		return 0, err
+	}
+	n, err := page.Estimate(r-1, db, rnd)
+	return float64(len(l.cells)) + float64(len(l.cells)+1)*n, err
+}
+
+// Random goes down a random child page. The record after a child page is the
+// record of its cell, or the record after this page for the rightmost page.
+func (l *indexInterior) Random(r int, db *Database, rnd *rand.Rand, next *cellPayload) (*cellPayload, error) {
+	if r == 0 {
+		return nil, ErrRecursion
+	}
+	p := l.child(rnd, &next)
+	page, err := db.openIndex(p)
+	if err != nil {
+		return nil, err
+	}
+	return page.Random(r-1, db, rnd, next)
+}
+
+// child picks a random child page. If next is not nil it's set to the record
+// after that page, if there is one on this page.
+func (l *indexInterior) child(rnd *rand.Rand, next **cellPayload) int {
+	n := rnd.Intn(len(l.cells) + 1)
+	if n == len(l.cells) {
+		return l.rightmost
+	}
+	if next != nil {
+		*next = &l.cells[n].payload
 	}
+	return l.cells[n].left
 }
 
 func (l *indexInterior) Count(db *Database) (int, error) {
@@ -495,7 +723,8 @@ func parsePayload(l int64, c []byte, pageSize int, maxInPagePayload int) (cellPa
 		return cellPayload{}, ErrCorrupted
 	}
 
-	c, overflow = c[:inPageBytes], int(binary.BigEndian.Uint32(c[inPageBytes:inPageBytes+4]))
+	// no spare capacity: addOverflow() appends to it, and the page is shared
+	c, overflow = c[:inPageBytes:inPageBytes], int(binary.BigEndian.Uint32(c[inPageBytes:inPageBytes+4]))
 	if overflow == 0 {
 		return cellPayload{}, ErrCorrupted
 	}
@@ -585,7 +814,7 @@ func parseCellpointers(
 	return cs, nil
 }
 
-func indexBinSearch(db *Database, pl cellPayload, key Key) (bool, error) {
+func indexBinSearch(db *Database, pl cellPayload, search searchFunc) (bool, error) {
 	// It would be possible to not load the record by default but compare with
 	// what's available, and to only call addOverflow() when that data is
 	// needed.
@@ -598,5 +827,5 @@ func indexBinSearch(db *Database, pl cellPayload, key Key) (bool, error) {
 		return true, err
 	}
 
-	return Search(key, rec), nil
+	return search(rec), nil
 }
@@ -91,6 +91,42 @@ func Search(key Key, r Record) bool {
 	return true
 }
 
+// SearchAfter is true if r is bigger than key, and it doesn't start with key.
+// Records which are equal to key on all key columns are before it.
+func SearchAfter(key Key, r Record) bool {
+	for i, k := range key {
+		if len(r)-1 < i {
+			return false
+		}
+		coll := DefaultCollate
+		if k.Collate != "" {
+			coll = k.Collate
+		}
+		cmp := compare(k.V, r[i], CollateFuncs[coll])
+		if k.Desc {
+			cmp = -cmp
+		}
+		switch {
+		case cmp < 0:
+			return true
+		case cmp > 0:
+			return false
+		}
+	}
+	return false
+}
+
+// Compare compares two Record values with SQLite's ordering rules. Strings are
+// compared with the named collate function, which should be empty (for the
+// default) or a key of CollateFuncs.
+// Returns -1, 0, or 1, same as strings.Compare().
+func Compare(a, b interface{}, collate string) int {
+	if collate == "" {
+		collate = DefaultCollate
+	}
+	return compare(a, b, CollateFuncs[collate])
+}
+
 // compare record values, with ordering according to SQLite's type sort order:
 //    nil < {int64|float64} < string < []byte
 //
@@ -98,11 +98,35 @@ func TestSearch(t *testing.T) {
 	)
 }
 
-func Testcompare(t *testing.T) {
+func TestSearchAfter(t *testing.T) {
+	for i, c := range []struct {
+		key  Key
+		rec  Record
+		want bool
+	}{
+		{Key{{V: int64(1)}}, Record{int64(42)}, true},
+		{Key{{V: int64(42)}}, Record{int64(42)}, false},
+		{Key{{V: int64(42)}}, Record{int64(42), int64(1)}, false},
+		{Key{{V: int64(82)}}, Record{int64(42)}, false},
+		{Key{{V: int64(82)}, {V: int64(12)}}, Record{int64(82), int64(14)}, true},
+		{Key{{V: int64(82)}, {V: int64(14)}}, Record{int64(82), int64(14)}, false},
+		{Key{{V: int64(82)}, {V: int64(14), Desc: true}}, Record{int64(82), int64(12)}, true},
+		{Key{{V: int64(82)}, {V: int64(12), Desc: true}}, Record{int64(82), int64(14)}, false},
+		{Key{{V: "fOo", Collate: "nocase"}}, Record{"foO", int64(1)}, false},
+		{Key{{V: "fOo", Collate: "nocase"}}, Record{"foP", int64(1)}, true},
+		{Key{{V: int64(82)}, {V: int64(14)}}, Record{int64(82)}, false},
+	} {
+		if have, want := SearchAfter(c.key, c.rec), c.want; have != want {
+			t.Errorf("case %d: have %t, want %t", i, have, want)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
 	test := func(a interface{}, b interface{}, want int) {
 		t.Helper()
 
-		if have, want := compare(a, b, CollateFuncs[""]), want; have != want {
+		if have, want := compare(a, b, CollateFuncs[DefaultCollate]), want; have != want {
 			t.Errorf("have %d, want %d", have, want)
 		}
 	}
@@ -153,3 +177,15 @@ func Testcompare(t *testing.T) {
 		test(CollateRtrim("aap"), "mies   ", -1)
 	*/
 }
+
+func TestCompareCollate(t *testing.T) {
+	if have, want := Compare("aap", "AAP", ""), 1; have != want {
+		t.Errorf("have %d, want %d", have, want)
+	}
+	if have, want := Compare("aap", "AAP", "nocase"), 0; have != want {
+		t.Errorf("have %d, want %d", have, want)
+	}
+	if have, want := Compare(int64(3), 2.5, "rtrim"), 1; have != want {
+		t.Errorf("have %d, want %d", have, want)
+	}
+}
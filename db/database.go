@@ -2,11 +2,14 @@ package db
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"math/bits"
 	"strings"
+	"sync"
+	"time"
 )
 
 const (
@@ -36,6 +39,9 @@ var (
 	// There is a stale `-journal` file present with an unfinished transaction.
 	// Open the database in sqlite3 to repair the database.
 	ErrHotJournal = errors.New("crashed transaction present")
+	// RLock() can't get the lock because another process is writing to the
+	// database. Try again later.
+	ErrLocked = errors.New("database is locked")
 
 	ErrNoSuchTable = errors.New("no such table")
 	ErrNoSuchIndex = errors.New("no such index")
@@ -56,12 +62,28 @@ type objectCache struct {
 }
 
 type Database struct {
-	journal     string
-	dirty       bool // reload header if true
-	l           pager
+	*state
+	file       string // empty if it's not a file
+	journal    string
+	l          pager
+	btreeCache *btreeCache     // table and index page cache
+	ctx        context.Context // can be nil
+	observer   Observer        // can be nil
+	reopen     bool            // see SetReopen()
+	onReopen   func()          // can be nil
+}
+
+// state is what's read after a lock. It's shared with the copies from
+// WithContext() and WithObserver(), so they update the original.
+type state struct {
+	// use is read locked from RLock() to RUnlock(). Reopening the file write
+	// locks it, so it waits until every reader is done with the old file.
+	use         sync.RWMutex
+	mu          sync.Mutex // for everything below
+	dirty       bool       // reload header if true
 	header      *header
-	btreeCache  *btreeCache // table and index page cache
 	objectCache *objectCache
+	lockedAt    time.Time // of the last RLock(), for EventUnlock
 }
 
 // OpenFile opens a .sqlite file. This is the main entry point.
@@ -71,13 +93,15 @@ func OpenFile(f string) (*Database, error) {
 	if err != nil {
 		return nil, err
 	}
-	return newDatabase(l, f+"-journal")
+	db, err := newDatabase(l, f+"-journal")
+	db.file = f
+	return db, err
 }
 
 func newDatabase(l pager, journal string) (*Database, error) {
 	d := &Database{
+		state:      &state{dirty: true},
 		journal:    journal,
-		dirty:      true,
 		l:          l,
 		btreeCache: newBtreeCache(CachePages),
 	}
@@ -89,15 +113,174 @@ func (db *Database) Close() error {
 	return db.l.Close()
 }
 
-// Lock database for reading. Blocks. Don't nest RLock() calls.
+// Lock database for reading. Blocks. Don't nest RLock() calls. Different
+// goroutines can hold the lock at the same time.
 func (db *Database) RLock() error {
+	db.use.RLock()
+	start := time.Now()
+	err := db.rlock()
+	if err != nil {
+		db.use.RUnlock()
+	}
+	if db.observer == nil {
+		return err
+	}
+	now := time.Now()
+	db.mu.Lock()
+	db.lockedAt = now
+	db.mu.Unlock()
+	db.observe(Event{Type: EventLock, Duration: now.Sub(start), Err: err})
+	return err
+}
+
+func (db *Database) rlock() error {
+	if err := db.l.RLock(); err != nil {
+		return err
+	}
+	db.mu.Lock()
+	db.dirty = true
+	db.mu.Unlock()
+	if !db.reopen {
+		return nil
+	}
+	return db.reopenReplaced()
+}
+
+// SetReopen enables or disables reopening the file when it's replaced. That's
+// for files which are updated by writing a new file and renaming it over the
+// old one. Normally the database keeps reading the old file, since that's the
+// one which is open.
+//
+// When it's enabled every RLock() compares the file at the path with the open
+// file. If they differ, the new file is opened, the page and schema caches are
+// cleared, and cb is called, if it's not nil. cb is called with the read lock
+// held, and gets an EventReopen to the observer. The reopen waits until other
+// goroutines which hold the lock RUnlock(), so reads which are in progress
+// finish on the old file. Reads which don't lock, such as Version() and
+// Watcher(), keep using the old file until the next RLock().
+//
+// Set it before the database is used.
+func (db *Database) SetReopen(on bool, cb func()) {
+	db.reopen = on
+	db.onReopen = cb
+}
+
+// reopenReplaced reopens the file if it was replaced. The file lock is held
+// before, and after if there is no error. db.use is read locked before and
+// after.
+func (db *Database) reopenReplaced() error {
+	r, ok := db.l.(reopener)
+	if !ok {
+		return nil
+	}
+	replaced, err := r.replaced()
+	if err != nil {
+		db.l.RUnlock()
+		return err
+	}
+	if !replaced {
+		return nil
+	}
+	if err := db.l.RUnlock(); err != nil {
+		return err
+	}
+	db.use.RUnlock()
+	db.use.Lock()
+	reopened, err := db.reopenFile(r)
+	db.use.Unlock()
+	db.use.RLock()
+	if err != nil {
+		return err
+	}
+	if err := db.l.RLock(); err != nil {
+		return err
+	}
+	if reopened {
+		db.observe(Event{Type: EventReopen})
+		if db.onReopen != nil {
+			db.onReopen()
+		}
+	}
+	return nil
+}
+
+// reopenFile opens the file again, and clears the caches. Only call it with
+// db.use write locked, so nobody reads the old file anymore. It does nothing
+// if another goroutine already reopened it.
+func (db *Database) reopenFile(r reopener) (bool, error) {
+	replaced, err := r.replaced()
+	if err != nil || !replaced {
+		return false, err
+	}
+	if err := r.reopen(); err != nil {
+		return false, err
+	}
+	db.btreeCache.clear()
+	db.mu.Lock()
+	db.objectCache = nil
+	db.header = nil
 	db.dirty = true
-	return db.l.RLock()
+	db.mu.Unlock()
+	return true, nil
 }
 
 // Unlock a read lock. Use a single RUnlock() for every RLock().
 func (db *Database) RUnlock() error {
-	return db.l.RUnlock()
+	err := db.l.RUnlock()
+	db.use.RUnlock()
+	if db.observer != nil {
+		db.mu.Lock()
+		lockedAt := db.lockedAt
+		db.mu.Unlock()
+		db.observe(Event{Type: EventUnlock, Duration: time.Since(lockedAt), Err: err})
+	}
+	return err
+}
+
+// Version has the header fields which SQLite changes when it changes the
+// database.
+type Version struct {
+	// ChangeCounter goes up with every committed transaction.
+	ChangeCounter uint32
+	// SchemaCookie goes up with every schema change.
+	SchemaCookie uint32
+}
+
+// Version reads the counters from the file header. It doesn't take a lock, so
+// it's safe to call while other goroutines use the database, but during a
+// write it can give the new counters before the transaction is done.
+func (db *Database) Version() (Version, error) {
+	buf, err := db.l.page(1, headerSize)
+	if err != nil {
+		return Version{}, err
+	}
+	h, err := parseHeader(buf)
+	if err != nil {
+		return Version{}, err
+	}
+	return Version{ChangeCounter: h.ChangeCounter, SchemaCookie: h.SchemaCookie}, nil
+}
+
+// WithContext gives a copy of the database which stops reading pages with
+// ctx.Err() when the context is done. Every table and index opened from the
+// copy does that. The context is checked before every page, so a scan
+// stops after at most a page of rows.
+//
+// The copy shares the file, the lock, and the page cache with the original.
+// Use it for a single scan, between RLock() and RUnlock() on the original, and
+// don't close it.
+func (db *Database) WithContext(ctx context.Context) *Database {
+	c := *db
+	c.ctx = ctx
+	return &c
+}
+
+// ctxErr is the context error, if there is a context.
+func (db *Database) ctxErr() error {
+	if db.ctx == nil {
+		return nil
+	}
+	return db.ctx.Err()
 }
 
 // n starts at 1, sqlite style
@@ -213,6 +396,8 @@ func parseHeader(b []byte) (header, error) {
 }
 
 func (db *Database) resolveDirty() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
 	if !db.dirty {
 		return nil
 	}
@@ -243,14 +428,20 @@ func (db *Database) resolveDirty() error {
 	if err != nil {
 		return err
 	}
-	if db.header != nil && db.header.ChangeCounter != newHeader.ChangeCounter {
+	changed := db.header != nil && db.header.ChangeCounter != newHeader.ChangeCounter
+	if changed {
 		db.btreeCache.clear()
 	}
+	db.observe(Event{Type: EventHeaderReload, Changed: changed})
 	if db.header != nil && db.header.SchemaCookie != newHeader.SchemaCookie {
 		db.objectCache = nil
 	}
 	db.dirty = false
-	db.header = &newHeader
+	// other goroutines which hold the lock use the header, and it can't
+	// change while they do
+	if db.header == nil || *db.header != newHeader {
+		db.header = &newHeader
+	}
 	return nil
 }
 
@@ -273,9 +464,13 @@ func (db *Database) master() ([]sqliteMaster, error) {
 		return nil, err
 	}
 
-	if o := db.objectCache; o != nil {
+	db.mu.Lock()
+	o := db.objectCache
+	db.mu.Unlock()
+	if o != nil {
 		return o.objects, o.err
 	}
+	db.observe(Event{Type: EventSchemaReload})
 
 	master, err := db.openTable(1)
 	if err != nil {
@@ -329,23 +524,30 @@ func (db *Database) master() ([]sqliteMaster, error) {
 		return false, nil
 	})
 
+	db.mu.Lock()
 	db.objectCache = &objectCache{
 		objects: objects,
 		err:     err,
 	}
+	db.mu.Unlock()
 
 	return objects, err
 }
 
 // openPage returns a tableBtree or indexBtree
 func (db *Database) openPage(page int) (interface{}, error) {
+	if err := db.ctxErr(); err != nil {
+		return nil, err
+	}
 	if err := db.resolveDirty(); err != nil {
 		return nil, err
 	}
 
 	if p := db.btreeCache.get(page); p != nil {
+		db.observe(Event{Type: EventPageRead, Page: page, Cached: true})
 		return p, nil
 	}
+	db.observe(Event{Type: EventPageRead, Page: page})
 
 	buf, err := db.page(page)
 	if err != nil {
@@ -382,6 +584,18 @@ func (db *Database) openIndex(page int) (indexBtree, error) {
 	return tb, nil
 }
 
+// openRoot opens the root page of a table, to start a scan or a search.
+func (db *Database) openRoot(page int) (tableBtree, error) {
+	db.observe(Event{Type: EventDescend, Page: page})
+	return db.openTable(page)
+}
+
+// openIndexRoot opens the root page of an index, to start a scan or a search.
+func (db *Database) openIndexRoot(page int) (indexBtree, error) {
+	db.observe(Event{Type: EventDescend, Page: page})
+	return db.openIndex(page)
+}
+
 // Tables lists all table names. Also sqlite internal ones.
 func (db *Database) Tables() ([]string, error) {
 	return db.objectNames("table")
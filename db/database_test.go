@@ -1,9 +1,14 @@
 package db
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"io"
+	"io/ioutil"
 	"math/rand"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 	"time"
@@ -459,6 +464,150 @@ func TestDatabaseLock(t *testing.T) {
 	}
 }
 
+func TestDatabaseCopy(t *testing.T) {
+	db, err := OpenFile("./../testdata/words.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	c := &Counters{}
+	db.SetObserver(c)
+
+	for i := 0; i < 3; i++ {
+		if err := db.RLock(); err != nil {
+			t.Fatal(err)
+		}
+		// the copy reads the schema for the original
+		if _, err := db.WithContext(context.Background()).Tables(); err != nil {
+			t.Fatal(err)
+		}
+		db.RUnlock()
+	}
+	if have, want := c.Snapshot().SchemaReloads, int64(1); have != want {
+		t.Errorf("have %d, want %d", have, want)
+	}
+}
+
+func TestDatabaseGrow(t *testing.T) {
+	b, err := ioutil.ReadFile("./../testdata/words.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := ioutil.TempFile("", "sqlittle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	if _, err := f.Write(b); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := OpenFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	pagesize := int(db.header.PageSize)
+	n := len(b) / pagesize
+	if _, err := db.l.page(n+1, pagesize); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	// another process appends a page
+	extra := bytes.Repeat([]byte{42}, pagesize)
+	if _, err := f.Write(extra); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.RLock(); err != nil {
+		t.Fatal(err)
+	}
+	defer db.RUnlock()
+	p, err := db.l.page(n+1, pagesize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(p, extra) {
+		t.Errorf("wrong page content")
+	}
+}
+
+func TestDatabaseReopen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sqlittle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "db.sqlite")
+	cp := func(from, to string) {
+		t.Helper()
+		b, err := ioutil.ReadFile(from)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(to, b, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, reopen := range []bool{false, true} {
+		cp("./../testdata/words.sqlite", file)
+
+		db, err := OpenFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reopened := 0
+		db.SetReopen(reopen, func() { reopened++ })
+		c := &Counters{}
+		db.SetObserver(c)
+
+		tables := func() []string {
+			t.Helper()
+			if err := db.RLock(); err != nil {
+				t.Fatal(err)
+			}
+			defer db.RUnlock()
+			ts, err := db.Tables()
+			if err != nil {
+				t.Fatal(err)
+			}
+			return ts
+		}
+		if have, want := tables(), []string{"words"}; !reflect.DeepEqual(have, want) {
+			t.Errorf("have %v, want %v", have, want)
+		}
+
+		// replace the file
+		cp("./../testdata/empty.sqlite", file+".new")
+		if err := os.Rename(file+".new", file); err != nil {
+			t.Fatal(err)
+		}
+
+		want := []string{"words"}
+		if reopen {
+			want = []string{"foo"}
+		}
+		if have := tables(); !reflect.DeepEqual(have, want) {
+			t.Errorf("reopen %t: have %v, want %v", reopen, have, want)
+		}
+		// only once
+		tables()
+		wantN := 0
+		if reopen {
+			wantN = 1
+		}
+		if have, want := reopened, wantN; have != want {
+			t.Errorf("reopen %t: have %d, want %d", reopen, have, want)
+		}
+		if have, want := c.Snapshot().Reopens, int64(wantN); have != want {
+			t.Errorf("reopen %t: have %d, want %d", reopen, have, want)
+		}
+		db.Close()
+	}
+}
+
 func TestDatabaseSchema(t *testing.T) {
 	db, err := OpenFile("./../testdata/words.sqlite")
 	if err != nil {
@@ -3,8 +3,11 @@
 package db
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"math"
+	"math/rand"
 
 	"github.com/hackborn/sqlittle/sql"
 )
@@ -61,7 +64,7 @@ func (t *Table) Def() (*sql.CreateTableStmt, error) {
 //  the value
 // If the callback returns true (done) the scan will be stopped.
 func (t *Table) Scan(cb TableScanCB) error {
-	root, err := t.db.openTable(t.root)
+	root, err := t.db.openRoot(t.root)
 	if err != nil {
 		return err
 	}
@@ -84,12 +87,18 @@ func (t *Table) Scan(cb TableScanCB) error {
 	return err
 }
 
+// ScanContext is Scan(), but it stops with ctx.Err() when the context is
+// done. The context is checked before every page.
+func (t *Table) ScanContext(ctx context.Context, cb TableScanCB) error {
+	return t.withContext(ctx).Scan(cb)
+}
+
 // Rowid finds a single row by rowid. Will return nil if it isn't found.
 // The rowid is an internal id, but if you have an `integer primary key` column
 // that should be the same.
 // See Table.Scan comments about the Record
 func (t *Table) Rowid(rowid int64) (Record, error) {
-	root, err := t.db.openTable(t.root)
+	root, err := t.db.openRoot(t.root)
 	if err != nil {
 		return nil, err
 	}
@@ -119,6 +128,134 @@ func (t *Table) Rowid(rowid int64) (Record, error) {
 	return parseRecord(c)
 }
 
+// ScanMin calls cb() for every row in the table with a rowid equal or larger
+// than the given rowid, in rowid order.
+// See Table.Scan comments about the Record.
+// If the callback returns true (done) the scan will be stopped.
+func (t *Table) ScanMin(rowid int64, cb TableScanCB) error {
+	root, err := t.db.openRoot(t.root)
+	if err != nil {
+		return err
+	}
+	_, err = root.IterMin(
+		maxRecursion,
+		t.db,
+		rowid,
+		func(k int64, pl cellPayload) (bool, error) {
+			c, err := addOverflow(t.db, pl)
+			if err != nil {
+				return false, err
+			}
+
+			rec, err := parseRecord(c)
+			if err != nil {
+				return false, err
+			}
+			return cb(k, rec), nil
+		},
+	)
+	return err
+}
+
+// ScanMinContext is ScanMin(), but it stops with ctx.Err() when the context is
+// done. The context is checked before every page.
+func (t *Table) ScanMinContext(ctx context.Context, rowid int64, cb TableScanCB) error {
+	return t.withContext(ctx).ScanMin(rowid, cb)
+}
+
+// ScanReverse is Scan(), but it goes from the highest rowid to the lowest.
+// If the callback returns true (done) the scan will be stopped.
+func (t *Table) ScanReverse(cb TableScanCB) error {
+	root, err := t.db.openRoot(t.root)
+	if err != nil {
+		return err
+	}
+	_, err = root.IterReverse(
+		maxRecursion,
+		t.db,
+		func(rowid int64, pl cellPayload) (bool, error) {
+			c, err := addOverflow(t.db, pl)
+			if err != nil {
+				return false, err
+			}
+
+			rec, err := parseRecord(c)
+			if err != nil {
+				return false, err
+			}
+			return cb(rowid, rec), nil
+		},
+	)
+	return err
+}
+
+// ScanReverseContext is ScanReverse(), but it stops with ctx.Err() when the context is
+// done. The context is checked before every page.
+func (t *Table) ScanReverseContext(ctx context.Context, cb TableScanCB) error {
+	return t.withContext(ctx).ScanReverse(cb)
+}
+
+// Count returns the number of rows in the table. It needs to read every leaf
+// page, but it doesn't look at the records.
+func (t *Table) Count() (int, error) {
+	root, err := t.db.openRoot(t.root)
+	if err != nil {
+		return 0, err
+	}
+	return root.Count(t.db)
+}
+
+// CountContext is Count(), but it stops with ctx.Err() when the context is
+// done.
+func (t *Table) CountContext(ctx context.Context) (int, error) {
+	return t.withContext(ctx).Count()
+}
+
+func (t *Table) withContext(ctx context.Context) *Table {
+	c := *t
+	c.db = t.db.WithContext(ctx)
+	return &c
+}
+
+// EstimateCount guesses the number of rows in the table. It follows a few
+// random paths from the root page down to a leaf page, and assumes that all
+// pages look like the ones it saw. That reads only a few pages, even for
+// big tables. The guess is exact when the table fits on a single page.
+//
+// The paths are the same for every call, so an unchanged table gives the same
+// number every time.
+func (t *Table) EstimateCount() (int, error) {
+	root, err := t.db.openRoot(t.root)
+	if err != nil {
+		return 0, err
+	}
+	return estimate(rand.New(rand.NewSource(int64(t.root))), func(rnd *rand.Rand) (float64, error) {
+		return root.Estimate(maxRecursion, t.db, rnd)
+	})
+}
+
+// Random gives a random row from the table. It follows a random path down
+// the btree, so it reads only a few pages, but rows on pages which have fewer
+// rows than their siblings are somewhat more likely to be picked.
+// The record will be nil if the table is empty.
+// See Table.Scan comments about the Record.
+func (t *Table) Random(rnd *rand.Rand) (int64, Record, error) {
+	root, err := t.db.openRoot(t.root)
+	if err != nil {
+		return 0, nil, err
+	}
+	rowid, pl, err := root.Random(maxRecursion, t.db, rnd)
+	if err != nil || pl == nil {
+		return 0, nil, err
+	}
+	c, err := addOverflow(t.db, *pl)
+	if err != nil {
+		return 0, nil, err
+	}
+	rec, err := parseRecord(c)
+	return rowid, rec, err
+}
+
 // Def returns the index definition.
 func (t *Index) Def() (*sql.CreateIndexStmt, error) {
 	c, err := sql.Parse(t.sql)
@@ -139,7 +276,7 @@ func (t *Index) Def() (*sql.CreateIndexStmt, error) {
 // For a WITHOUT ROWID table the columns depend on your table structure.
 // If the callback returns true (done) the scan will be stopped.
 func (in *Index) Scan(cb RecordCB) error {
-	root, err := in.db.openIndex(in.root)
+	root, err := in.db.openIndexRoot(in.root)
 	if err != nil {
 		return err
 	}
@@ -154,9 +291,91 @@ func (in *Index) Scan(cb RecordCB) error {
 	return err
 }
 
+// ScanContext is Scan(), but it stops with ctx.Err() when the context is
+// done. The context is checked before every page.
+func (in *Index) ScanContext(ctx context.Context, cb RecordCB) error {
+	return in.withContext(ctx).Scan(cb)
+}
+
+// ScanReverse is Scan(), but in reverse index order: the last record first.
+// If the callback returns true (done) the scan will be stopped.
+func (in *Index) ScanReverse(cb RecordCB) error {
+	root, err := in.db.openIndexRoot(in.root)
+	if err != nil {
+		return err
+	}
+
+	_, err = root.IterReverse(
+		maxRecursion,
+		in.db,
+		func(rec Record) (bool, error) {
+			return cb(rec), nil
+		},
+	)
+	return err
+}
+
+// ScanReverseContext is ScanReverse(), but it stops with ctx.Err() when the context is
+// done. The context is checked before every page.
+func (in *Index) ScanReverseContext(ctx context.Context, cb RecordCB) error {
+	return in.withContext(ctx).ScanReverse(cb)
+}
+
+// Count returns the number of records in the index. It needs to read every
+// page, but it doesn't decode the records.
+func (in *Index) Count() (int, error) {
+	root, err := in.db.openIndexRoot(in.root)
+	if err != nil {
+		return 0, err
+	}
+	return root.Count(in.db)
+}
+
+// CountContext is Count(), but it stops with ctx.Err() when the context is
+// done.
+func (in *Index) CountContext(ctx context.Context) (int, error) {
+	return in.withContext(ctx).Count()
+}
+
+func (in *Index) withContext(ctx context.Context) *Index {
+	c := *in
+	c.db = in.db.WithContext(ctx)
+	return &c
+}
+
+// EstimateCount guesses the number of records in the index. See
+// Table.EstimateCount().
+func (in *Index) EstimateCount() (int, error) {
+	root, err := in.db.openIndexRoot(in.root)
+	if err != nil {
+		return 0, err
+	}
+	return estimate(rand.New(rand.NewSource(int64(in.root))), func(rnd *rand.Rand) (float64, error) {
+		return root.Estimate(maxRecursion, in.db, rnd)
+	})
+}
+
+// Random gives a random record from the index, or nil if the index is empty.
+// See Table.Random().
+func (in *Index) Random(rnd *rand.Rand) (Record, error) {
+	root, err := in.db.openIndexRoot(in.root)
+	if err != nil {
+		return nil, err
+	}
+	pl, err := root.Random(maxRecursion, in.db, rnd, nil)
+	if err != nil || pl == nil {
+		return nil, err
+	}
+	c, err := addOverflow(in.db, *pl)
+	if err != nil {
+		return nil, err
+	}
+	return parseRecord(c)
+}
+
 // Scan all record matching key
 func (in *Index) ScanEq(key Key, cb RecordCB) error {
-	root, err := in.db.openIndex(in.root)
+	root, err := in.db.openIndexRoot(in.root)
 	if err != nil {
 		return err
 	}
@@ -175,13 +394,19 @@ func (in *Index) ScanEq(key Key, cb RecordCB) error {
 	return err
 }
 
+// ScanEqContext is ScanEq(), but it stops with ctx.Err() when the context is
+// done. The context is checked before every page.
+func (in *Index) ScanEqContext(ctx context.Context, key Key, cb RecordCB) error {
+	return in.withContext(ctx).ScanEq(key, cb)
+}
+
 // ScanMin calls cb() for every row in the index, starting from the first
 // record where key is true.
 //
 // If the callback returns true (done) the scan will be stopped.
 // All comments from Index.Scan are valid here as well.
 func (in *Index) ScanMin(from Key, cb RecordCB) error {
-	root, err := in.db.openIndex(in.root)
+	root, err := in.db.openIndexRoot(in.root)
 	if err != nil {
 		return err
 	}
@@ -197,12 +422,49 @@ func (in *Index) ScanMin(from Key, cb RecordCB) error {
 	return err
 }
 
+// ScanMinContext is ScanMin(), but it stops with ctx.Err() when the context is
+// done. The context is checked before every page.
+func (in *Index) ScanMinContext(ctx context.Context, from Key, cb RecordCB) error {
+	return in.withContext(ctx).ScanMin(from, cb)
+}
+
+// ScanAfter calls cb() for every row in the index after all records which start
+// with key. This can be used to jump to the next distinct value of the first
+// index columns.
+//
+// If the callback returns true (done) the scan will be stopped.
+// All comments from Index.Scan are valid here as well.
+func (in *Index) ScanAfter(key Key, cb RecordCB) error {
+	root, err := in.db.openIndexRoot(in.root)
+	if err != nil {
+		return err
+	}
+
+	_, err = root.IterSearch(
+		maxRecursion,
+		in.db,
+		func(rec Record) bool {
+			return SearchAfter(key, rec)
+		},
+		func(rec Record) (bool, error) {
+			return cb(rec), nil
+		},
+	)
+	return err
+}
+
+// ScanAfterContext is ScanAfter(), but it stops with ctx.Err() when the context is
+// done. The context is checked before every page.
+func (in *Index) ScanAfterContext(ctx context.Context, key Key, cb RecordCB) error {
+	return in.withContext(ctx).ScanAfter(key, cb)
+}
+
 // Find all records where from(index) is true, and to(index) is false.
 //
 // You'll have to compensate for DESC columns.
 //
 func (in *Index) ScanRange(from, to Key, cb RecordCB) error {
-	root, err := in.db.openIndex(in.root)
+	root, err := in.db.openIndexRoot(in.root)
 	if err != nil {
 		return err
 	}
@@ -220,3 +482,25 @@ func (in *Index) ScanRange(from, to Key, cb RecordCB) error {
 	)
 	return err
 }
+
+// ScanRangeContext is ScanRange(), but it stops with ctx.Err() when the context is
+// done. The context is checked before every page.
+func (in *Index) ScanRangeContext(ctx context.Context, from, to Key, cb RecordCB) error {
+	return in.withContext(ctx).ScanRange(from, to, cb)
+}
+
+// number of random paths EstimateCount() follows
+const estimatePaths = 8
+
+// estimate averages the guesses from a few random paths.
+func estimate(rnd *rand.Rand, path func(*rand.Rand) (float64, error)) (int, error) {
+	total := 0.0
+	for i := 0; i < estimatePaths; i++ {
+		n, err := path(rnd)
+		if err != nil {
+			return 0, err
+		}
+		total += n
+	}
+	return int(math.Round(total / estimatePaths)), nil
+}
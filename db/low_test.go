@@ -1,7 +1,12 @@
 package db
 
 import (
+	"context"
+	"math/rand"
 	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/andreyvit/diff"
@@ -276,3 +281,385 @@ func TestLowScanRangeDesc(t *testing.T) {
 		t.Errorf("diff:\n%s", diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
 	}
 }
+
+func TestLowScanMin(t *testing.T) {
+	db, err := OpenFile("./../testdata/words.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	table, err := db.Table("words")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// crosses page boundaries
+	var rowids []int64
+	if err := table.ScanMin(
+		990,
+		func(rowid int64, r Record) bool {
+			rowids = append(rowids, rowid)
+			return false
+		}); err != nil {
+		t.Fatal(err)
+	}
+	want := []int64{990, 991, 992, 993, 994, 995, 996, 997, 998, 999, 1000}
+	if have, want := rowids, want; !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+
+	for from, want := range map[int64]int{
+		-10:  1000,
+		500:  501,
+		1000: 1,
+		1001: 0,
+	} {
+		count := 0
+		if err := table.ScanMin(
+			from,
+			func(rowid int64, r Record) bool {
+				count++
+				return false
+			}); err != nil {
+			t.Fatal(err)
+		}
+		if have := count; have != want {
+			t.Errorf("from %d: have %v, want %v", from, have, want)
+		}
+	}
+}
+
+func TestLowScanReverse(t *testing.T) {
+	db, err := OpenFile("./../testdata/words.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	table, err := db.Table("words")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rowids []int64
+	if err := table.ScanReverse(func(rowid int64, r Record) bool {
+		rowids = append(rowids, rowid)
+		return false
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := len(rowids), 1000; have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+	for i, rowid := range rowids {
+		if have, want := rowid, int64(1000-i); have != want {
+			t.Fatalf("have %d, want %d", have, want)
+		}
+	}
+	if n, err := table.Count(); err != nil || n != 1000 {
+		t.Errorf("count: %d, %v", n, err)
+	}
+
+	// crosses page boundaries, and interior records
+	index, err := db.Index("words_index_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var forward, reverse []Record
+	if err := index.Scan(func(r Record) bool {
+		forward = append(forward, r)
+		return false
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := index.ScanReverse(func(r Record) bool {
+		reverse = append(reverse, r)
+		return false
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for i, j := 0, len(reverse)-1; i < j; i, j = i+1, j-1 {
+		reverse[i], reverse[j] = reverse[j], reverse[i]
+	}
+	if !reflect.DeepEqual(forward, reverse) {
+		t.Errorf("diff:\n%s", diff.LineDiff(spew.Sdump(forward), spew.Sdump(reverse)))
+	}
+	if n, err := index.Count(); err != nil || n != 1000 {
+		t.Errorf("count: %d, %v", n, err)
+	}
+
+	var last Record
+	if err := index.ScanReverse(func(r Record) bool {
+		last = r
+		return true
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := last, forward[len(forward)-1]; !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+}
+
+func TestLowEstimate(t *testing.T) {
+	db, err := OpenFile("./../testdata/words.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	table, err := db.Table("words")
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := table.EstimateCount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n < 500 || n > 2000 {
+		t.Errorf("table estimate: %d", n)
+	}
+	if again, err := table.EstimateCount(); err != nil || again != n {
+		t.Errorf("have %d, want %d (%v)", again, n, err)
+	}
+
+	index, err := db.Index("words_index_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err = index.EstimateCount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n < 500 || n > 2000 {
+		t.Errorf("index estimate: %d", n)
+	}
+
+	// single page tables are exact
+	empty, err := OpenFile("./../testdata/empty.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer empty.Close()
+	foo, err := empty.Table("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := foo.EstimateCount(); err != nil || n != 0 {
+		t.Errorf("empty estimate: %d, %v", n, err)
+	}
+}
+
+func TestLowRandom(t *testing.T) {
+	db, err := OpenFile("./../testdata/words.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	rnd := rand.New(rand.NewSource(42))
+	table, err := db.Table("words")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rowids := map[int64]bool{}
+	for i := 0; i < 200; i++ {
+		rowid, r, err := table.Random(rnd)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rowid < 1 || rowid > 1000 || len(r) != 2 {
+			t.Fatalf("unexpected row: %d: %v", rowid, r)
+		}
+		rowids[rowid] = true
+	}
+	if len(rowids) < 100 {
+		t.Errorf("not very random: %d", len(rowids))
+	}
+
+	// interior records are found as well
+	index, err := db.Index("words_index_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	interior, err := db.openIndex(index.root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, ok := interior.(*indexInterior)
+	if !ok {
+		t.Fatalf("expected an interior root page")
+	}
+	want := map[string]bool{}
+	for _, c := range root.cells {
+		full, err := addOverflow(db, c.payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := parseRecord(full)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want[r[0].(string)] = true
+	}
+	found := 0
+	for i := 0; i < 5000; i++ {
+		r, err := index.Random(rnd)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want[r[0].(string)] {
+			found++
+		}
+	}
+	if found == 0 {
+		t.Errorf("no interior records found")
+	}
+
+	empty, err := OpenFile("./../testdata/empty.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer empty.Close()
+	foo, err := empty.Table("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, r, err := foo.Random(rnd); err != nil || r != nil {
+		t.Errorf("empty: %v, %v", r, err)
+	}
+}
+
+func TestLowParallelScan(t *testing.T) {
+	db, err := OpenFile("./../testdata/words.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	table, err := db.Table("words")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want []Record
+	if err := table.Scan(func(rowid int64, r Record) bool {
+		want = append(want, r)
+		return false
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, workers := range []int{0, 1, 3, 100} {
+		var (
+			mu   sync.Mutex
+			have = make([]Record, len(want))
+		)
+		if err := table.ParallelScan(workers, func(rowid int64, r Record) bool {
+			mu.Lock()
+			defer mu.Unlock()
+			have[rowid-1] = r
+			return false
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(have, want) {
+			t.Errorf("workers %d: diff:\n%s", workers, diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+		}
+	}
+
+	// stops early
+	var n int32
+	if err := table.ParallelScan(4, func(rowid int64, r Record) bool {
+		atomic.AddInt32(&n, 1)
+		return true
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if n > 4 {
+		t.Errorf("didn't stop: %d", n)
+	}
+
+	index, err := db.Index("words_index_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wantIndex []Record
+	if err := index.Scan(func(r Record) bool {
+		wantIndex = append(wantIndex, r)
+		return false
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var (
+		mu        sync.Mutex
+		haveIndex []Record
+	)
+	if err := index.ParallelScan(3, func(r Record) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		haveIndex = append(haveIndex, r)
+		return false
+	}); err != nil {
+		t.Fatal(err)
+	}
+	sort.Slice(haveIndex, func(i, j int) bool {
+		return haveIndex[i][1].(int64) < haveIndex[j][1].(int64)
+	})
+	sort.Slice(wantIndex, func(i, j int) bool {
+		return wantIndex[i][1].(int64) < wantIndex[j][1].(int64)
+	})
+	if !reflect.DeepEqual(haveIndex, wantIndex) {
+		t.Errorf("index diff:\n%s", diff.LineDiff(spew.Sdump(wantIndex), spew.Sdump(haveIndex)))
+	}
+}
+
+func TestLowContext(t *testing.T) {
+	db, err := OpenFile("./../testdata/words.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	table, err := db.Table("words")
+	if err != nil {
+		t.Fatal(err)
+	}
+	index, err := db.Index("words_index_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done, cancel := context.WithCancel(context.Background())
+	cancel()
+	if have, want := table.ScanContext(done, func(int64, Record) bool { return false }), context.Canceled; have != want {
+		t.Errorf("have %v, want %v", have, want)
+	}
+	if _, have := table.CountContext(done); have != context.Canceled {
+		t.Errorf("have %v, want %v", have, context.Canceled)
+	}
+	if have, want := index.ScanEqContext(done, Key{KeyCol{V: "foo"}}, func(Record) bool { return false }), context.Canceled; have != want {
+		t.Errorf("have %v, want %v", have, want)
+	}
+
+	// cancel halfway: stops at the next page
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	n := 0
+	err = table.ScanContext(ctx, func(int64, Record) bool {
+		n++
+		if n == 10 {
+			cancel()
+		}
+		return false
+	})
+	if have, want := err, context.Canceled; have != want {
+		t.Errorf("have %v, want %v", have, want)
+	}
+	if n >= 1000 {
+		t.Errorf("didn't stop: %d", n)
+	}
+
+	// the original doesn't have the context
+	if n, err := table.Count(); err != nil || n != 1000 {
+		t.Errorf("count: %d, %v", n, err)
+	}
+}
@@ -0,0 +1,212 @@
+// observer for metrics and tracing
+
+package db
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Observer gets events from a Database: page reads, btree descents, locks,
+// and reloads. See Database.SetObserver().
+//
+// Observe is called synchronously, while the database is being read, so it
+// should be fast. With ParallelScan() it's called from multiple goroutines at
+// the same time.
+type Observer interface {
+	Observe(Event)
+}
+
+// EventType is the kind of Event.
+type EventType int
+
+const (
+	// EventPageRead is a btree page which is needed. Event.Cached tells
+	// whether it came from the page cache or from the file.
+	EventPageRead EventType = iota
+	// EventOverflowRead is an overflow page read from the file, for a
+	// record which doesn't fit in its btree page.
+	EventOverflowRead
+	// EventDescend is a table or index scan or search starting at the root
+	// page of its btree.
+	EventDescend
+	// EventLock is RLock(). Event.Duration is the time it waited for the
+	// lock.
+	EventLock
+	// EventUnlock is RUnlock(). Event.Duration is the time the lock was held.
+	EventUnlock
+	// EventHeaderReload is the file header being read again, after an
+	// RLock(). Event.Changed is set when the database was changed, and the
+	// page cache was cleared.
+	EventHeaderReload
+	// EventSchemaReload is the sqlite_master table being read again,
+	// because the schema changed or wasn't read yet.
+	EventSchemaReload
+	// EventCall is the summary of a high level call, such as a select. See
+	// Event.Call.
+	EventCall
+	// EventReopen is the file being opened again, because it was replaced.
+	// See SetReopen().
+	EventReopen
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventPageRead:
+		return "page read"
+	case EventOverflowRead:
+		return "overflow read"
+	case EventDescend:
+		return "descend"
+	case EventLock:
+		return "lock"
+	case EventUnlock:
+		return "unlock"
+	case EventHeaderReload:
+		return "header reload"
+	case EventSchemaReload:
+		return "schema reload"
+	case EventCall:
+		return "call"
+	case EventReopen:
+		return "reopen"
+	default:
+		return "unknown"
+	}
+}
+
+// Event is what an Observer gets. Which fields are set depends on the Type.
+type Event struct {
+	Type     EventType
+	Page     int           // page number for page reads and descents
+	Cached   bool          // EventPageRead: the page was in the cache
+	Changed  bool          // EventHeaderReload: the database has changed
+	Duration time.Duration // EventLock, EventUnlock, and EventCall
+	Err      error         // EventLock and EventCall
+	Call     *CallSummary  // EventCall
+}
+
+// CallSummary describes a single high level call, such as
+// sqlittle.DB.Select().
+type CallSummary struct {
+	Method        string // "Select", "IndexedSelect", ...
+	Table         string
+	Index         string // if an index was asked for
+	PagesRead     int    // btree pages read from the file
+	PagesCached   int    // btree pages found in the cache
+	OverflowPages int    // overflow pages read
+	RowsExamined  int    // rows (or index records) looked at
+	Rows          int    // rows given to the callback
+}
+
+// SetObserver sets the observer which gets all events. nil disables it.
+// Set it before the database is used.
+func (db *Database) SetObserver(o Observer) {
+	db.observer = o
+}
+
+// WithObserver gives a copy of the database which sends its events to o. It's
+// the same as WithContext(), but for the observer.
+func (db *Database) WithObserver(o Observer) *Database {
+	c := *db
+	c.observer = o
+	return &c
+}
+
+// Observer gives the observer, or nil.
+func (db *Database) Observer() Observer {
+	return db.observer
+}
+
+func (db *Database) observe(e Event) {
+	if db.observer != nil {
+		db.observer.Observe(e)
+	}
+}
+
+// Counters is an Observer which adds up all events. It's safe to use from
+// multiple goroutines; use Snapshot() to read the numbers.
+//
+//	c := &Counters{}
+//	db.SetObserver(c)
+//	...
+//	fmt.Printf("%+v\n", c.Snapshot())
+type Counters struct {
+	PagesRead     int64
+	PagesCached   int64
+	OverflowReads int64
+	Descents      int64
+	Locks         int64
+	LockWait      time.Duration
+	LockHeld      time.Duration
+	HeaderReloads int64
+	CacheClears   int64
+	SchemaReloads int64
+	Reopens       int64
+	Calls         int64
+	RowsExamined  int64
+	Rows          int64
+	Errors        int64 // failed locks and calls
+}
+
+var _ Observer = &Counters{}
+
+// Observe implements Observer.
+func (c *Counters) Observe(e Event) {
+	switch e.Type {
+	case EventPageRead:
+		if e.Cached {
+			atomic.AddInt64(&c.PagesCached, 1)
+		} else {
+			atomic.AddInt64(&c.PagesRead, 1)
+		}
+	case EventOverflowRead:
+		atomic.AddInt64(&c.OverflowReads, 1)
+	case EventDescend:
+		atomic.AddInt64(&c.Descents, 1)
+	case EventLock:
+		atomic.AddInt64(&c.Locks, 1)
+		atomic.AddInt64((*int64)(&c.LockWait), int64(e.Duration))
+	case EventUnlock:
+		atomic.AddInt64((*int64)(&c.LockHeld), int64(e.Duration))
+	case EventHeaderReload:
+		atomic.AddInt64(&c.HeaderReloads, 1)
+		if e.Changed {
+			atomic.AddInt64(&c.CacheClears, 1)
+		}
+	case EventSchemaReload:
+		atomic.AddInt64(&c.SchemaReloads, 1)
+	case EventReopen:
+		atomic.AddInt64(&c.Reopens, 1)
+	case EventCall:
+		atomic.AddInt64(&c.Calls, 1)
+		if e.Call != nil {
+			atomic.AddInt64(&c.RowsExamined, int64(e.Call.RowsExamined))
+			atomic.AddInt64(&c.Rows, int64(e.Call.Rows))
+		}
+	}
+	if e.Err != nil {
+		atomic.AddInt64(&c.Errors, 1)
+	}
+}
+
+// Snapshot gives a copy of the current numbers.
+func (c *Counters) Snapshot() Counters {
+	return Counters{
+		PagesRead:     atomic.LoadInt64(&c.PagesRead),
+		PagesCached:   atomic.LoadInt64(&c.PagesCached),
+		OverflowReads: atomic.LoadInt64(&c.OverflowReads),
+		Descents:      atomic.LoadInt64(&c.Descents),
+		Locks:         atomic.LoadInt64(&c.Locks),
+		LockWait:      time.Duration(atomic.LoadInt64((*int64)(&c.LockWait))),
+		LockHeld:      time.Duration(atomic.LoadInt64((*int64)(&c.LockHeld))),
+		HeaderReloads: atomic.LoadInt64(&c.HeaderReloads),
+		CacheClears:   atomic.LoadInt64(&c.CacheClears),
+		SchemaReloads: atomic.LoadInt64(&c.SchemaReloads),
+		Reopens:       atomic.LoadInt64(&c.Reopens),
+		Calls:         atomic.LoadInt64(&c.Calls),
+		RowsExamined:  atomic.LoadInt64(&c.RowsExamined),
+		Rows:          atomic.LoadInt64(&c.Rows),
+		Errors:        atomic.LoadInt64(&c.Errors),
+	}
+}
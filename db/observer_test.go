@@ -0,0 +1,86 @@
+package db
+
+import (
+	"testing"
+)
+
+func TestObserver(t *testing.T) {
+	db, err := OpenFile("./../testdata/words.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	c := &Counters{}
+	db.SetObserver(c)
+
+	scan := func() {
+		if err := db.RLock(); err != nil {
+			t.Fatal(err)
+		}
+		defer db.RUnlock()
+		table, err := db.Table("words")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := table.Scan(func(int64, Record) bool { return false }); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	scan()
+	first := c.Snapshot()
+	if have, want := first.Locks, int64(1); have != want {
+		t.Errorf("locks: have %d, want %d", have, want)
+	}
+	if have, want := first.Descents, int64(1); have != want {
+		t.Errorf("descents: have %d, want %d", have, want)
+	}
+	if have, want := first.HeaderReloads, int64(1); have != want {
+		t.Errorf("header reloads: have %d, want %d", have, want)
+	}
+	if have, want := first.CacheClears, int64(0); have != want {
+		t.Errorf("cache clears: have %d, want %d", have, want)
+	}
+	if have, want := first.SchemaReloads, int64(1); have != want {
+		t.Errorf("schema reloads: have %d, want %d", have, want)
+	}
+	if first.PagesRead < 2 {
+		t.Errorf("pages read: %d", first.PagesRead)
+	}
+
+	// everything is cached now
+	scan()
+	second := c.Snapshot()
+	if have, want := second.PagesRead, first.PagesRead; have != want {
+		t.Errorf("pages read: have %d, want %d", have, want)
+	}
+	if second.PagesCached <= first.PagesCached {
+		t.Errorf("pages cached: %d", second.PagesCached)
+	}
+	if have, want := second.SchemaReloads, int64(1); have != want {
+		t.Errorf("schema reloads: have %d, want %d", have, want)
+	}
+
+	// copies share the observer
+	db.SetObserver(nil)
+	var types []EventType
+	obs := observerFunc(func(e Event) { types = append(types, e.Type) })
+	table, err := db.WithObserver(obs).Table("words")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := table.Rowid(1); err != nil {
+		t.Fatal(err)
+	}
+	if len(types) < 2 || types[0] != EventDescend || types[1] != EventPageRead {
+		t.Errorf("events: %v", types)
+	}
+	if have, want := c.Snapshot(), second; have != want {
+		t.Errorf("have %+v, want %+v", have, want)
+	}
+}
+
+type observerFunc func(Event)
+
+func (f observerFunc) Observe(e Event) { f(e) }
@@ -12,3 +12,11 @@ type pager interface {
 	// true if there is any 'RESERVED' lock on this file
 	CheckReservedLock() (bool, error)
 }
+
+// reopener is a pager for a file which can be replaced by a new file.
+type reopener interface {
+	// true if the path points to a different file now
+	replaced() (bool, error)
+	// open the path again. No lock is held.
+	reopen() error
+}
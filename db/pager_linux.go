@@ -5,9 +5,10 @@ package db
 
 import (
 	"errors"
+	"io"
 	"os"
+	"sync"
 
-	"golang.org/x/exp/mmap"
 	"golang.org/x/sys/unix"
 )
 
@@ -21,8 +22,11 @@ const (
 
 type filePager struct {
 	f        *os.File
+	lmu      sync.Mutex // for readLock and readers
 	readLock *unix.Flock_t
-	mm       *mmap.ReaderAt
+	readers  int          // number of RLock()s; the file lock is shared by all
+	mu       sync.RWMutex // for mm
+	mm       []byte       // the mapped file, nil if it's empty
 }
 
 func newFilePager(file string) (*filePager, error) {
@@ -30,7 +34,7 @@ func newFilePager(file string) (*filePager, error) {
 	if err != nil {
 		return nil, err
 	}
-	mm, err := mmap.Open(file)
+	mm, err := mapFile(f)
 	if err != nil {
 		f.Close()
 		return nil, err
@@ -41,24 +45,130 @@ func newFilePager(file string) (*filePager, error) {
 	}, nil
 }
 
+// mapFile maps the open file. It maps the file descriptor, not the path, so
+// it's always the file the lock is on, even when another file was renamed
+// over the path.
+func mapFile(f *os.File) ([]byte, error) {
+	st, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := st.Size()
+	if size == 0 {
+		return nil, nil
+	}
+	if int64(int(size)) != size {
+		return nil, errors.New("file too large")
+	}
+	return unix.Mmap(int(f.Fd()), 0, int(size), unix.PROT_READ, unix.MAP_SHARED)
+}
+
+func unmap(mm []byte) error {
+	if mm == nil {
+		return nil
+	}
+	return unix.Munmap(mm)
+}
+
 // pages start counting at 1
 func (f *filePager) page(id int, pagesize int) ([]byte, error) {
 	buf := make([]byte, pagesize)
-	_, err := f.mm.ReadAt(buf[:], int64(id-1)*int64(pagesize))
-	return buf, err
+	off := int64(id-1) * int64(pagesize)
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if f.mm == nil {
+		// the same error golang.org/x/exp/mmap gives for an empty file
+		return buf, errors.New("mmap: closed")
+	}
+	if off < 0 || off >= int64(len(f.mm)) {
+		return buf, io.EOF
+	}
+	if n := copy(buf, f.mm[off:]); n < len(buf) {
+		return buf, io.EOF
+	}
+	return buf, nil
+}
+
+// replaced is true when the path now points to a different file than the open
+// one, because a new file was renamed over it.
+func (f *filePager) replaced() (bool, error) {
+	f.mu.RLock()
+	file := f.f
+	f.mu.RUnlock()
+	st, err := os.Stat(file.Name())
+	if err != nil {
+		if os.IsNotExist(err) {
+			// keep reading the old file
+			return false, nil
+		}
+		return false, err
+	}
+	cur, err := file.Stat()
+	if err != nil {
+		return false, err
+	}
+	return !os.SameFile(st, cur), nil
+}
+
+// reopen opens the path again, and closes the old file. Don't hold a lock.
+func (f *filePager) reopen() error {
+	n, err := newFilePager(f.f.Name())
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	oldF, oldMM := f.f, f.mm
+	f.f, f.mm = n.f, n.mm
+	f.mu.Unlock()
+	oldF.Close()
+	return unmap(oldMM)
+}
+
+// remap maps the file again when its size changed since it was mapped, so
+// pages appended by other processes can be read.
+func (f *filePager) remap() error {
+	st, err := f.f.Stat()
+	if err != nil {
+		return err
+	}
+	f.mu.RLock()
+	same := st.Size() == int64(len(f.mm))
+	f.mu.RUnlock()
+	if same {
+		return nil
+	}
+	mm, err := mapFile(f.f)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	old := f.mm
+	f.mm = mm
+	f.mu.Unlock()
+	return unmap(old)
 }
 
 func (f *filePager) lock(flock *unix.Flock_t) error {
-	return unix.FcntlFlock(f.f.Fd(), unix.F_SETLK, flock)
+	err := unix.FcntlFlock(f.f.Fd(), unix.F_SETLK, flock)
+	if err == unix.EAGAIN || err == unix.EACCES {
+		// someone else has a conflicting lock
+		return ErrLocked
+	}
+	return err
 }
 
+// RLock read locks the file. Locks are per process, so the file is only
+// locked by the first RLock(), and later ones only count.
 func (f *filePager) RLock() error {
-	// Set a 'SHARED' lock, following unixLock() logic from sqlite3.c
-
-	if f.readLock != nil {
-		return errors.New("trying to lock a locked lock") // panic?
+	f.lmu.Lock()
+	defer f.lmu.Unlock()
+	if f.readers > 0 {
+		f.readers++
+		return nil
 	}
 
+	// Set a 'SHARED' lock, following unixLock() logic from sqlite3.c
+
 	// - get PENDING lock
 	pending := &unix.Flock_t{
 		Type:   unix.F_RDLCK,
@@ -86,14 +196,26 @@ func (f *filePager) RLock() error {
 	if err := f.lock(read); err != nil {
 		return err
 	}
+	if err := f.remap(); err != nil {
+		read.Type = unix.F_UNLCK
+		f.lock(read)
+		return err
+	}
 	f.readLock = read
+	f.readers = 1
 	return nil
 }
 
 func (f *filePager) RUnlock() error {
-	if f.readLock == nil {
+	f.lmu.Lock()
+	defer f.lmu.Unlock()
+	if f.readers == 0 {
 		return errors.New("trying to unlock an unlocked lock") // panic?
 	}
+	f.readers--
+	if f.readers > 0 {
+		return nil
+	}
 	f.readLock.Type = unix.F_UNLCK
 	f.lock(f.readLock)
 	f.readLock = nil
@@ -115,5 +237,5 @@ func (f *filePager) CheckReservedLock() (bool, error) {
 
 func (f *filePager) Close() error {
 	f.f.Close()
-	return f.mm.Close()
+	return unmap(f.mm)
 }
@@ -7,6 +7,7 @@ import (
 	// "errors"
 	"golang.org/x/exp/mmap"
 	"os"
+	"sync"
 	//	"golang.org/x/sys/unix"
 )
 
@@ -21,6 +22,7 @@ const (
 type filePager struct {
 	f *os.File
 	//	readLock *unix.Flock_t
+	mu sync.RWMutex // for mm
 	mm *mmap.ReaderAt
 }
 
@@ -43,10 +45,77 @@ func newFilePager(file string) (*filePager, error) {
 // pages start counting at 1
 func (f *filePager) page(id int, pagesize int) ([]byte, error) {
 	buf := make([]byte, pagesize)
+	f.mu.RLock()
 	_, err := f.mm.ReadAt(buf[:], int64(id-1)*int64(pagesize))
+	f.mu.RUnlock()
 	return buf, err
 }
 
+// replaced is true when the path now points to a different file than the open
+// one, because a new file was renamed over it.
+func (f *filePager) replaced() (bool, error) {
+	f.mu.RLock()
+	file := f.f
+	f.mu.RUnlock()
+	st, err := os.Stat(file.Name())
+	if err != nil {
+		if os.IsNotExist(err) {
+			// keep reading the old file
+			return false, nil
+		}
+		return false, err
+	}
+	cur, err := file.Stat()
+	if err != nil {
+		return false, err
+	}
+	return !os.SameFile(st, cur), nil
+}
+
+// reopen opens the path again, and closes the old file. Don't hold a lock.
+func (f *filePager) reopen() error {
+	n, err := newFilePager(f.f.Name())
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	oldF, oldMM := f.f, f.mm
+	f.f, f.mm = n.f, n.mm
+	f.mu.Unlock()
+	oldF.Close()
+	return oldMM.Close()
+}
+
+// remap maps the file again when its size changed since it was mapped, so
+// pages appended by other processes can be read.
+func (f *filePager) remap() error {
+	st, err := f.f.Stat()
+	if err != nil {
+		return err
+	}
+	f.mu.RLock()
+	same := st.Size() == int64(f.mm.Len())
+	f.mu.RUnlock()
+	if same {
+		return nil
+	}
+	// mmap.Open() maps the path, which must still be the open file, or reads
+	// would come from another file than the one which is locked. Keep the old
+	// mapping when the file was replaced or removed.
+	if pst, err := os.Stat(f.f.Name()); err != nil || !os.SameFile(pst, st) {
+		return nil
+	}
+	mm, err := mmap.Open(f.f.Name())
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	old := f.mm
+	f.mm = mm
+	f.mu.Unlock()
+	return old.Close()
+}
+
 /*
 func (f *filePager) lock(flock *unix.Flock_t) error {
 		return unix.FcntlFlock(f.f.Fd(), unix.F_SETLK, flock)
@@ -90,7 +159,7 @@ func (f *filePager) RLock() error {
 		}
 		f.readLock = read
 	*/
-	return nil
+	return f.remap()
 }
 
 func (f *filePager) RUnlock() error {
@@ -0,0 +1,229 @@
+// parallel scans
+
+package db
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// partitions per worker, so workers which finish early can take over some
+// work
+const partitionsPerWorker = 4
+
+// ParallelScan calls cb() for every row in the table, like Scan(), but it
+// splits the table in parts which are scanned by workers goroutines. workers
+// < 1 means runtime.GOMAXPROCS(0).
+//
+// The parts are the pages below the interior pages of the btree, so every part
+// has its own range of rowids. The callback is called from different
+// goroutines at the same time, but all rows of a part are given by a single
+// goroutine, in rowid order. There is no order between parts.
+//
+// If the callback returns true (done) all workers stop. ParallelScan returns
+// when all workers are done.
+func (t *Table) ParallelScan(workers int, cb TableScanCB) error {
+	root, err := t.db.openRoot(t.root)
+	if err != nil {
+		return err
+	}
+	workers = parallelWorkers(workers)
+
+	// go down the interior pages until there are enough parts. All leaf
+	// pages have the same depth.
+	depth := 0
+	parts := []tableBtree{root}
+	for len(parts) < workers*partitionsPerWorker {
+		var next []tableBtree
+		for _, p := range parts {
+			in, ok := p.(*tableInterior)
+			if !ok {
+				break
+			}
+			if _, err := in.cellIter(t.db, func(pageID int) (bool, error) {
+				page, err := t.db.openTable(pageID)
+				if err != nil {
+					return false, err
+				}
+				next = append(next, page)
+				return false, nil
+			}); err != nil {
+				return err
+			}
+		}
+		if len(next) == 0 {
+			break
+		}
+		parts = next
+		depth++
+	}
+
+	var stop int32
+	return parallel(workers, len(parts), func(i int) (bool, error) {
+		// the part's page is already open, so check the context here
+		if err := t.db.ctxErr(); err != nil {
+			return false, err
+		}
+		return parts[i].Iter(
+			maxRecursion-depth,
+			t.db,
+			func(rowid int64, pl cellPayload) (bool, error) {
+				if atomic.LoadInt32(&stop) != 0 {
+					return true, nil
+				}
+				c, err := addOverflow(t.db, pl)
+				if err != nil {
+					return false, err
+				}
+				rec, err := parseRecord(c)
+				if err != nil {
+					return false, err
+				}
+				if cb(rowid, rec) {
+					atomic.StoreInt32(&stop, 1)
+					return true, nil
+				}
+				return false, nil
+			},
+		)
+	})
+}
+
+// ParallelScanContext is ParallelScan(), but all workers stop with ctx.Err()
+// when the context is done. The context is checked before every page.
+func (t *Table) ParallelScanContext(ctx context.Context, workers int, cb TableScanCB) error {
+	return t.withContext(ctx).ParallelScan(workers, cb)
+}
+
+// ParallelScan calls cb() for every record in the index, like Scan(), with
+// workers goroutines. Same as Table.ParallelScan(), but the parts are ranges of
+// index values. The records stored in the interior pages are parts on their
+// own.
+func (in *Index) ParallelScan(workers int, cb RecordCB) error {
+	root, err := in.db.openIndexRoot(in.root)
+	if err != nil {
+		return err
+	}
+	workers = parallelWorkers(workers)
+
+	// a part is either a page, or a single record from an interior page
+	type part struct {
+		page indexBtree
+		rec  *cellPayload
+	}
+	depth := 0
+	parts := []part{{page: root}}
+	for len(parts) < workers*partitionsPerWorker {
+		var next []part
+		for _, p := range parts {
+			if p.page == nil {
+				next = append(next, p)
+				continue
+			}
+			interior, ok := p.page.(*indexInterior)
+			if !ok {
+				next = nil
+				break
+			}
+			for i := range interior.cells {
+				c := &interior.cells[i]
+				page, err := in.db.openIndex(c.left)
+				if err != nil {
+					return err
+				}
+				next = append(next, part{page: page}, part{rec: &c.payload})
+			}
+			page, err := in.db.openIndex(interior.rightmost)
+			if err != nil {
+				return err
+			}
+			next = append(next, part{page: page})
+		}
+		if next == nil {
+			break
+		}
+		parts = next
+		depth++
+	}
+
+	var stop int32
+	recordCB := func(rec Record) (bool, error) {
+		if atomic.LoadInt32(&stop) != 0 {
+			return true, nil
+		}
+		if cb(rec) {
+			atomic.StoreInt32(&stop, 1)
+			return true, nil
+		}
+		return false, nil
+	}
+	return parallel(workers, len(parts), func(i int) (bool, error) {
+		if err := in.db.ctxErr(); err != nil {
+			return false, err
+		}
+		p := parts[i]
+		if p.page != nil {
+			return p.page.Iter(maxRecursion-depth, in.db, recordCB)
+		}
+		full, err := addOverflow(in.db, *p.rec)
+		if err != nil {
+			return false, err
+		}
+		rec, err := parseRecord(full)
+		if err != nil {
+			return false, err
+		}
+		return recordCB(rec)
+	})
+}
+
+// ParallelScanContext is ParallelScan(), but all workers stop with ctx.Err()
+// when the context is done. The context is checked before every page.
+func (in *Index) ParallelScanContext(ctx context.Context, workers int, cb RecordCB) error {
+	return in.withContext(ctx).ParallelScan(workers, cb)
+}
+
+func parallelWorkers(workers int) int {
+	if workers < 1 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return workers
+}
+
+// parallel calls scan for every part, from workers goroutines. It stops
+// handing out parts after the first error, or when a scan is done.
+func parallel(workers, parts int, scan func(int) (bool, error)) error {
+	if workers > parts {
+		workers = parts
+	}
+	var (
+		next     int64 = -1
+		done     int32
+		firstErr error
+		errOnce  sync.Once
+		wg       sync.WaitGroup
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for atomic.LoadInt32(&done) == 0 {
+				i := int(atomic.AddInt64(&next, 1))
+				if i >= parts {
+					return
+				}
+				stop, err := scan(i)
+				if err != nil {
+					errOnce.Do(func() { firstErr = err })
+				}
+				if stop || err != nil {
+					atomic.StoreInt32(&done, 1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
@@ -21,6 +21,10 @@ func addOverflow(db *Database, pl cellPayload) ([]byte, error) {
 		if overflow == 0 {
 			return to[:pl.Length], nil
 		}
+		if err := db.ctxErr(); err != nil {
+			return nil, err
+		}
+		db.observe(Event{Type: EventOverflowRead, Page: overflow})
 		buf, err := db.page(overflow)
 		if err != nil {
 			return nil, err
@@ -37,8 +37,19 @@ type TableColumn struct {
 type SchemaIndex struct {
 	Index   string
 	Columns []IndexColumn
+	Unique  bool
+	Where   sql.Expression // only set for partial indexes
+	Origin  string         // one of the Origin... constants
 }
 
+// Where an index comes from. These are the same values as `PRAGMA index_list`
+// uses.
+const (
+	OriginCreateIndex = "c"  // a `CREATE INDEX` statement
+	OriginUnique      = "u"  // a UNIQUE constraint in the `CREATE TABLE`
+	OriginPrimaryKey  = "pk" // a PRIMARY KEY in the `CREATE TABLE`
+)
+
 type IndexColumn struct {
 	Column     string
 	Expression string
@@ -193,6 +204,9 @@ func (st *Schema) addCreateIndex(ci sql.CreateIndexStmt) {
 	st.Indexes = append(st.Indexes, SchemaIndex{
 		Index:   ci.Index,
 		Columns: st.toIndexColumns(ci.IndexedColumns),
+		Unique:  ci.Unique,
+		Where:   ci.Where,
+		Origin:  OriginCreateIndex,
 	})
 }
 
@@ -228,17 +242,25 @@ func (st *Schema) addIndex(pk bool, name string, cols []IndexColumn) bool {
 	if reflect.DeepEqual(st.PK, cols) {
 		return false
 	}
-	for _, ind := range st.Indexes {
+	for i, ind := range st.Indexes {
 		if reflect.DeepEqual(ind.Columns, cols) {
 			if pk {
+				// SQLite reports the shared index as the primary key
 				st.PrimaryKey = ind.Index
+				st.Indexes[i].Origin = OriginPrimaryKey
 			}
 			return false
 		}
 	}
+	origin := OriginUnique
+	if pk {
+		origin = OriginPrimaryKey
+	}
 	st.Indexes = append(st.Indexes, SchemaIndex{
 		Index:   name,
 		Columns: cols,
+		Unique:  true,
+		Origin:  origin,
 	})
 	if pk {
 		st.PrimaryKey = name
@@ -99,6 +99,35 @@ func TestSchemaConstrPK(t *testing.T) {
 				{
 					Index:   "sqlite_autoindex_foo_1",
 					Columns: []IndexColumn{{Column: "a"}},
+					Unique:  true,
+					Origin:  OriginPrimaryKey,
+				},
+			},
+		},
+		nil,
+	)
+}
+
+func TestSchemaUniquePK(t *testing.T) {
+	testSchema(
+		t,
+		"foo4",
+		[]sqliteMaster{
+			{"table", "foo4", "foo4", 42, "create table foo4 (a unique, b, primary key(a))"},
+		},
+		&Schema{
+			Table:      "foo4",
+			PrimaryKey: "sqlite_autoindex_foo4_1",
+			Columns: []TableColumn{
+				{Column: "a", Null: true},
+				{Column: "b", Null: true},
+			},
+			Indexes: []SchemaIndex{
+				{
+					Index:   "sqlite_autoindex_foo4_1",
+					Columns: []IndexColumn{{Column: "a"}},
+					Unique:  true,
+					Origin:  OriginPrimaryKey,
 				},
 			},
 		},
@@ -126,14 +155,20 @@ func TestSchemaUnique(t *testing.T) {
 				{
 					Index:   "sqlite_autoindex_foo3_1",
 					Columns: []IndexColumn{{Column: "a"}},
+					Unique:  true,
+					Origin:  OriginUnique,
 				},
 				{
 					Index:   "sqlite_autoindex_foo3_2",
 					Columns: []IndexColumn{{Column: "b"}},
+					Unique:  true,
+					Origin:  OriginPrimaryKey,
 				},
 				{
 					Index:   "sqlite_autoindex_foo3_3",
 					Columns: []IndexColumn{{Column: "c"}},
+					Unique:  true,
+					Origin:  OriginUnique,
 				},
 			},
 		},
@@ -161,12 +196,16 @@ func TestSchemaRowid(t *testing.T) {
 				{
 					Index:   "sqlite_autoindex_foo_1",
 					Columns: []IndexColumn{{Column: "b"}},
+					Unique:  true,
+					Origin:  OriginUnique,
 				},
 				{
 					Index: "sqlite_autoindex_foo_2",
 					Columns: []IndexColumn{
 						{Column: "b", Collate: "rtrim", SortOrder: sql.Desc},
 					},
+					Unique: true,
+					Origin: OriginUnique,
 				},
 			},
 		},
@@ -210,6 +249,8 @@ func TestSchemaWithoutRowid(t *testing.T) {
 				{
 					Index:   "sqlite_autoindex_foo4_2", // _1 is reserved
 					Columns: []IndexColumn{{Column: "b"}},
+					Unique:  true,
+					Origin:  OriginUnique,
 				},
 			},
 			PK: []IndexColumn{{Column: "a"}},
@@ -277,14 +318,18 @@ func TestSchemaIndex(t *testing.T) {
 				{
 					Index:   "sqlite_autoindex_foo9_1",
 					Columns: []IndexColumn{{Column: "c"}, {Column: "b"}},
+					Unique:  true,
+					Origin:  OriginUnique,
 				},
 				{
 					Index:   "fooi",
 					Columns: []IndexColumn{{Column: "c"}, {Column: "b"}},
+					Origin:  OriginCreateIndex,
 				},
 				{
 					Index:   "fooi2",
 					Columns: []IndexColumn{{Column: "c"}, {Column: "b"}},
+					Origin:  OriginCreateIndex,
 				},
 			},
 		},
@@ -313,10 +358,12 @@ func TestSchemaIndexNonRowid(t *testing.T) {
 				{
 					Index:   "fooi",
 					Columns: []IndexColumn{{Column: "b"}, {Column: "c"}},
+					Origin:  OriginCreateIndex,
 				},
 				{
 					Index:   "fooj",
 					Columns: []IndexColumn{{Column: "a"}, {Column: "c"}},
+					Origin:  OriginCreateIndex,
 				},
 			},
 			PK: []IndexColumn{{Column: "c"}, {Column: "b"}},
@@ -345,6 +392,8 @@ func TestSchemaUnique2(t *testing.T) {
 				{
 					Index:   "sqlite_autoindex_foo_2",
 					Columns: []IndexColumn{{Column: "a"}, {Column: "c"}},
+					Unique:  true,
+					Origin:  OriginUnique,
 				},
 			},
 			PK: []IndexColumn{{Column: "a"}, {Column: "c"}, {Column: "b"}},
@@ -371,6 +420,35 @@ func TestSchemaCollate(t *testing.T) {
 				{
 					Index:   "fooi2",
 					Columns: []IndexColumn{{Column: "a", Collate: "rtrim"}},
+					Origin:  OriginCreateIndex,
+				},
+			},
+		},
+		nil,
+	)
+}
+
+func TestSchemaPartialIndex(t *testing.T) {
+	testSchema(
+		t,
+		"foo",
+		[]sqliteMaster{
+			{"table", "foo", "foo", 42, `CREATE TABLE foo (a, b)`},
+			{"index", "foo_a", "foo", 42, `CREATE UNIQUE INDEX foo_a ON foo (a) WHERE b > 10`},
+		},
+		&Schema{
+			Table: "foo",
+			Columns: []TableColumn{
+				{Column: "a", Null: true},
+				{Column: "b", Null: true},
+			},
+			Indexes: []SchemaIndex{
+				{
+					Index:   "foo_a",
+					Columns: []IndexColumn{{Column: "a"}},
+					Unique:  true,
+					Where:   sql.ExBinaryOp{Op: ">", Left: sql.ExColumn("b"), Right: int64(10)},
+					Origin:  OriginCreateIndex,
 				},
 			},
 		},
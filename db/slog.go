@@ -0,0 +1,56 @@
+package db
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SlogObserver is an Observer which logs every event to a log/slog logger,
+// with the given level. Page reads are very frequent, so use a level which is
+// normally disabled, such as slog.LevelDebug, or the Counters observer.
+func SlogObserver(logger *slog.Logger, level slog.Level) Observer {
+	return &slogObserver{logger: logger, level: level}
+}
+
+type slogObserver struct {
+	logger *slog.Logger
+	level  slog.Level
+}
+
+func (o *slogObserver) Observe(e Event) {
+	ctx := context.Background()
+	if !o.logger.Enabled(ctx, o.level) {
+		return
+	}
+	attrs := []slog.Attr{}
+	switch e.Type {
+	case EventPageRead:
+		attrs = append(attrs, slog.Int("page", e.Page), slog.Bool("cached", e.Cached))
+	case EventOverflowRead, EventDescend:
+		attrs = append(attrs, slog.Int("page", e.Page))
+	case EventLock, EventUnlock:
+		attrs = append(attrs, slog.Duration("duration", e.Duration))
+	case EventHeaderReload:
+		attrs = append(attrs, slog.Bool("changed", e.Changed))
+	case EventCall:
+		attrs = append(attrs, slog.Duration("duration", e.Duration))
+		if c := e.Call; c != nil {
+			attrs = append(attrs,
+				slog.String("method", c.Method),
+				slog.String("table", c.Table),
+				slog.Int("pages_read", c.PagesRead),
+				slog.Int("pages_cached", c.PagesCached),
+				slog.Int("overflow_pages", c.OverflowPages),
+				slog.Int("rows_examined", c.RowsExamined),
+				slog.Int("rows", c.Rows),
+			)
+			if c.Index != "" {
+				attrs = append(attrs, slog.String("index", c.Index))
+			}
+		}
+	}
+	if e.Err != nil {
+		attrs = append(attrs, slog.String("error", e.Err.Error()))
+	}
+	o.logger.LogAttrs(ctx, o.level, "sqlittle "+e.Type.String(), attrs...)
+}
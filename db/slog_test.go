@@ -0,0 +1,51 @@
+package db
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSlogObserver(t *testing.T) {
+	db, err := OpenFile("./../testdata/words.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var b bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&b, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	db.SetObserver(SlogObserver(logger, slog.LevelDebug))
+	if err := db.RLock(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Table("words"); err != nil {
+		t.Fatal(err)
+	}
+	db.RUnlock()
+
+	out := b.String()
+	for _, want := range []string{
+		`msg="sqlittle lock"`,
+		`msg="sqlittle header reload" changed=false`,
+		`msg="sqlittle schema reload"`,
+		`msg="sqlittle page read" page=1 cached=`,
+		`msg="sqlittle unlock"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+
+	// disabled level
+	b.Reset()
+	db.SetObserver(SlogObserver(logger, slog.LevelDebug-1))
+	if err := db.RLock(); err != nil {
+		t.Fatal(err)
+	}
+	db.RUnlock()
+	if b.Len() != 0 {
+		t.Errorf("unexpected output: %s", b.String())
+	}
+}
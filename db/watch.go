@@ -0,0 +1,19 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+// longest time a Watcher waits before it checks its context
+const watchStep = 100 * time.Millisecond
+
+// sleep waits for d, or until the context is done.
+func sleep(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,88 @@
+// inotify implementation of the file Watcher
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// Watcher waits for writes to the database file. See Database.Watcher().
+type Watcher struct {
+	fd int // inotify fd, -1 if there is no file
+}
+
+// Watcher starts watching the database file for writes. On Linux this uses
+// inotify. Close() it when done.
+func (db *Database) Watcher() (*Watcher, error) {
+	if db.file == "" {
+		return &Watcher{fd: -1}, nil
+	}
+	fd, err := unix.InotifyInit1(unix.IN_NONBLOCK | unix.IN_CLOEXEC)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := unix.InotifyAddWatch(fd, db.file, unix.IN_MODIFY|unix.IN_CLOSE_WRITE); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+	return &Watcher{fd: fd}, nil
+}
+
+// Wait returns when the file was written to, or after the timeout. It returns
+// ctx.Err() when the context is done.
+func (w *Watcher) Wait(ctx context.Context, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		left := time.Until(deadline)
+		if left <= 0 {
+			return nil
+		}
+		if left > watchStep {
+			left = watchStep
+		}
+		if w.fd < 0 {
+			sleep(ctx, left)
+			continue
+		}
+		fds := []unix.PollFd{{Fd: int32(w.fd), Events: unix.POLLIN}}
+		n, err := unix.Poll(fds, int(left/time.Millisecond)+1)
+		if err == unix.EINTR {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			return w.drain()
+		}
+	}
+}
+
+// drain reads all pending inotify events. There is only a single watch, so the
+// content doesn't matter.
+func (w *Watcher) drain() error {
+	var buf [4096]byte
+	for {
+		_, err := unix.Read(w.fd, buf[:])
+		if err == unix.EAGAIN {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Close stops watching.
+func (w *Watcher) Close() error {
+	if w.fd < 0 {
+		return nil
+	}
+	return unix.Close(w.fd)
+}
@@ -0,0 +1,66 @@
+package db
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestWatcher(t *testing.T) {
+	b, err := ioutil.ReadFile("./../testdata/words.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := ioutil.TempFile("", "sqlittle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	if _, err := f.Write(b); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := OpenFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	w, err := db.Watcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		f.WriteAt([]byte{0, 0, 0, 42}, 24)
+	}()
+	start := time.Now()
+	if err := w.Wait(context.Background(), time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("inotify didn't fire: %s", d)
+	}
+	v, err := db.Version()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := v.ChangeCounter, uint32(42); have != want {
+		t.Errorf("have %d, want %d", have, want)
+	}
+
+	// timeout and cancel
+	if err := w.Wait(context.Background(), 10*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if have, want := w.Wait(ctx, time.Hour), context.Canceled; have != want {
+		t.Errorf("have %v, want %v", have, want)
+	}
+}
@@ -0,0 +1,32 @@
+//go:build !linux
+// +build !linux
+
+// polling implementation of the file Watcher
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+// Watcher waits for writes to the database file. See Database.Watcher().
+type Watcher struct{}
+
+// Watcher starts watching the database file for writes. On this platform
+// Wait() simply waits for the timeout. Close() it when done.
+func (db *Database) Watcher() (*Watcher, error) {
+	return &Watcher{}, nil
+}
+
+// Wait returns after the timeout. It returns ctx.Err() when the context is
+// done.
+func (w *Watcher) Wait(ctx context.Context, timeout time.Duration) error {
+	sleep(ctx, timeout)
+	return ctx.Err()
+}
+
+// Close stops watching.
+func (w *Watcher) Close() error {
+	return nil
+}
@@ -0,0 +1,136 @@
+package sqlittle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	sdb "github.com/hackborn/sqlittle/db"
+)
+
+// Distinct calls the callback for every distinct value of the first ncols
+// columns of an index, in index order. Same as:
+//
+//	SELECT DISTINCT col1, col2 FROM table
+//
+// where col1 and col2 are the first index columns. Values are compared with
+// the collate function of the index column. The values are given as stored
+// in the index.
+//
+// This doesn't read every record in the index: after every value it jumps to
+// the next distinct value. That's fast when there are few distinct values for
+// many rows.
+//
+// If the index has a WHERE expression only the values from rows matching that
+// expression are found.
+func (db *DB) Distinct(table, index string, ncols int, cb RowCB) error {
+	return db.DistinctContext(context.Background(), table, index, ncols, cb)
+}
+
+// DistinctContext is Distinct() with a context. See SelectContext().
+func (db *DB) DistinctContext(ctx context.Context, table, index string, ncols int, cb RowCB) (err error) {
+	d, c, err := db.rlock(ctx, "Distinct", table, index)
+	if err != nil {
+		return err
+	}
+	defer c.end(&err)
+	cb = c.count(cb)
+
+	s, err := d.Schema(table)
+	if err != nil {
+		return err
+	}
+	ind := s.NamedIndex(index)
+	if ind == nil {
+		return fmt.Errorf("no such index: %q", index)
+	}
+	if ncols < 1 || ncols > len(ind.Columns) {
+		return fmt.Errorf("invalid number of columns: %d", ncols)
+	}
+
+	in, err := d.Index(ind.Index)
+	if err != nil {
+		return err
+	}
+	return prefixes(in, ind.Columns, ncols, func(prefix sdb.Record) error {
+		cb(Row(prefix))
+		return nil
+	})
+}
+
+// prefixes calls cb with the first n values of the records in an index, once
+// for every distinct value, in index order. It uses ScanAfter() to jump from one
+// value to the next.
+func prefixes(in *sdb.Index, cols []sdb.IndexColumn, n int, cb func(sdb.Record) error) error {
+	var after sdb.Key
+	for {
+		var (
+			prefix sdb.Record
+			found  bool
+		)
+		first := func(r sdb.Record) bool {
+			if len(r) >= n {
+				prefix, found = sdb.Record(copyRow(Row(r[:n]))), true
+			}
+			return true
+		}
+		var err error
+		if after == nil {
+			err = in.Scan(first)
+		} else {
+			err = in.ScanAfter(after, first)
+		}
+		if err != nil {
+			return err
+		}
+		if !found {
+			return nil
+		}
+		if err := cb(prefix); err != nil {
+			return err
+		}
+		if after, err = asDbKey(Key(prefix), cols); err != nil {
+			return err
+		}
+	}
+}
+
+// skipScan finds the records where the columns after the first skip columns
+// equal the key, no matter what the values of the first columns are. For every
+// distinct value of the first columns it does a search with that value
+// followed by the key.
+func skipScan(in *sdb.Index, cols []sdb.IndexColumn, skip int, key Key, cb sdb.RecordCB) error {
+	if skip < 0 {
+		return errors.New("invalid SkipScan")
+	}
+	if skip+len(key) > len(cols) {
+		return errors.New("too many columns in Key")
+	}
+	// the callback returning true stops the whole scan, not just this search
+	done := false
+	stop := func(r sdb.Record) bool {
+		done = cb(r)
+		return done
+	}
+	err := prefixes(in, cols, skip, func(prefix sdb.Record) error {
+		k := append(Key(nil), prefix...)
+		dbkey, err := asDbKey(append(k, key...), cols)
+		if err != nil {
+			return err
+		}
+		if err := in.ScanEq(dbkey, stop); err != nil {
+			return err
+		}
+		if done {
+			return errStop
+		}
+		return nil
+	})
+	if err == errStop {
+		return nil
+	}
+	return err
+}
+
+// errStop ends prefixes() early.
+var errStop = errors.New("stop")
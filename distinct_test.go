@@ -0,0 +1,100 @@
+package sqlittle
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/andreyvit/diff"
+	"github.com/davecgh/go-spew/spew"
+)
+
+func TestDistinct(t *testing.T) {
+	db, err := Open("testdata/words.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var rows []Row
+	cb := func(r Row) {
+		rows = append(rows, r)
+	}
+	if err := db.Distinct("words", "words_index_2", 1, cb); err != nil {
+		t.Fatal(err)
+	}
+	var want []Row
+	for l := int64(2); l <= 18; l++ {
+		want = append(want, Row{l})
+	}
+	if have := rows; !reflect.DeepEqual(have, want) {
+		t.Errorf("diff:\n%s", diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+	}
+
+	rows = nil
+	if err := db.Distinct("words", "words_index_2", 2, cb); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := len(rows), 1000; have != want {
+		t.Errorf("have %d, want %d", have, want)
+	}
+	if have, want := rows[0], (Row{int64(2), "am"}); !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+
+	cb = func(r Row) {}
+	if have, want := db.Distinct("words", "words_index_2", 3, cb), errors.New("invalid number of columns: 3"); !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+	if have, want := db.Distinct("words", "nosuch", 1, cb), errors.New(`no such index: "nosuch"`); !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+}
+
+func TestSkipScan(t *testing.T) {
+	for _, file := range []string{
+		"testdata/words.sqlite",
+		"testdata/withoutrowid.sqlite",
+	} {
+		db, err := Open(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		index := "words_index_2"
+		if file == "testdata/withoutrowid.sqlite" {
+			index = "words_l"
+		}
+		var rows [][]string
+		cb := func(r Row) {
+			rows = append(rows, r.ScanStrings())
+		}
+		opts := Options{SkipScan: 1}
+		for _, w := range []string{"yard", "bloodthirstiness's", "nosuch"} {
+			if err := db.IndexedSelectEqWith("words", index, Key{w}, opts, cb, "word", "length"); err != nil {
+				t.Fatal(err)
+			}
+		}
+		want := [][]string{
+			{"yard", "4"},
+			{"bloodthirstiness's", "18"},
+		}
+		if have := rows; !reflect.DeepEqual(have, want) {
+			t.Errorf("%s diff:\n%s", file, diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+		}
+
+		// no key: every row
+		n := 0
+		if err := db.IndexedSelectEqWith("words", index, nil, opts, func(Row) { n++ }, "word"); err != nil {
+			t.Fatal(err)
+		}
+		if have, want := n, 1000; have != want {
+			t.Errorf("have %d, want %d", have, want)
+		}
+
+		if have, want := db.IndexedSelectEqWith("words", index, Key{"a", 1, 2}, opts, cb, "word"), errors.New("too many columns in Key"); !reflect.DeepEqual(have, want) {
+			t.Errorf("have %v, want %v", have, want)
+		}
+		db.Close()
+	}
+}
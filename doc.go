@@ -7,10 +7,13 @@ What
 SQLittle reads SQLite3 tables and indexes. It iterates over tables, and
 can search efficiently using indexes. SQLittle will deal with all SQLite
 storage quirks, but otherwise it doesn't try to be smart; if you want to use
-an index you have to give the name of the index.
+an index you have to give the name of the index, or use Where() and let a simple
+planner pick one.
 
-There is no support for SQL, and if you want to do the most efficient joins
-possible you'll have to use the low level code.
+There is no support for SQL, other than simple single table SELECTs with
+Query(), or through the read-only `database/sql` driver in the driver package.
+Join() does joins between two tables on equal columns, but if you want to do
+the most efficient joins possible you'll have to use the low level code.
 
 Based on https://sqlite.org/fileformat2.html and some SQLite source code reading.
 
@@ -44,12 +47,33 @@ Things SQLittle can do:
  - DESC indexes are handled automatically
  - Collate functions are used automatically
  - indexes with expression (either in columns or as a `WHERE`) are (partially) supported
- - Scan() to most Go datatypes, including `time.Time`
+ - index only scans when all requested columns are stored in the index
+ - find indexes by their columns, including the `sqlite_autoindex_...` indexes
+ - filter rows with SQLite comparison semantics: `=`, `!=`, `<`, `BETWEEN`, `IN`, `IS NULL`, `LIKE`, `GLOB`, `AND`, `OR`, `NOT`
+ - sort on any columns (`ORDER BY`), with temporary files for big results
+ - inner and left outer joins between two tables, using an index if there is one
+ - `COUNT`, `MIN`, `MAX`, `SUM`, and `AVG`, with or without `GROUP BY`
+ - `DISTINCT` values and skip-scans on index columns, without reading the whole index
+ - page through results with `Limit` and resume tokens, which survive changes to the database
+ - estimated row counts and random samples, without reading the whole table
+ - scan big tables in parallel, over multiple goroutines
+ - stop long scans with a `context.Context`
+ - observe page reads, locks, and per-call summaries, with counters or `log/slog`
+ - watch the file for changes to the data or the schema
+ - follow rows appended to a table, like `tail -f`
+ - reopen the file when it's replaced by a new one
+ - run simple single table `SELECT`s with `WHERE`, `ORDER BY`, and `LIMIT`
+ - `database/sql` driver for simple single table `SELECT`s
+ - scan rows into structs with ScanStruct() and SelectInto()
+ - rows with their column names and declared types, as a map or as JSON, with NamedRow
+ - range over rows or structs with iterators, such as `for t, err := range All[Track](db, "tracks", opts)`
+ - values get the column affinity, so `REAL` columns always give a `float64`
+ - keys and conditions are compared the way SQLite does, so `Eq("id", "42")` finds an `INTEGER` 42
+ - read all SQLite date and time formats, julian days, and unix timestamps as `time.Time`
+ - Scan() to most Go datatypes, including `time.Time`, the `sql.Null` types, and `sql.Scanner`
 
 Things SQLittle should do:
 
- - add a helper to find indexes. That would be especially useful for the `sqlite_autoindex_...` indexes
- - optimize loading when all requested columns are available in the index
  - expose the locking so you can do bigger read transactions
 
 
@@ -57,9 +81,7 @@ Things SQLittle can not do:
 
  - read-only
  - only supports UTF8 strings
- - no joins
  - WAL files are not supported
- - indexes are used for sorting, but there is no on-the-fly sorting
 
 
 Locks
@@ -72,7 +94,7 @@ file is opened in SQLittle.
 Status
 
 The current level of abstraction is likely the final one (that is: deal
-with reading single tables; don't even try joins or SQL or query planning), but
+with reading tables and simple joins; don't even try SQL), but
 the API might still change.
 
 
@@ -0,0 +1,235 @@
+// Package driver is a read-only database/sql driver for sqlittle. It
+// registers itself as "sqlittle":
+//
+//	import (
+//		"database/sql"
+//
+//		_ "github.com/hackborn/sqlittle/driver"
+//	)
+//
+//	db, err := sql.Open("sqlittle", "./music.sqlite")
+//	...
+//	rows, err := db.Query("SELECT name FROM tracks WHERE length > ? ORDER BY name LIMIT 10", 180)
+//
+// Only SELECT statements on a single table are supported:
+//
+//	SELECT * | column, ... FROM table
+//	    [WHERE expr]
+//	    [ORDER BY column [ASC|DESC], ...]
+//	    [LIMIT n [OFFSET m]]
+//
+// The WHERE expression is the one described at sqlittle.DB.Prepare(): column
+// comparisons, IS [NOT] NULL, [NOT] IN, [NOT] LIKE, and [NOT] GLOB, combined
+// with AND, OR, and NOT. Statements are run with
+// sqlittle.DB.Query(), so the best index for the WHERE is picked the same way
+// as sqlittle.DB.Where() does it. Statements which would change the database
+// fail with ErrReadOnly.
+//
+// All rows of a query are read into memory when the query is run, so there is
+// no lock held while the rows are used, but the whole result has to fit in
+// memory. Use a LIMIT, or sqlittle.DB.Select() and friends with a callback,
+// for results which might be big. Rows.ColumnTypes() gives the types as
+// declared in the CREATE TABLE.
+package driver
+
+import (
+	"context"
+	"database/sql"
+	sqldriver "database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/hackborn/sqlittle"
+)
+
+// ErrReadOnly is returned for every statement which would change the
+// database. It's sqlittle.ErrReadOnly.
+var ErrReadOnly = sqlittle.ErrReadOnly
+
+func init() {
+	sql.Register("sqlittle", &Driver{})
+}
+
+// Driver is the database/sql driver. The name to open is the filename of the
+// database.
+type Driver struct{}
+
+var (
+	_ sqldriver.Driver        = &Driver{}
+	_ sqldriver.DriverContext = &Driver{}
+)
+
+// Open implements database/sql/driver.Driver.
+func (d *Driver) Open(name string) (sqldriver.Conn, error) {
+	c, err := d.OpenConnector(name)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{c: c.(*connector), owner: true}, nil
+}
+
+// OpenConnector implements database/sql/driver.DriverContext. All connections
+// from the connector share a single sqlittle.DB.
+func (d *Driver) OpenConnector(name string) (sqldriver.Connector, error) {
+	db, err := sqlittle.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &connector{d: d, db: db}, nil
+}
+
+// connector runs a single query at a time on the database.
+type connector struct {
+	d  *Driver
+	mu sync.Mutex
+	db *sqlittle.DB
+}
+
+func (c *connector) Connect(context.Context) (sqldriver.Conn, error) {
+	return &conn{c: c}, nil
+}
+
+func (c *connector) Driver() sqldriver.Driver {
+	return c.d
+}
+
+// Close is called by sql.DB.Close().
+func (c *connector) Close() error {
+	return c.db.Close()
+}
+
+type conn struct {
+	c     *connector
+	owner bool // close the connector with the connection
+}
+
+func (c *conn) Prepare(query string) (sqldriver.Stmt, error) {
+	st, err := c.c.db.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	return &stmt{c: c.c, st: st}, nil
+}
+
+func (c *conn) Close() error {
+	if c.owner {
+		return c.c.Close()
+	}
+	return nil
+}
+
+// Begin gives a transaction which doesn't do anything, since nothing can be
+// written.
+func (c *conn) Begin() (sqldriver.Tx, error) {
+	return tx{}, nil
+}
+
+type tx struct{}
+
+func (tx) Commit() error   { return nil }
+func (tx) Rollback() error { return nil }
+
+type stmt struct {
+	c  *connector
+	st *sqlittle.Stmt
+}
+
+var _ sqldriver.StmtQueryContext = &stmt{}
+
+func (s *stmt) Close() error {
+	return nil
+}
+
+func (s *stmt) NumInput() int {
+	return s.st.NumInput()
+}
+
+func (s *stmt) Exec(args []sqldriver.Value) (sqldriver.Result, error) {
+	return nil, ErrReadOnly
+}
+
+func (s *stmt) Query(args []sqldriver.Value) (sqldriver.Rows, error) {
+	nv := make([]sqldriver.NamedValue, len(args))
+	for i, a := range args {
+		nv[i] = sqldriver.NamedValue{Ordinal: i + 1, Value: a}
+	}
+	return s.QueryContext(context.Background(), nv)
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []sqldriver.NamedValue) (sqldriver.Rows, error) {
+	vs := make([]interface{}, s.st.NumInput())
+	for _, a := range args {
+		if a.Name != "" {
+			return nil, fmt.Errorf("named parameters are not supported: %q", a.Name)
+		}
+		if a.Ordinal < 1 || a.Ordinal > len(vs) {
+			return nil, fmt.Errorf("invalid parameter number: %d", a.Ordinal)
+		}
+		vs[a.Ordinal-1] = a.Value
+	}
+
+	s.c.mu.Lock()
+	defer s.c.mu.Unlock()
+
+	res, err := s.st.QueryContext(ctx, vs...)
+	if err != nil {
+		return nil, err
+	}
+	return newRows(res), nil
+}
+
+// declType is the declared type without the size, in uppercase.
+func declType(t string) string {
+	if i := strings.IndexByte(t, '('); i >= 0 {
+		t = t[:i]
+	}
+	return strings.ToUpper(strings.TrimSpace(t))
+}
+
+type rows struct {
+	res   *sqlittle.Rows
+	types []string
+	nulls []bool
+}
+
+func newRows(res *sqlittle.Rows) *rows {
+	r := &rows{res: res}
+	for _, t := range res.ColumnTypes() {
+		r.types = append(r.types, declType(t.Type))
+		r.nulls = append(r.nulls, t.Null)
+	}
+	return r
+}
+
+var (
+	_ sqldriver.RowsColumnTypeDatabaseTypeName = &rows{}
+	_ sqldriver.RowsColumnTypeNullable         = &rows{}
+)
+
+func (r *rows) Columns() []string {
+	return r.res.Columns()
+}
+
+func (r *rows) Close() error {
+	return r.res.Close()
+}
+
+func (r *rows) Next(dest []sqldriver.Value) error {
+	if !r.res.Next() {
+		return io.EOF
+	}
+	for i, v := range r.res.Row() {
+		dest[i] = v
+	}
+	return nil
+}
+
+func (r *rows) ColumnTypeDatabaseTypeName(i int) string {
+	return r.types[i]
+}
+
+func (r *rows) ColumnTypeNullable(i int) (bool, bool) {
+	return r.nulls[i], true
+}
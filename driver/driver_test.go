@@ -0,0 +1,227 @@
+package driver
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/andreyvit/diff"
+	"github.com/davecgh/go-spew/spew"
+)
+
+func open(t *testing.T, file string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlittle", file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// all reads all rows as strings.
+func all(t *testing.T, rows *sql.Rows) [][]string {
+	t.Helper()
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res [][]string
+	for rows.Next() {
+		vs := make([]sql.NullString, len(cols))
+		ps := make([]interface{}, len(cols))
+		for i := range vs {
+			ps[i] = &vs[i]
+		}
+		if err := rows.Scan(ps...); err != nil {
+			t.Fatal(err)
+		}
+		row := make([]string, len(cols))
+		for i, v := range vs {
+			row[i] = v.String
+			if !v.Valid {
+				row[i] = "NULL"
+			}
+		}
+		res = append(res, row)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func TestQuery(t *testing.T) {
+	db := open(t, "./../testdata/music.sqlite")
+	defer db.Close()
+
+	type cas struct {
+		sql  string
+		args []interface{}
+		want [][]string
+	}
+	for _, c := range []cas{
+		{
+			sql: "SELECT name FROM tracks",
+			want: [][]string{
+				{"Drive My Car"},
+				{"Norwegian Wood"},
+				{"You Wont See Me"},
+				{"Come Together"},
+				{"Something"},
+				{"Maxwells Silver Hammer"},
+			},
+		},
+		{
+			sql:  "SELECT * FROM artists WHERE id = ?",
+			args: []interface{}{1},
+			want: [][]string{{"1", "The Beatles"}},
+		},
+		{
+			sql:  "SELECT name, length FROM tracks WHERE length > ? AND 200 >= length",
+			args: []interface{}{180},
+			want: [][]string{{"Something", "182"}, {"You Wont See Me", "198"}},
+		},
+		{
+			sql:  "SELECT name FROM tracks WHERE album = 2 ORDER BY name DESC LIMIT ? OFFSET 1",
+			args: []interface{}{1},
+			want: [][]string{{"Maxwells Silver Hammer"}},
+		},
+		{
+			sql:  "SELECT rowid, name FROM albums WHERE name >= 'Rubber Soul' LIMIT 10 OFFSET 0;",
+			want: [][]string{{"1", "Rubber Soul"}},
+		},
+		{
+			sql:  "SELECT name FROM tracks LIMIT 0",
+			want: nil,
+		},
+	} {
+		rows, err := db.Query(c.sql, c.args...)
+		if err != nil {
+			t.Fatalf("%s: %s", c.sql, err)
+		}
+		if have, want := all(t, rows), c.want; !reflect.DeepEqual(have, want) {
+			t.Errorf("%s diff:\n%s", c.sql, diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+		}
+	}
+}
+
+func TestQueryRow(t *testing.T) {
+	db := open(t, "./../testdata/music.sqlite")
+	defer db.Close()
+
+	var name string
+	if err := db.QueryRow("SELECT name FROM albums WHERE id = ?", 2).Scan(&name); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := name, "Abbey Road"; have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+
+	// prepared
+	st, err := db.Prepare("SELECT length FROM tracks WHERE id = ?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	for id, want := range map[int]int{1: 145, 4: 259} {
+		var l int
+		if err := st.QueryRow(id).Scan(&l); err != nil {
+			t.Fatal(err)
+		}
+		if have := l; have != want {
+			t.Errorf("have %d, want %d", have, want)
+		}
+	}
+
+	if have, want := db.QueryRow("SELECT name FROM albums WHERE id = 42").Scan(&name), sql.ErrNoRows; have != want {
+		t.Errorf("have %v, want %v", have, want)
+	}
+}
+
+func TestColumnTypes(t *testing.T) {
+	db := open(t, "./../testdata/music.sqlite")
+	defer db.Close()
+
+	rows, err := db.Query("SELECT * FROM albums")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var have []string
+	for _, ct := range types {
+		null, _ := ct.Nullable()
+		have = append(have, ct.Name()+" "+ct.DatabaseTypeName()+" "+map[bool]string{true: "NULL", false: "NOT NULL"}[null])
+	}
+	want := []string{
+		"id INTEGER NOT NULL",
+		"artist INTEGER NOT NULL",
+		"name  NULL",
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("diff:\n%s", diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	db := open(t, "./../testdata/music.sqlite")
+	defer db.Close()
+
+	for _, q := range []string{
+		"INSERT INTO artists (name) VALUES ('The Kinks')",
+		"update artists set name = 'x'",
+		"DELETE FROM artists",
+		"DROP TABLE artists",
+		"CREATE TABLE foo (a)",
+		"SELECT name FROM artists", // Exec()
+	} {
+		if _, err := db.Exec(q); err != ErrReadOnly {
+			t.Errorf("%s: have %v, want %v", q, err, ErrReadOnly)
+		}
+	}
+
+	// transactions work, but don't do anything
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	if err := tx.QueryRow("SELECT id FROM artists WHERE name = 'The Beatles'").Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	db := open(t, "./../testdata/music.sqlite")
+	defer db.Close()
+
+	type cas struct {
+		sql  string
+		args []interface{}
+		err  error
+	}
+	for _, c := range []cas{
+		{sql: "SELECT nosuch FROM artists", err: errors.New(`no such column: "nosuch"`)},
+		{sql: "SELECT name FROM nosuch", err: errors.New(`no such table: "nosuch"`)},
+		{sql: "SELECT name FROM artists WHERE id BETWEEN 1 AND 2", err: errors.New("syntax error")},
+		{sql: "SELECT name FROM artists WHERE id + 1 = 2", err: errors.New(`unsupported WHERE: "id"+1=2`)},
+		{sql: "SELECT name FROM artists WHERE name", err: errors.New(`unsupported WHERE: "name"`)},
+		{sql: "SELECT *, name FROM artists", err: errors.New("* can't be used with other columns")},
+		{sql: "SELECT name FROM artists LIMIT 'ten'", err: errors.New("not an integer: ten")},
+		{sql: "SELECT name FROM artists WHERE id = ?", args: []interface{}{1, 2}, err: errors.New("sql: expected 1 arguments, got 2")},
+		{sql: "SELECT name FROM artists WHERE id = :id", err: errors.New(`unexpected char at pos:36: ':'`)},
+	} {
+		_, err := db.Query(c.sql, c.args...)
+		if have, want := err, c.err; !reflect.DeepEqual(have, want) {
+			t.Errorf("%s: have %v, want %v", c.sql, have, want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package sqlittle_test
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/hackborn/sqlittle"
@@ -114,3 +115,128 @@ func ExampleDB_PKSelect() {
 	// output:
 	// Come Together
 }
+
+// SELECT with a WHERE
+func ExampleDB_SelectWith() {
+	db, err := sqlittle.Open("./testdata/music.sqlite")
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	db.SelectWith(
+		"tracks",
+		sqlittle.Options{
+			Where: sqlittle.Or(
+				sqlittle.Like("name", "%wood%"),
+				sqlittle.Between("length", 200, 300),
+			),
+		},
+		func(r sqlittle.Row) {
+			name, _ := r.ScanString()
+			fmt.Printf("%s\n", name)
+		},
+		"name",
+	)
+	// output:
+	// Norwegian Wood
+	// Come Together
+	// Maxwells Silver Hammer
+}
+
+// SELECT album, COUNT(*), SUM(length) FROM tracks GROUP BY album
+func ExampleDB_Aggregate() {
+	db, err := sqlittle.Open("./testdata/music.sqlite")
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	db.Aggregate(
+		"tracks",
+		[]string{"album"},
+		[]sqlittle.Agg{sqlittle.Count("*"), sqlittle.Sum("length")},
+		func(r sqlittle.Row) {
+			var album, count, length int
+			r.Scan(&album, &count, &length)
+			fmt.Printf("album %d: %d tracks, %d seconds\n", album, count, length)
+		},
+	)
+	// output:
+	// album 1: 3 tracks, 464 seconds
+	// album 2: 3 tracks, 648 seconds
+}
+
+// SELECT into structs
+func ExampleDB_SelectInto() {
+	db, err := sqlittle.Open("./testdata/music.sqlite")
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	type Track struct {
+		Name   string
+		Length int `sqlittle:"length"`
+	}
+	var tracks []Track
+	if err := db.SelectInto("tracks", &tracks); err != nil {
+		panic(err)
+	}
+	for _, t := range tracks[:2] {
+		fmt.Printf("%s: %d seconds\n", t.Name, t.Length)
+	}
+	// output:
+	// Drive My Car: 145 seconds
+	// Norwegian Wood: 121 seconds
+}
+
+// Rows as JSON
+func ExampleNamedRow() {
+	db, err := sqlittle.Open("./testdata/music.sqlite")
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	db.SelectWith(
+		"tracks",
+		sqlittle.Options{
+			Limit: 2,
+			Named: func(r sqlittle.NamedRow) {
+				js, _ := json.Marshal(r)
+				fmt.Printf("%s\n", js)
+			},
+		},
+		nil,
+		"name",
+		"length",
+	)
+	// output:
+	// {"name":"Drive My Car","length":145}
+	// {"name":"Norwegian Wood","length":121}
+}
+
+// Rows as structs, with an iterator
+func ExampleAll() {
+	db, err := sqlittle.Open("./testdata/music.sqlite")
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+
+	type Track struct {
+		Name   string
+		Length int
+	}
+	opts := sqlittle.Options{Where: sqlittle.Gt("length", 200)}
+	for t, err := range sqlittle.All[Track](db, "tracks", opts) {
+		if err != nil {
+			panic(err)
+		}
+		fmt.Printf("%s: %d seconds\n", t.Name, t.Length)
+	}
+	// output:
+	// Come Together: 259 seconds
+	// Maxwells Silver Hammer: 207 seconds
+}
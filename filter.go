@@ -0,0 +1,266 @@
+package sqlittle
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	sdb "github.com/hackborn/sqlittle/db"
+)
+
+// Filter is a condition on a row, as used in Options.Where. Make them with Eq(),
+// Ne(), Lt(), Le(), Gt(), Ge(), Between(), In(), IsNull(), Like(), Glob(),
+// And(), Or(), and Not().
+//
+// Filters follow SQLite's WHERE semantics: values are compared with the
+// collate function of the column, and anything compared with NULL is NULL,
+// which is not true. Not() of NULL is still NULL, so:
+//
+//	Not(Eq("name", "x"))
+//
+// will not match rows where name is NULL, same as SQLite.
+//
+// Filters are checked against the database record before a Row is made for
+// it.
+type Filter interface {
+	compile(*filterCompiler) (matcher, error)
+}
+
+// truth is SQL's three-valued logic
+type truth int
+
+const (
+	isFalse truth = iota
+	isTrue
+	isNull
+)
+
+func truthOf(b bool) truth {
+	if b {
+		return isTrue
+	}
+	return isFalse
+}
+
+// matcher is a compiled Filter. The columnIndexes are for all columns from
+// filterCompiler.columns.
+type matcher func(rowid int64, ci []columnIndex, r sdb.Record) truth
+
+type filterCompiler struct {
+	schema  *sdb.Schema
+	columns []string    // all columns which need to be loaded
+	scan    ScanOptions // to convert time.Time values
+}
+
+// add adds a column to the columns to load, and returns its position.
+func (fc *filterCompiler) add(column string) int {
+	fc.columns = append(fc.columns, column)
+	return len(fc.columns) - 1
+}
+
+// Between is true if the value is between lo and hi, inclusive. It's the same
+// as And(Ge(column, lo), Le(column, hi)).
+func Between(column string, lo, hi interface{}) Filter {
+	return And(Ge(column, lo), Le(column, hi))
+}
+
+// And is true if all filters are true. An And without filters is always true.
+func And(fs ...Filter) Filter { return and(fs) }
+
+type and []Filter
+
+func (a and) compile(fc *filterCompiler) (matcher, error) {
+	ms, err := compileAll(fc, a)
+	if err != nil {
+		return nil, err
+	}
+	return func(rowid int64, ci []columnIndex, r sdb.Record) truth {
+		res := isTrue
+		for _, m := range ms {
+			switch m(rowid, ci, r) {
+			case isFalse:
+				return isFalse
+			case isNull:
+				res = isNull
+			}
+		}
+		return res
+	}, nil
+}
+
+// Or is true if any filter is true. An Or without filters is always false.
+func Or(fs ...Filter) Filter { return or(fs) }
+
+type or []Filter
+
+func (o or) compile(fc *filterCompiler) (matcher, error) {
+	ms, err := compileAll(fc, o)
+	if err != nil {
+		return nil, err
+	}
+	return func(rowid int64, ci []columnIndex, r sdb.Record) truth {
+		res := isFalse
+		for _, m := range ms {
+			switch m(rowid, ci, r) {
+			case isTrue:
+				return isTrue
+			case isNull:
+				res = isNull
+			}
+		}
+		return res
+	}, nil
+}
+
+func compileAll(fc *filterCompiler, fs []Filter) ([]matcher, error) {
+	ms := make([]matcher, 0, len(fs))
+	for _, f := range fs {
+		m, err := f.compile(fc)
+		if err != nil {
+			return nil, err
+		}
+		ms = append(ms, m)
+	}
+	return ms, nil
+}
+
+// Not inverts a filter. Not of NULL is NULL.
+func Not(f Filter) Filter { return not{f} }
+
+type not struct {
+	f Filter
+}
+
+func (n not) compile(fc *filterCompiler) (matcher, error) {
+	m, err := n.f.compile(fc)
+	if err != nil {
+		return nil, err
+	}
+	return func(rowid int64, ci []columnIndex, r sdb.Record) truth {
+		switch m(rowid, ci, r) {
+		case isTrue:
+			return isFalse
+		case isFalse:
+			return isTrue
+		default:
+			return isNull
+		}
+	}, nil
+}
+
+// In is true if the column equals any of the values. As in SQLite an In()
+// without values is always false, even for NULL.
+func In(column string, vs ...interface{}) Filter { return in{column, vs} }
+
+type in struct {
+	column string
+	values []interface{}
+}
+
+func (n in) compile(fc *filterCompiler) (matcher, error) {
+	_, _, collate, err := lookupColumn(fc.schema, n.column)
+	if err != nil {
+		return nil, err
+	}
+	aff := columnAffinity(fc.schema, n.column)
+	vs := make([]interface{}, 0, len(n.values))
+	for _, v := range n.values {
+		dv, err := toKeyValue(v, aff, fc.scan)
+		if err != nil {
+			return nil, err
+		}
+		vs = append(vs, dv)
+	}
+	pos := fc.add(n.column)
+	return func(rowid int64, ci []columnIndex, r sdb.Record) truth {
+		if len(vs) == 0 {
+			return isFalse
+		}
+		v := ci[pos].value(rowid, r)
+		if v == nil {
+			return isNull
+		}
+		res := isFalse
+		for _, w := range vs {
+			if w == nil {
+				res = isNull
+				continue
+			}
+			if sdb.Compare(v, w, collate) == 0 {
+				return isTrue
+			}
+		}
+		return res
+	}, nil
+}
+
+// Like is SQLite's LIKE: `%` matches any sequence of characters, `_` matches
+// a single character, and ASCII letters match case insensitively. The collate
+// function of the column is not used. Numbers are matched on their text form.
+func Like(column string, pattern string) Filter { return like{column, pattern, false} }
+
+// Glob is SQLite's GLOB: `*` matches any sequence of characters, `?` matches a
+// single character, and `[...]` matches a set of characters such as `[a-z]`
+// or `[^0-9]`. Matching is case sensitive.
+func Glob(column string, pattern string) Filter { return like{column, pattern, true} }
+
+type like struct {
+	column  string
+	pattern string
+	glob    bool
+}
+
+func (l like) compile(fc *filterCompiler) (matcher, error) {
+	if _, _, _, err := lookupColumn(fc.schema, l.column); err != nil {
+		return nil, err
+	}
+	pattern := []rune(l.pattern)
+	match := matchLike
+	if l.glob {
+		match = matchGlob
+	}
+	pos := fc.add(l.column)
+	return func(rowid int64, ci []columnIndex, r sdb.Record) truth {
+		s, ok := asText(ci[pos].value(rowid, r))
+		if !ok {
+			return isNull
+		}
+		return truthOf(match(pattern, []rune(s)))
+	}, nil
+}
+
+// asText converts a database value to text, the way SQLite does. Returns false
+// for NULL.
+func asText(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case []byte:
+		return string(v), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case float64:
+		return realText(v), true
+	default:
+		return "", false
+	}
+}
+
+// realText formats a float as SQLite does (printf's "%!.15g"): 15 digits, and
+// there is always a decimal point.
+func realText(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	}
+	s := strconv.FormatFloat(f, 'g', 15, 64)
+	if strings.IndexByte(s, '.') >= 0 {
+		return s
+	}
+	if i := strings.IndexByte(s, 'e'); i >= 0 {
+		return s[:i] + ".0" + s[i:]
+	}
+	return s + ".0"
+}
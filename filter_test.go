@@ -0,0 +1,289 @@
+package sqlittle
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/andreyvit/diff"
+	"github.com/davecgh/go-spew/spew"
+)
+
+func TestSelectWith(t *testing.T) {
+	db, err := Open("testdata/words.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var words []string
+	cb := func(r Row) {
+		w, _ := r.ScanString()
+		words = append(words, w)
+	}
+
+	type cas struct {
+		filter Filter
+		want   []string
+	}
+	for n, c := range []cas{
+		{
+			filter: And(Eq("length", 3), Like("word", "B%")),
+			want:   []string{"Bic", "big"},
+		},
+		{
+			filter: Or(Glob("word", "[xz]*"), Between("length", 16, 30)),
+			want: []string{
+				"mysteriousness's",
+				"unconsciousness's",
+				"electrification's",
+				"bloodthirstiness's",
+				"multiplication's",
+				"thoughtfulness's",
+				"substantiation's",
+				"internationalism's",
+				"immaculateness's",
+				"authentication's",
+			},
+		},
+		{
+			filter: Between("word", "ba", "bb"),
+			want: []string{
+				"babels",
+				"babysitter's",
+				"backbit",
+				"backrest's",
+				"backstroke",
+				"badder",
+				"baffled",
+				"baked",
+				"baking",
+				"balloonist",
+				"band's",
+				"bankbook",
+				"barnstorm",
+				"barricade",
+				"baseball's",
+				"basilica's",
+			},
+		},
+		{
+			filter: And(Gt("length", 10), Lt("rowid", 30), Ne("word", "supernumeraries")),
+			want: []string{
+				"volatility's",
+				"mysteriousness's",
+				"semiconscious",
+				"flamboyance",
+				"apocalypse's",
+				"enlightenment's",
+				"Zimbabwean's",
+			},
+		},
+		{
+			filter: In("length"),
+			want:   nil,
+		},
+	} {
+		words = nil
+		if err := db.SelectWith("words", Options{Where: c.filter}, cb, "word"); err != nil {
+			t.Fatal(err)
+		}
+		sort.Strings(words)
+		sort.Strings(c.want)
+		if have, want := words, c.want; !reflect.DeepEqual(have, want) {
+			t.Errorf("case %d diff:\n%s", n, diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+		}
+	}
+
+	// an empty And() is always true
+	words = nil
+	if err := db.SelectWith("words", Options{Where: And()}, cb, "word"); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := len(words), 1000; have != want {
+		t.Errorf("have %d, want %d", have, want)
+	}
+}
+
+func TestSelectWithNull(t *testing.T) {
+	db, err := Open("testdata/values.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var rowids []int64
+	cb := func(r Row) {
+		var rowid int64
+		r.Scan(&rowid)
+		rowids = append(rowids, rowid)
+	}
+
+	type cas struct {
+		filter Filter
+		want   []int64
+	}
+	for n, c := range []cas{
+		{
+			filter: Not(Eq("c", "x")),
+			want:   []int64{2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17},
+		},
+		{
+			filter: Or(IsNull("c"), Eq("i", 80)),
+			want:   []int64{1, 4},
+		},
+		{
+			filter: In("c"),
+			want:   nil,
+		},
+		{
+			filter: In("c", nil, ""),
+			want:   []int64{2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17},
+		},
+		{
+			filter: Not(In("c", nil, "x")),
+			want:   nil,
+		},
+		{
+			filter: Like("f", "3.1%"),
+			want:   []int64{16},
+		},
+		{
+			filter: Like("i", "-8%"),
+			want:   []int64{5},
+		},
+	} {
+		rowids = nil
+		if err := db.SelectWith("things", Options{Where: c.filter}, cb, "rowid"); err != nil {
+			t.Fatal(err)
+		}
+		if have, want := rowids, c.want; !reflect.DeepEqual(have, want) {
+			t.Errorf("case %d: have %v, want %v", n, have, want)
+		}
+	}
+}
+
+func TestSelectWithNonRowid(t *testing.T) {
+	db, err := Open("testdata/withoutrowid.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var words []string
+	cb := func(r Row) {
+		w, _ := r.ScanString()
+		words = append(words, w)
+	}
+	f := And(Ge("length", 14), Not(Like("word", "%'s")))
+	if err := db.SelectWith("words", Options{Where: f}, cb, "word"); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"chrysanthemums",
+		"collectivizing",
+		"commercializing",
+		"conglomerating",
+		"diagnosticians",
+		"excruciatingly",
+		"incarcerations",
+		"individualized",
+		"industriousness",
+		"insurmountable",
+		"proprietresses",
+		"servomechanism",
+		"substantiations",
+		"supernumeraries",
+		"trustworthiness",
+		"verisimilitude",
+	}
+	if have, want := words, want; !reflect.DeepEqual(have, want) {
+		t.Errorf("diff:\n%s", diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+	}
+
+	// filter on a column which isn't in the index
+	words = nil
+	f = Like("word", "%q%")
+	if err := db.IndexedSelectEqWith("words", "words_l", Key{8}, Options{Where: f}, cb, "word"); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := words, []string{"quandary", "quarrels", "quasar's", "quelling", "squiggle"}; !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+}
+
+func TestIndexedSelectWith(t *testing.T) {
+	db, err := Open("testdata/words.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var words []string
+	cb := func(r Row) {
+		w, _ := r.ScanString()
+		words = append(words, w)
+	}
+
+	f := Like("word", "%q%")
+	if err := db.IndexedSelectWith("words", "words_index_2", Options{Where: f}, cb, "word"); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"equip",
+		"quandary",
+		"quarrels",
+		"quasar's",
+		"quelling",
+		"squiggle",
+		"Algonquin",
+		"Joaquin's",
+		"equinox's",
+		"equipages",
+		"squadron's",
+		"tranquiler",
+		"acquisition",
+		"quarterback",
+		"quintuplets",
+		"tourniquets",
+		"misquotation",
+		"equilibrium's",
+		"requisitioned",
+	}
+	if have, want := words, want; !reflect.DeepEqual(have, want) {
+		t.Errorf("diff:\n%s", diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+	}
+
+	words = nil
+	f = Glob("word", "*'s")
+	if err := db.IndexedSelectEqWith("words", "words_index_2", Key{4}, Options{Where: f}, cb, "word"); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := words, []string{"Eu's", "pi's"}; !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+}
+
+func TestFilterErrors(t *testing.T) {
+	db, err := Open("testdata/words.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	cb := func(r Row) {}
+	for n, c := range []struct {
+		filter Filter
+		want   error
+	}{
+		{Like("nosuch", "a"), errors.New(`no such column: "nosuch"`)},
+		{Or(Eq("word", "a"), Not(In("nosuch", 1))), errors.New(`no such column: "nosuch"`)},
+		{In("word", struct{}{}), errors.New(`unknown Key datatype: struct {}`)},
+		{Cond{"word", Op(42), 1}, errors.New(`invalid operator: 42`)},
+	} {
+		if have, want := db.SelectWith("words", Options{Where: c.filter}, cb, "word"), c.want; !reflect.DeepEqual(have, want) {
+			t.Errorf("case %d: have %v, want %v", n, have, want)
+		}
+	}
+}
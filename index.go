@@ -0,0 +1,94 @@
+package sqlittle
+
+import (
+	sdb "github.com/hackborn/sqlittle/db"
+	"github.com/hackborn/sqlittle/sql"
+)
+
+// IndexInfo describes an index which can be used with IndexedSelect() and
+// IndexedSelectEq().
+type IndexInfo struct {
+	// Name of the index. Use this name in IndexedSelect() and friends.
+	Name    string
+	Columns []IndexColumn
+	Unique  bool
+	// Where is the `WHERE` expression of a partial index. Empty if this
+	// index has all rows.
+	Where string
+	// Origin is where the index comes from: "c" for `CREATE INDEX`, "u" for a
+	// `UNIQUE` constraint, and "pk" for a `PRIMARY KEY`. These are the same
+	// values as `PRAGMA index_list` uses.
+	Origin string
+}
+
+// IndexColumn is a single column in an IndexInfo.
+type IndexColumn struct {
+	// Column is the name of the table column. Empty for expression columns.
+	Column string
+	// Expression is the expression stored in the index, if this is not a
+	// simple column.
+	Expression string
+	Desc       bool
+	// Collate is the name of the collate function, "binary" by default.
+	Collate string
+}
+
+func newIndexInfo(ind *sdb.SchemaIndex) IndexInfo {
+	info := IndexInfo{
+		Name:   ind.Index,
+		Unique: ind.Unique,
+		Origin: ind.Origin,
+	}
+	if ind.Where != nil {
+		info.Where = sql.AsString(ind.Where)
+	}
+	for _, c := range ind.Columns {
+		info.Columns = append(info.Columns, IndexColumn{
+			Column:     c.Column,
+			Expression: c.Expression,
+			Desc:       c.SortOrder == sql.Desc,
+			Collate:    normCollate(c.Collate),
+		})
+	}
+	return info
+}
+
+// all indexes from a schema, in schema order
+func indexes(s *sdb.Schema) []IndexInfo {
+	var res []IndexInfo
+	for i := range s.Indexes {
+		res = append(res, newIndexInfo(&s.Indexes[i]))
+	}
+	return res
+}
+
+// findIndex finds the best index which starts with the columns. Partial
+// indexes are never used. An index with exactly those columns is preferred over
+// an index which has more columns.
+func findIndex(s *sdb.Schema, columns []string) *sdb.SchemaIndex {
+	var best *sdb.SchemaIndex
+	for i := range s.Indexes {
+		ind := &s.Indexes[i]
+		if ind.Where != nil || !hasPrefix(ind, columns) {
+			continue
+		}
+		if best == nil || len(ind.Columns) < len(best.Columns) {
+			best = ind
+		}
+	}
+	return best
+}
+
+// hasPrefix is true if the first index columns are the given columns, in
+// order.
+func hasPrefix(ind *sdb.SchemaIndex, columns []string) bool {
+	if len(columns) > len(ind.Columns) {
+		return false
+	}
+	for i, c := range columns {
+		if ind.Column(c) != i {
+			return false
+		}
+	}
+	return true
+}
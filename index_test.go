@@ -0,0 +1,109 @@
+package sqlittle
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/andreyvit/diff"
+	"github.com/davecgh/go-spew/spew"
+)
+
+func TestIndexes(t *testing.T) {
+	db, err := Open("testdata/expr.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	have, err := db.Indexes("expr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []IndexInfo{
+		{
+			Name: "expr_name",
+			Columns: []IndexColumn{
+				{Expression: `"substr"("name", 0, 10)`, Collate: "binary"},
+			},
+			Origin: "c",
+		},
+		{
+			Name: "expr_where",
+			Columns: []IndexColumn{
+				{Column: "name", Collate: "binary"},
+			},
+			Where:  `"name">"foo"`,
+			Origin: "c",
+		},
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("diff:\n%s", diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+	}
+
+	if _, err := db.Indexes("nosuch"); err == nil {
+		t.Errorf("expected an error")
+	}
+}
+
+func TestFindIndex(t *testing.T) {
+	test := func(file, table string, columns []string, want string) {
+		t.Helper()
+		db, err := Open(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+
+		ind, err := db.FindIndex(table, columns...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		have := ""
+		if ind != nil {
+			have = ind.Name
+		}
+		if have != want {
+			t.Errorf("have %q, want %q", have, want)
+		}
+	}
+
+	test("testdata/funkykey.sqlite", "fuz", []string{"b"}, "sqlite_autoindex_fuz_2")
+	test("testdata/funkykey.sqlite", "fuz", []string{"B", "c"}, "sqlite_autoindex_fuz_3")
+	test("testdata/funkykey.sqlite", "fuz", []string{"a"}, "sqlite_autoindex_fuz_4")
+	test("testdata/funkykey.sqlite", "fuz", []string{"c"}, "") // that's the table
+	test("testdata/funkykey.sqlite", "fuz", []string{"c", "b"}, "")
+	test("testdata/prefix.sqlite", "words", []string{"word"}, "sqlite_autoindex_words_1")
+	test("testdata/prefix.sqlite", "words", []string{"length"}, "words_length")
+	test("testdata/expr.sqlite", "expr", []string{"name"}, "") // partial index
+}
+
+func TestFindIndexInfo(t *testing.T) {
+	db, err := Open("testdata/prefix.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	have, err := db.FindIndex("words", "prefix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &IndexInfo{
+		Name: "words_prefix",
+		Columns: []IndexColumn{
+			{Column: "prefix", Collate: "binary"},
+		},
+		Origin: "c",
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("diff:\n%s", diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+	}
+
+	have, err = db.FindIndex("words", "word")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have.Origin != "pk" || !have.Unique {
+		t.Errorf("have %+v", have)
+	}
+}
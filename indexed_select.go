@@ -9,78 +9,123 @@ func indexedSelect(
 	db *sdb.Database,
 	schema *sdb.Schema,
 	index *sdb.SchemaIndex,
-	cb RowCB,
+	e *emitter,
 	columns []string,
 ) error {
-	ci, err := toColumnIndexRowid(schema, columns)
+	ind, err := db.Index(index.Index)
 	if err != nil {
 		return err
 	}
 
-	tab, err := db.Table(schema.Table)
+	rcb, err := indexRecordCB(db, schema, index, e, columns)
 	if err != nil {
 		return err
 	}
+	return scanIndex(schema, index, ind, e, rcb)
+}
 
+// index (==) search on a rowid table
+func indexedSelectEq(
+	db *sdb.Database,
+	schema *sdb.Schema,
+	index *sdb.SchemaIndex,
+	key sdb.Key,
+	e *emitter,
+	columns []string,
+) error {
 	ind, err := db.Index(index.Index)
 	if err != nil {
 		return err
 	}
 
-	return ind.Scan(func(r sdb.Record) bool {
-		rowid, _, err := sdb.ChompRowid(r)
-		if err != nil {
-			return false
-		}
-		row, err := tab.Rowid(rowid)
-		if err != nil || row == nil {
-			// row should never be nil
-			return false
-		}
-		cb(toRow(rowid, ci, row))
-		return false
-	})
+	rcb, err := indexRecordCB(db, schema, index, e, columns)
+	if err != nil {
+		return err
+	}
+	return scanIndexEq(schema, index, ind, key, e, rcb)
 }
 
-// index (==) search on a rowid table
-func indexedSelectEq(
+// index skip-scan search; the key is for the index columns after the first
+// skip columns
+func indexedSkipScan(
 	db *sdb.Database,
 	schema *sdb.Schema,
 	index *sdb.SchemaIndex,
-	key sdb.Key,
-	cb RowCB,
+	skip int,
+	key Key,
+	e *emitter,
 	columns []string,
 ) error {
-	ci, err := toColumnIndexRowid(schema, columns)
+	ind, err := db.Index(index.Index)
 	if err != nil {
 		return err
 	}
 
-	tab, err := db.Table(schema.Table)
+	var rcb sdb.RecordCB
+	if schema.WithoutRowid {
+		rcb, err = indexRecordCBNonRowid(db, schema, index, e, columns)
+	} else {
+		rcb, err = indexRecordCB(db, schema, index, e, columns)
+	}
 	if err != nil {
 		return err
 	}
+	if skip < len(index.Columns) {
+		if key, err = keyValues(schema, index.Columns[skip:], key, e.scan); err != nil {
+			return err
+		}
+	}
+	return skipScan(ind, index.Columns, skip, key, rcb)
+}
 
-	ind, err := db.Index(index.Index)
+// indexRecordCB makes the callback for index scans on a rowid table. If the
+// index has all the columns we need the values come straight from the index,
+// otherwise every row is looked up in the table.
+func indexRecordCB(
+	db *sdb.Database,
+	schema *sdb.Schema,
+	index *sdb.SchemaIndex,
+	e *emitter,
+	columns []string,
+) (sdb.RecordCB, error) {
+	ci, err := toColumnIndexRowid(schema, columns)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return ind.ScanEq(
-		key,
-		func(r sdb.Record) bool {
+	if cov, ok := coveringColumns(index, ci); ok {
+		return func(r sdb.Record) bool {
 			rowid, _, err := sdb.ChompRowid(r)
 			if err != nil {
 				return false
 			}
-			row, err := tab.Rowid(rowid)
-			if err != nil || row == nil {
-				// row should never be nil
-				return false
+			if e.emit(rowid, cov, r) {
+				return e.stop(r)
 			}
-			cb(toRow(rowid, ci, row))
 			return false
-		})
+		}, nil
+	}
+
+	tab, err := db.Table(schema.Table)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(r sdb.Record) bool {
+		rowid, _, err := sdb.ChompRowid(r)
+		if err != nil {
+			return false
+		}
+		row, err := tab.Rowid(rowid)
+		if err != nil || row == nil {
+			// row should never be nil
+			return false
+		}
+		if e.emit(rowid, ci, row) {
+			return e.stop(r)
+		}
+		return false
+	}, nil
 }
 
 // index scan on a WITHOUT ROWID table
@@ -88,33 +133,83 @@ func indexedSelectNonRowid(
 	db *sdb.Database,
 	schema *sdb.Schema,
 	index *sdb.SchemaIndex,
-	cb RowCB,
+	e *emitter,
 	columns []string,
 ) error {
-	ci, err := toColumnIndexNonRowid(schema, columns)
+	ind, err := db.Index(index.Index)
 	if err != nil {
 		return err
 	}
 
-	tab, err := db.NonRowidTable(schema.Table)
+	rcb, err := indexRecordCBNonRowid(db, schema, index, e, columns)
 	if err != nil {
 		return err
 	}
+	return scanIndex(schema, index, ind, e, rcb)
+}
 
+// index (==) search on a WITHOUT ROWID table
+func indexedSelectEqNonRowid(
+	db *sdb.Database,
+	schema *sdb.Schema,
+	index *sdb.SchemaIndex,
+	key sdb.Key,
+	e *emitter,
+	columns []string,
+) error {
 	ind, err := db.Index(index.Index)
 	if err != nil {
 		return err
 	}
 
+	rcb, err := indexRecordCBNonRowid(db, schema, index, e, columns)
+	if err != nil {
+		return err
+	}
+	return scanIndexEq(schema, index, ind, key, e, rcb)
+}
+
+// indexRecordCBNonRowid makes the callback for index scans on a WITHOUT ROWID
+// table. If the index has all the columns we need the values come straight
+// from the index, otherwise every row is looked up in the table by primary
+// key.
+func indexRecordCBNonRowid(
+	db *sdb.Database,
+	schema *sdb.Schema,
+	index *sdb.SchemaIndex,
+	e *emitter,
+	columns []string,
+) (sdb.RecordCB, error) {
+	ci, err := toColumnIndexNonRowid(schema, columns)
+	if err != nil {
+		return nil, err
+	}
+
+	// this adds the primary key columns to the index columns
 	cols := pkColumns(schema, index)
+
+	if cov, ok := coveringColumns(index, ci); ok {
+		return func(r sdb.Record) bool {
+			if e.emit(0, cov, r) {
+				return e.stop(r)
+			}
+			return false
+		}, nil
+	}
+
+	tab, err := db.NonRowidTable(schema.Table)
+	if err != nil {
+		return nil, err
+	}
+
 	// make an empty key with the correct definition which we update in the
 	// callback
 	pk, err := asDbKey(make(Key, len(schema.PK)), schema.PK)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return ind.Scan(func(r sdb.Record) bool {
+	return func(r sdb.Record) bool {
 		setKey(r, cols, pk)
 
 		var found sdb.Record
@@ -126,58 +221,82 @@ func indexedSelectNonRowid(
 			// found should never be nil
 			return false
 		}
-		cb(toRow(0, ci, found))
+		if e.emit(0, ci, found) {
+			return e.stop(r)
+		}
 		return false
-	})
+	}, nil
 }
 
-// index (==) search on a WITHOUT ROWID table
-func indexedSelectEqNonRowid(
-	db *sdb.Database,
+// scanIndex scans the whole index, or the part after the position to continue
+// after.
+func scanIndex(
 	schema *sdb.Schema,
 	index *sdb.SchemaIndex,
-	key sdb.Key,
-	cb RowCB,
-	columns []string,
+	ind *sdb.Index,
+	e *emitter,
+	rcb sdb.RecordCB,
 ) error {
-	ci, err := toColumnIndexNonRowid(schema, columns)
-	if err != nil {
-		return err
+	if e.after == nil {
+		return ind.Scan(rcb)
 	}
-
-	tab, err := db.NonRowidTable(schema.Table)
+	from, err := afterKey(schema, index, e.after)
 	if err != nil {
 		return err
 	}
+	return ind.ScanAfter(from, rcb)
+}
 
-	ind, err := db.Index(index.Index)
-	if err != nil {
-		return err
+// scanIndexEq scans the records matching the key, or the ones after the
+// position to continue after. That position has to match the key.
+func scanIndexEq(
+	schema *sdb.Schema,
+	index *sdb.SchemaIndex,
+	ind *sdb.Index,
+	key sdb.Key,
+	e *emitter,
+	rcb sdb.RecordCB,
+) error {
+	if e.after == nil {
+		return ind.ScanEq(key, rcb)
 	}
-
-	cols := pkColumns(schema, index)
-	// make an empty key with the correct definition which we update in the
-	// callback
-	pk, err := asDbKey(make(Key, len(schema.PK)), schema.PK)
+	if !sdb.Equals(key, e.after) {
+		return ErrInvalidToken
+	}
+	from, err := afterKey(schema, index, e.after)
 	if err != nil {
 		return err
 	}
+	return ind.ScanAfter(from, func(r sdb.Record) bool {
+		if !sdb.Equals(key, r) {
+			return true
+		}
+		return rcb(r)
+	})
+}
 
-	return ind.ScanEq(
-		key,
-		func(r sdb.Record) bool {
-			setKey(r, cols, pk)
-
-			var found sdb.Record
-			err := tab.ScanEq(pk, func(row sdb.Record) bool { found = row; return true })
-			if err != nil || found == nil {
-				// found should never be nil
-				return false
-			}
-			cb(toRow(0, ci, found))
-			return false
-		},
-	)
+// coveringColumns checks whether all columns can be read from the index
+// record. If so it returns the column indexes for a record from the index.
+// Expression columns never match, since the index stores the value of the
+// expression and not the column value. The rowid is always available.
+//
+// For WITHOUT ROWID tables call pkColumns() first, so the index knows about the
+// primary key columns it stores.
+func coveringColumns(index *sdb.SchemaIndex, cis []columnIndex) ([]columnIndex, bool) {
+	res := make([]columnIndex, len(cis))
+	for i, c := range cis {
+		if c.rowid {
+			res[i] = c
+			continue
+		}
+		n := index.Column(c.col.Column)
+		if n < 0 {
+			return nil, false
+		}
+		res[i] = c
+		res[i].rowIndex = n
+	}
+	return res, true
 }
 
 // make a key from columns from the record
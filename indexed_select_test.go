@@ -6,6 +6,7 @@ import (
 
 	"github.com/andreyvit/diff"
 	"github.com/davecgh/go-spew/spew"
+	sdb "github.com/hackborn/sqlittle/db"
 )
 
 func init() {
@@ -207,3 +208,119 @@ func TestIndexedSelectExprCol(t *testing.T) {
 		t.Errorf("diff:\n%s", diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
 	}
 }
+
+func TestIndexedSelectCovering(t *testing.T) {
+	// all columns are in the index; no table lookups
+	db, err := Open("testdata/words.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var rows []Row
+	cb := func(r Row) {
+		rows = append(rows, r)
+	}
+	if err := db.IndexedSelectEq(
+		"words",
+		"words_index_2",
+		Key{3},
+		cb,
+		"word",
+		"length",
+		"rowid",
+	); err != nil {
+		t.Fatal(err)
+	}
+	want := []Row{
+		{"Amy", int64(3), int64(619)},
+		{"Bic", int64(3), int64(951)},
+		{"Eva", int64(3), int64(777)},
+		{"Len", int64(3), int64(243)},
+		{"big", int64(3), int64(868)},
+		{"fir", int64(3), int64(141)},
+		{"nab", int64(3), int64(62)},
+		{"pat", int64(3), int64(405)},
+	}
+	if have, want := rows, want; !reflect.DeepEqual(have, want) {
+		t.Errorf("diff:\n%s", diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+	}
+}
+
+func TestIndexedSelectCoveringNonRowid(t *testing.T) {
+	// the primary key columns are stored in the index
+	db, err := Open("testdata/funkykey.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var rows [][]string
+	cb := func(r Row) {
+		rows = append(rows, r.ScanStrings())
+	}
+	// index on (b), which also stores c and a
+	if err := db.IndexedSelect(
+		"fuz",
+		"sqlite_autoindex_fuz_2",
+		cb,
+		"a",
+		"b",
+		"c",
+	); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"allegory", "beagle", "consequent"},
+		{"algebraic", "begotten", "colder"},
+		{"angle", "billiards", "crotchety"},
+	}
+	if have, want := rows, want; !reflect.DeepEqual(have, want) {
+		t.Errorf("diff:\n%s", diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+	}
+}
+
+func TestCoveringColumns(t *testing.T) {
+	schema := &sdb.Schema{
+		Table: "words",
+		Columns: []sdb.TableColumn{
+			{Column: "id", Type: "INTEGER", Rowid: true},
+			{Column: "word"},
+			{Column: "length"},
+		},
+	}
+	index := &sdb.SchemaIndex{
+		Index: "words_length",
+		Columns: []sdb.IndexColumn{
+			{Column: "length"},
+			{Expression: `"lower"("word")`},
+		},
+	}
+
+	test := func(columns []string, want []columnIndex, wantOK bool) {
+		t.Helper()
+		ci, err := toColumnIndexRowid(schema, columns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		have, ok := coveringColumns(index, ci)
+		if ok != wantOK {
+			t.Fatalf("have %t, want %t", ok, wantOK)
+		}
+		if !reflect.DeepEqual(have, want) {
+			t.Errorf("diff:\n%s", diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+		}
+	}
+
+	test(
+		[]string{"length", "id", "rowid"},
+		[]columnIndex{
+			newColumnIndex(&schema.Columns[2], 0),
+			{rowIndex: 0, rowid: true},
+			{rowIndex: -1, rowid: true},
+		},
+		true,
+	)
+	// expression columns don't count
+	test([]string{"length", "word"}, nil, false)
+}
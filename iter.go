@@ -0,0 +1,137 @@
+package sqlittle
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"reflect"
+)
+
+// stoppable runs a select with a callback which can stop it: when yield
+// returns false the context given to sel is canceled, so the select stops at
+// the next page read, and yield isn't called again. It gives nil if yield
+// stopped the select, and the error of the select otherwise.
+func stoppable(ctx context.Context, sel func(context.Context, RowCB) error, yield func(Row) bool) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stopped := false
+	err := sel(ctx, func(r Row) {
+		if stopped {
+			return
+		}
+		if !yield(r) {
+			stopped = true
+			cancel()
+		}
+	})
+	if stopped {
+		return nil
+	}
+	return err
+}
+
+// rowSeq makes an iterator from a select. Errors are given as the last pair,
+// with a nil Row.
+func rowSeq(ctx context.Context, sel func(context.Context, RowCB) error) iter.Seq2[Row, error] {
+	return func(yield func(Row, error) bool) {
+		if err := stoppable(ctx, sel, func(r Row) bool {
+			return yield(r, nil)
+		}); err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// SelectSeq is SelectWith() as an iterator:
+//
+//	for r, err := range db.SelectSeq("words", Options{}, "word") {
+//	    if err != nil {
+//	        return err
+//	    }
+//	    ...
+//	}
+//
+// An error is given as the last pair, with a nil Row. The database is locked
+// while the loop runs, and it's unlocked when the loop ends, including with a
+// break or a return. As with a callback, the Row values are only valid in the
+// loop body.
+func (db *DB) SelectSeq(table string, opts Options, columns ...string) iter.Seq2[Row, error] {
+	return db.SelectSeqContext(context.Background(), table, opts, columns...)
+}
+
+// SelectSeqContext is SelectSeq() with a context. See SelectContext().
+func (db *DB) SelectSeqContext(ctx context.Context, table string, opts Options, columns ...string) iter.Seq2[Row, error] {
+	return rowSeq(ctx, func(ctx context.Context, cb RowCB) error {
+		return db.SelectWithContext(ctx, table, opts, cb, columns...)
+	})
+}
+
+// IndexedSelectSeq is IndexedSelectWith() as an iterator. See SelectSeq().
+func (db *DB) IndexedSelectSeq(table, index string, opts Options, columns ...string) iter.Seq2[Row, error] {
+	return db.IndexedSelectSeqContext(context.Background(), table, index, opts, columns...)
+}
+
+// IndexedSelectSeqContext is IndexedSelectSeq() with a context. See
+// SelectContext().
+func (db *DB) IndexedSelectSeqContext(ctx context.Context, table, index string, opts Options, columns ...string) iter.Seq2[Row, error] {
+	return rowSeq(ctx, func(ctx context.Context, cb RowCB) error {
+		return db.IndexedSelectWithContext(ctx, table, index, opts, cb, columns...)
+	})
+}
+
+// IndexedSelectEqSeq is IndexedSelectEqWith() as an iterator. See SelectSeq().
+func (db *DB) IndexedSelectEqSeq(table, index string, key Key, opts Options, columns ...string) iter.Seq2[Row, error] {
+	return db.IndexedSelectEqSeqContext(context.Background(), table, index, key, opts, columns...)
+}
+
+// IndexedSelectEqSeqContext is IndexedSelectEqSeq() with a context. See
+// SelectContext().
+func (db *DB) IndexedSelectEqSeqContext(ctx context.Context, table, index string, key Key, opts Options, columns ...string) iter.Seq2[Row, error] {
+	return rowSeq(ctx, func(ctx context.Context, cb RowCB) error {
+		return db.IndexedSelectEqWithContext(ctx, table, index, key, opts, cb, columns...)
+	})
+}
+
+// All reads the rows of a table into structs, as an iterator. T is a struct,
+// or a pointer to a struct, and the columns are its fields; see
+// Row.ScanStruct().
+//
+//	for t, err := range sqlittle.All[Track](db, "tracks", Options{}) {
+//	    if err != nil {
+//	        return err
+//	    }
+//	    ...
+//	}
+//
+// opts can have a Where, OrderBy, and Limit; After, Next, and SkipScan can't be
+// used, the same as with WhereWith(). Times are read with the options from
+// SetScanOptions(). Errors, including for a field which can't be scanned, are
+// given as the last pair. The database is unlocked when the loop
+// ends, including with a break or a return.
+func All[T any](db *DB, table string, opts Options) iter.Seq2[T, error] {
+	return AllContext[T](context.Background(), db, table, opts)
+}
+
+// AllContext is All() with a context. See SelectContext().
+func AllContext[T any](ctx context.Context, db *DB, table string, opts Options) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		t := reflect.TypeOf(&zero).Elem()
+		ptr := t.Kind() == reflect.Ptr
+		if ptr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			yield(zero, fmt.Errorf("not a struct: %s", reflect.TypeOf(&zero).Elem()))
+			return
+		}
+		if err := db.selectStructs(ctx, "All", table, opts, t, func(v reflect.Value) bool {
+			if !ptr {
+				v = v.Elem()
+			}
+			return yield(v.Interface().(T), nil)
+		}); err != nil {
+			yield(zero, err)
+		}
+	}
+}
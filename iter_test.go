@@ -0,0 +1,174 @@
+package sqlittle
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSelectSeq(t *testing.T) {
+	db, err := Open("testdata/words.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	rec := &callRecorder{}
+	db.SetObserver(rec)
+
+	var have []string
+	for r, err := range db.SelectSeq("words", Options{}, "word") {
+		if err != nil {
+			t.Fatal(err)
+		}
+		have = append(have, r.ScanStrings()[0])
+		if len(have) == 3 {
+			break
+		}
+	}
+	// the select is done, and it stopped early
+	if have, want := len(rec.calls), 1; have != want {
+		t.Fatalf("have %d calls, want %d", have, want)
+	}
+	if n := rec.calls[0].RowsExamined; n >= 1000 {
+		t.Errorf("examined %d rows", n)
+	}
+	var want []string
+	if err := db.SelectWith("words", Options{Limit: 3}, func(r Row) {
+		want = append(want, r.ScanStrings()[0])
+	}, "word"); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+
+	have = nil
+	for r, err := range db.IndexedSelectEqSeq("words", "words_index_2", Key{3}, Options{}, "word") {
+		if err != nil {
+			t.Fatal(err)
+		}
+		have = append(have, r.ScanStrings()[0])
+	}
+	want = nil
+	if err := db.IndexedSelectEq("words", "words_index_2", Key{3}, func(r Row) {
+		want = append(want, r.ScanStrings()[0])
+	}, "word"); err != nil {
+		t.Fatal(err)
+	}
+	if len(have) != 8 || !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+
+	n := 0
+	for _, err := range db.IndexedSelectSeq("words", "words_index_2", Options{Where: Le("length", 4)}, "word") {
+		if err != nil {
+			t.Fatal(err)
+		}
+		n++
+	}
+	if have, want := n, 40; have != want {
+		t.Errorf("have %d, want %d", have, want)
+	}
+
+	var errs []error
+	for r, err := range db.SelectSeq("nosuch", Options{}, "word") {
+		if r != nil {
+			t.Errorf("have %v", r)
+		}
+		errs = append(errs, err)
+	}
+	if have, want := errs, []error{errors.New(`no such table: "nosuch"`)}; !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	errs = nil
+	for _, err := range db.SelectSeqContext(ctx, "words", Options{}, "word") {
+		errs = append(errs, err)
+	}
+	if have, want := errs, []error{context.Canceled}; !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+}
+
+func TestAll(t *testing.T) {
+	db, err := Open("testdata/music.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var names []string
+	for track, err := range All[Track](db, "tracks", Options{Where: Eq("album", 2)}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, track.Name)
+	}
+	if have, want := names, []string{"Come Together", "Something", "Maxwells Silver Hammer"}; !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+
+	var ids []int64
+	for track, err := range All[*Track](db, "tracks", Options{OrderBy: []Order{Desc("length")}}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, track.ID)
+		if len(ids) == 2 {
+			break
+		}
+	}
+	if have, want := ids, []int64{4, 6}; !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+
+	for _, c := range []struct {
+		seq func(func(interface{}, error) bool)
+		err error
+	}{
+		{
+			seq: func(yield func(interface{}, error) bool) {
+				for v, err := range All[int](db, "tracks", Options{}) {
+					yield(v, err)
+				}
+			},
+			err: errors.New("not a struct: int"),
+		},
+		{
+			seq: func(yield func(interface{}, error) bool) {
+				for v, err := range All[Track](db, "albums", Options{}) {
+					yield(v, err)
+				}
+			},
+			err: errors.New(`field Length: no such column: "Length"`),
+		},
+		{
+			seq: func(yield func(interface{}, error) bool) {
+				for v, err := range All[struct{ Name int }](db, "tracks", Options{}) {
+					yield(v, err)
+				}
+			},
+			err: errors.New(`field Name: invalid number: "Drive My Car"`),
+		},
+		{
+			seq: func(yield func(interface{}, error) bool) {
+				for v, err := range All[Track](db, "tracks", Options{After: "abc"}) {
+					yield(v, err)
+				}
+			},
+			err: errors.New("After, Next, and SkipScan can't be used with Where"),
+		},
+	} {
+		var errs []error
+		for _, err := range c.seq {
+			errs = append(errs, err)
+		}
+		if have, want := errs, []error{c.err}; !reflect.DeepEqual(have, want) {
+			t.Errorf("have %v, want %v", have, want)
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package sqlittle
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+
+	sdb "github.com/hackborn/sqlittle/db"
+)
+
+// JoinSpec is one of the tables in a Join() or LeftJoin().
+type JoinSpec struct {
+	Table string
+	// Columns are the columns to return from this table. The rowid aliases
+	// can be used for rowid tables.
+	Columns []string
+	// Where is an optional filter on this table. For the right table of a
+	// LeftJoin() this works as part of the join condition: left rows without
+	// a matching right row are still returned.
+	Where Filter
+}
+
+// ColumnPair is a join condition: the Left column from the left table equals
+// the Right column from the right table.
+type ColumnPair struct {
+	Left, Right string
+}
+
+// Join returns every combination of rows from the left and the right table
+// where all the column pairs are equal. The row given to the callback has the
+// left columns followed by the right columns. Values are compared with the
+// collate function of the left column, which is what SQLite does for
+// `left.a = right.b`, and NULL never matches anything.
+//
+// Rows come in the order of the left table. If there is an index on the right
+// table which starts with all the right columns (or they are the rowid or the
+// primary key) every left row is looked up in that index. Otherwise the right
+// table is loaded in memory in a hash table.
+//
+//	db.Join(
+//	    JoinSpec{Table: "tracks", Columns: []string{"name"}},
+//	    JoinSpec{Table: "albums", Columns: []string{"name"}},
+//	    []ColumnPair{{"album", "id"}},
+//	    cb,
+//	)
+func (db *DB) Join(left, right JoinSpec, on []ColumnPair, cb RowCB) error {
+	return db.JoinContext(context.Background(), left, right, on, cb)
+}
+
+// JoinContext is Join() with a context. See SelectContext().
+func (db *DB) JoinContext(ctx context.Context, left, right JoinSpec, on []ColumnPair, cb RowCB) error {
+	return db.join(ctx, "Join", left, right, on, false, cb)
+}
+
+// LeftJoin is Join(), but it also returns rows from the left table without a
+// matching row in the right table. The right columns will be NULL for those
+// rows. Same as SQL's `LEFT OUTER JOIN`.
+func (db *DB) LeftJoin(left, right JoinSpec, on []ColumnPair, cb RowCB) error {
+	return db.LeftJoinContext(context.Background(), left, right, on, cb)
+}
+
+// LeftJoinContext is LeftJoin() with a context. See SelectContext().
+func (db *DB) LeftJoinContext(ctx context.Context, left, right JoinSpec, on []ColumnPair, cb RowCB) error {
+	return db.join(ctx, "LeftJoin", left, right, on, true, cb)
+}
+
+func (db *DB) join(ctx context.Context, method string, left, right JoinSpec, on []ColumnPair, outer bool, cb RowCB) (err error) {
+	d, c, err := db.rlock(ctx, method, left.Table, "")
+	if err != nil {
+		return err
+	}
+	defer c.end(&err)
+	cb = c.count(cb)
+
+	ls, err := d.Schema(left.Table)
+	if err != nil {
+		return err
+	}
+	rs, err := d.Schema(right.Table)
+	if err != nil {
+		return err
+	}
+
+	j := &joiner{
+		db:    d,
+		scan:  db.scanOpts,
+		right: rs,
+		nleft: len(left.Columns),
+		outer: outer,
+		cb:    cb,
+	}
+	leftColumns := append([]string(nil), left.Columns...)
+	for _, p := range on {
+		_, _, collate, err := lookupColumn(ls, p.Left)
+		if err != nil {
+			return err
+		}
+		c, err := resolveCond(rs, Cond{Column: p.Right, Op: OpEq}, db.scanOpts)
+		if err != nil {
+			return err
+		}
+		c.collate = collate
+		j.conds = append(j.conds, c)
+		leftColumns = append(leftColumns, p.Left)
+	}
+	if err := j.prepare(right); err != nil {
+		return err
+	}
+
+	e, all, err := newEmitter(ls, Options{Where: left.Where}, db.scanOpts, j.probe, leftColumns)
+	if err != nil {
+		return err
+	}
+	c.e = e
+	e.abort = &j.err // a failed lookup stops the left scan
+	if ls.WithoutRowid {
+		err = selectNonRowid(d, ls, e, all)
+	} else {
+		err = select_(d, ls, e, all)
+	}
+	if err != nil {
+		return err
+	}
+	return j.err
+}
+
+// joiner finds the right rows for every left row, either with index lookups
+// or with a hash table.
+type joiner struct {
+	db    *sdb.Database
+	scan  ScanOptions
+	right *sdb.Schema
+	nleft int    // number of requested left columns
+	conds []cond // == conditions for the right join columns; the values change for every left row
+	outer bool
+	cb    RowCB
+
+	// index lookups
+	plan    *plan
+	e       *emitter
+	columns []string // all right columns to load
+
+	// hash join
+	hash   map[string][]Row
+	nright int
+
+	// current left row
+	row     Row
+	matched bool
+	err     error
+}
+
+// prepare finds an access path on the right table, and if there is none it
+// loads the right table in a hash table.
+func (j *joiner) prepare(right JoinSpec) error {
+	j.nright = len(right.Columns)
+	j.plan = joinPlan(j.right, j.conds, right.Columns)
+	if j.plan != nil {
+		e, all, err := newEmitter(j.right, Options{Where: right.Where}, j.scan, j.match, right.Columns)
+		if err != nil {
+			return err
+		}
+		j.e, j.columns = e, all
+		return nil
+	}
+
+	columns := append([]string(nil), right.Columns...)
+	for _, c := range j.conds {
+		columns = append(columns, c.Column)
+	}
+	j.hash = map[string][]Row{}
+	e, all, err := newEmitter(j.right, Options{Where: right.Where}, j.scan, func(r Row) {
+		k, ok := hashKey(r[j.nright:], j.conds)
+		if !ok {
+			return
+		}
+		for i, v := range r {
+			if b, ok := v.([]byte); ok {
+				r[i] = append([]byte(nil), b...)
+			}
+		}
+		j.hash[k] = append(j.hash[k], r[:j.nright])
+	}, columns)
+	if err != nil {
+		return err
+	}
+	if j.right.WithoutRowid {
+		return selectNonRowid(j.db, j.right, e, all)
+	}
+	return select_(j.db, j.right, e, all)
+}
+
+// probe gets a left row, followed by the values of the left join columns.
+func (j *joiner) probe(r Row) {
+	if j.err != nil {
+		return
+	}
+	j.row, j.matched = r[:j.nleft], false
+	keys := r[j.nleft:]
+	if j.hash != nil {
+		if k, ok := hashKey(keys, j.conds); ok {
+			for _, rr := range j.hash[k] {
+				j.match(rr)
+			}
+		}
+	} else if j.setValues(keys) {
+		j.err = j.plan.run(j.db, j.e, j.columns)
+	}
+	if j.outer && !j.matched {
+		row := make(Row, j.nleft+j.nright)
+		copy(row, j.row)
+		j.cb(row)
+	}
+}
+
+// setValues puts the left values in the conditions of the plan. False if
+// there can't be a match.
+func (j *joiner) setValues(vs Row) bool {
+	for i, v := range vs {
+		if v == nil {
+			return false
+		}
+		j.conds[i].value = v
+	}
+	if j.plan.kind == planRowid {
+		if _, ok := rowidValue(j.conds[0].value, 0); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// match gets a matching right row.
+func (j *joiner) match(r Row) {
+	j.matched = true
+	row := make(Row, 0, len(j.row)+len(r))
+	row = append(row, j.row...)
+	j.cb(append(row, r...))
+}
+
+// joinPlan finds an access path which uses all join conditions: the rowid, the
+// primary key, or an index. Returns nil if there is none.
+func joinPlan(s *sdb.Schema, conds []cond, columns []string) *plan {
+	if !s.WithoutRowid && len(conds) == 1 && conds[0].rowid {
+		return &plan{kind: planRowid, schema: s, eq: []*cond{&conds[0]}, unique: true}
+	}
+
+	var best *plan
+	if s.WithoutRowid {
+		pk := &sdb.SchemaIndex{Columns: s.PK}
+		if p := indexPlan(s, pk, conds); p != nil && len(p.eq) == len(conds) {
+			p.pk = true
+			best = p
+		}
+	}
+	for i := range s.Indexes {
+		ind := &s.Indexes[i]
+		if ind.Where != nil {
+			continue
+		}
+		p := indexPlan(s, ind, conds)
+		if p == nil || len(p.eq) != len(conds) {
+			continue
+		}
+		p.covering = isCovering(s, ind, columns)
+		if best == nil || p.score() > best.score() {
+			best = p
+		}
+	}
+	return best
+}
+
+// hashKey makes a key from the join values. Values which compare equal give
+// the same key. False if any value is NULL.
+func hashKey(vs Row, conds []cond) (string, bool) {
+	var b strings.Builder
+	for i, v := range vs {
+		if v == nil {
+			return "", false
+		}
+		writeKey(&b, v, conds[i].collate)
+	}
+	return b.String(), true
+}
+
+// writeKey adds a value to a hash key.
+func writeKey(b *strings.Builder, v interface{}, collate string) {
+	switch v := v.(type) {
+	case nil:
+		b.WriteString("n")
+	case int64:
+		b.WriteString("i" + strconv.FormatInt(v, 10))
+	case float64:
+		if v == math.Trunc(v) && v >= math.MinInt64 && v < math.MaxInt64 {
+			b.WriteString("i" + strconv.FormatInt(int64(v), 10))
+		} else {
+			b.WriteString("f" + strconv.FormatFloat(v, 'g', -1, 64))
+		}
+	case string:
+		s := collateKey(collate, v)
+		b.WriteString("s" + strconv.Itoa(len(s)) + ":" + s)
+	case []byte:
+		b.WriteString("b" + strconv.Itoa(len(v)) + ":")
+		b.Write(v)
+	}
+	b.WriteByte(0)
+}
+
+// collateKey normalizes a string so that strings which are equal according to
+// the collate function are the same.
+func collateKey(collate, s string) string {
+	switch collate {
+	case "nocase":
+		return strings.Map(lowerASCII, s)
+	case "rtrim":
+		return strings.TrimRight(s, " \t\r\n")
+	default:
+		return s
+	}
+}
@@ -0,0 +1,237 @@
+package sqlittle
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/andreyvit/diff"
+	"github.com/davecgh/go-spew/spew"
+	sdb "github.com/hackborn/sqlittle/db"
+)
+
+func TestJoin(t *testing.T) {
+	db, err := Open("testdata/music.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var rows [][]string
+	cb := func(r Row) {
+		rows = append(rows, r.ScanStrings())
+	}
+
+	// albums.id is the rowid
+	if err := db.Join(
+		JoinSpec{Table: "tracks", Columns: []string{"name"}},
+		JoinSpec{Table: "albums", Columns: []string{"name"}},
+		[]ColumnPair{{"album", "id"}},
+		cb,
+	); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"Drive My Car", "Rubber Soul"},
+		{"Norwegian Wood", "Rubber Soul"},
+		{"You Wont See Me", "Rubber Soul"},
+		{"Come Together", "Abbey Road"},
+		{"Something", "Abbey Road"},
+		{"Maxwells Silver Hammer", "Abbey Road"},
+	}
+	if have, want := rows, want; !reflect.DeepEqual(have, want) {
+		t.Errorf("diff:\n%s", diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+	}
+
+	// no index on tracks.album: hash join
+	rows = nil
+	if err := db.Join(
+		JoinSpec{Table: "albums", Columns: []string{"name"}, Where: Eq("name", "Abbey Road")},
+		JoinSpec{Table: "tracks", Columns: []string{"id", "name"}, Where: Gt("length", 200)},
+		[]ColumnPair{{"id", "album"}},
+		cb,
+	); err != nil {
+		t.Fatal(err)
+	}
+	want = [][]string{
+		{"Abbey Road", "4", "Come Together"},
+		{"Abbey Road", "6", "Maxwells Silver Hammer"},
+	}
+	if have, want := rows, want; !reflect.DeepEqual(have, want) {
+		t.Errorf("diff:\n%s", diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+	}
+
+	// primary key of a WITHOUT ROWID table
+	rows = nil
+	if err := db.LeftJoin(
+		JoinSpec{Table: "albums", Columns: []string{"rowid", "name"}},
+		JoinSpec{Table: "tracks", Columns: []string{"name"}},
+		[]ColumnPair{{"rowid", "id"}},
+		cb,
+	); err != nil {
+		t.Fatal(err)
+	}
+	want = [][]string{
+		{"1", "Rubber Soul", "Drive My Car"},
+		{"2", "Abbey Road", "Norwegian Wood"},
+	}
+	if have, want := rows, want; !reflect.DeepEqual(have, want) {
+		t.Errorf("diff:\n%s", diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+	}
+
+	// left join without matches
+	rows = nil
+	if err := db.LeftJoin(
+		JoinSpec{Table: "albums", Columns: []string{"name"}},
+		JoinSpec{Table: "artists", Columns: []string{"name"}, Where: Eq("name", "The Rolling Stones")},
+		[]ColumnPair{{"artist", "id"}},
+		cb,
+	); err != nil {
+		t.Fatal(err)
+	}
+	want = [][]string{
+		{"Rubber Soul", ""},
+		{"Abbey Road", ""},
+	}
+	if have, want := rows, want; !reflect.DeepEqual(have, want) {
+		t.Errorf("diff:\n%s", diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+	}
+
+	cb = func(r Row) {}
+	if have, want := db.Join(
+		JoinSpec{Table: "albums"},
+		JoinSpec{Table: "tracks"},
+		[]ColumnPair{{"nosuch", "id"}},
+		cb,
+	), errors.New(`no such column: "nosuch"`); !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+	if have, want := db.Join(
+		JoinSpec{Table: "albums"},
+		JoinSpec{Table: "tracks"},
+		[]ColumnPair{{"id", "rowid"}},
+		cb,
+	), errors.New(`no such column: "rowid"`); !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+}
+
+func TestJoinIndex(t *testing.T) {
+	db, err := Open("testdata/words.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var rows [][]string
+	cb := func(r Row) {
+		rows = append(rows, r.ScanStrings())
+	}
+	if err := db.Join(
+		JoinSpec{Table: "words", Columns: []string{"word"}, Where: Eq("length", 18)},
+		JoinSpec{Table: "words", Columns: []string{"length"}},
+		[]ColumnPair{{"word", "word"}},
+		cb,
+	); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"bloodthirstiness's", "18"},
+		{"internationalism's", "18"},
+	}
+	if have, want := rows, want; !reflect.DeepEqual(have, want) {
+		t.Errorf("diff:\n%s", diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+	}
+
+	n := 0
+	if err := db.Join(
+		JoinSpec{Table: "words", Where: Like("word", "b%")},
+		JoinSpec{Table: "words"},
+		[]ColumnPair{{"length", "length"}},
+		func(r Row) { n++ },
+	); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := n, 8131; have != want {
+		t.Errorf("have %d, want %d", have, want)
+	}
+}
+
+func TestJoinPlan(t *testing.T) {
+	db, err := Open("testdata/words.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	s, err := db.db.Schema("words")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		columns []string
+		want    string
+	}{
+		{[]string{"word"}, "SEARCH words USING COVERING INDEX words_index_1 (word=?)"},
+		{[]string{"length"}, "SEARCH words USING COVERING INDEX words_index_2 (length=?)"},
+		{[]string{"word", "length"}, "SEARCH words USING COVERING INDEX words_index_2 (length=? AND word=?)"},
+		{[]string{"rowid"}, "SEARCH words USING INTEGER PRIMARY KEY (rowid=?)"},
+		{[]string{"rowid", "word"}, ""},
+	} {
+		var conds []cond
+		for _, col := range c.columns {
+			rc, err := resolveCond(s, Cond{Column: col, Op: OpEq}, ScanOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			conds = append(conds, rc)
+		}
+		p := joinPlan(s, conds, []string{"word"})
+		have := ""
+		if p != nil {
+			have = p.String()
+		}
+		if want := c.want; have != want {
+			t.Errorf("%v: have %q, want %q", c.columns, have, want)
+		}
+	}
+}
+
+func TestJoinAbort(t *testing.T) {
+	db, err := Open("testdata/words.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	s, err := db.db.Schema("words")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// every lookup on the right fails
+	rs := *s
+	rs.Table = "nosuch"
+	rc, err := resolveCond(&rs, Cond{Column: "rowid", Op: OpEq}, ScanOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	j := &joiner{db: db.db, right: &rs, nleft: 1, conds: []cond{rc}, cb: func(Row) {}}
+	if err := j.prepare(JoinSpec{Columns: []string{"word"}}); err != nil {
+		t.Fatal(err)
+	}
+	e, all, err := newEmitter(s, Options{}, ScanOptions{}, j.probe, []string{"word", "rowid"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.abort = &j.err
+	if err := select_(db.db, s, e, all); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := j.err, sdb.ErrNoSuchTable; have != want {
+		t.Errorf("have %v, want %v", have, want)
+	}
+	if have, want := e.examined, 1; have != want {
+		t.Errorf("have %d, want %d", have, want)
+	}
+}
@@ -2,7 +2,9 @@ package sqlittle
 
 import (
 	"fmt"
+	"math"
 	"strings"
+	"time"
 
 	sdb "github.com/hackborn/sqlittle/db"
 	"github.com/hackborn/sqlittle/sql"
@@ -13,6 +15,12 @@ import (
 // It accepts most Go datatypes, but they will be converted to the set SQLite
 // supports:
 // nil, int64, float64, string, []byte
+//
+// Values are converted with the affinity of their column, as SQLite does when
+// it compares a column with a value: the string "42" finds the integer 42 in
+// an INTEGER column. time.Time, uint64, and fmt.Stringer values are accepted
+// as well. A time.Time is converted the way Scan() reads it back, with the
+// options from DB.SetScanOptions().
 type Key []interface{}
 
 // asDbKey translates a Key to a db.Key. Applies DESC and collate, and changes
@@ -31,38 +39,109 @@ func asDbKey(k Key, cols []sdb.IndexColumn) (sdb.Key, error) {
 			}
 			dbk[i].Collate = collate
 		}
-		switch kv := kv.(type) {
-		case nil:
-			dbk[i].V = nil
-		case int64:
-			dbk[i].V = kv
-		case float64:
-			dbk[i].V = kv
-		case string:
-			dbk[i].V = kv
-		case []byte:
-			dbk[i].V = kv
+		v, err := toDbValue(kv)
+		if err != nil {
+			return nil, err
+		}
+		dbk[i].V = v
+	}
+	return dbk, nil
+}
 
-		case int:
-			dbk[i].V = int64(kv)
-		case uint:
-			dbk[i].V = int64(kv)
-		case int32:
-			dbk[i].V = int64(kv)
-		case uint32:
-			dbk[i].V = int64(kv)
-		case float32:
-			dbk[i].V = float64(kv)
-		case bool:
-			v := int64(0)
-			if kv {
-				v = 1
-			}
-			dbk[i].V = v
+// toDbValue changes a Go value to one of the few datatypes the database uses:
+// nil, int64, float64, string, []byte
+//
+// A time.Time becomes text in UTC, in the format SQLite's CURRENT_TIMESTAMP
+// uses, with milliseconds if it has a fraction of a second. A fmt.Stringer
+// becomes its String().
+func toDbValue(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case nil, int64, float64, string, []byte:
+		return v, nil
+	case int:
+		return int64(v), nil
+	case uint:
+		return toDbValue(uint64(v))
+	case int8:
+		return int64(v), nil
+	case uint8:
+		return int64(v), nil
+	case int16:
+		return int64(v), nil
+	case uint16:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case uint32:
+		return int64(v), nil
+	case uint64:
+		if v > math.MaxInt64 {
+			return nil, fmt.Errorf("Key value out of range: %d", v)
+		}
+		return int64(v), nil
+	case float32:
+		return float64(v), nil
+	case bool:
+		if v {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case time.Time:
+		return timeText(v.UTC()), nil
+	case fmt.Stringer:
+		return v.String(), nil
+	default:
+		return nil, fmt.Errorf("unknown Key datatype: %T", v)
+	}
+}
+
+// timeText formats a time the way SQLite's CURRENT_TIMESTAMP does, with
+// milliseconds if it has a fraction of a second.
+func timeText(t time.Time) string {
+	if t.Nanosecond() != 0 {
+		return t.Format("2006-01-02 15:04:05.000")
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
 
+// toKeyValue is toDbValue() for a value which is compared with a column with
+// affinity a. The value is converted the way SQLite converts it before a
+// comparison: text becomes a number for INTEGER, REAL, and NUMERIC columns,
+// and a number becomes text for TEXT columns. A time.Time is stored the way
+// Scan() with the options o reads it back: the Epoch unit for INTEGER
+// columns, a julian day for REAL columns, and text in the Location otherwise.
+func toKeyValue(v interface{}, a affinity, o ScanOptions) (interface{}, error) {
+	if t, ok := v.(time.Time); ok {
+		switch a {
+		case affInteger:
+			return toEpoch(t, o.epoch()), nil
+		case affReal:
+			return toJulian(t), nil
 		default:
-			return nil, fmt.Errorf("unknown Key datatype: %T", kv)
+			return a.compare(timeText(t.In(o.location()))), nil
 		}
 	}
-	return dbk, nil
+	dv, err := toDbValue(v)
+	if err != nil {
+		return nil, err
+	}
+	return a.compare(dv), nil
+}
+
+// keyValues converts the values of a Key given to an index or a primary key
+// with toKeyValue(), using the affinity of the columns.
+func keyValues(s *sdb.Schema, cols []sdb.IndexColumn, k Key, o ScanOptions) (Key, error) {
+	res := make(Key, len(k))
+	for i, v := range k {
+		a := affBlob
+		if i < len(cols) && cols[i].Expression == "" {
+			a = columnAffinity(s, cols[i].Column)
+		}
+		dv, err := toKeyValue(v, a, o)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = dv
+	}
+	return res, nil
 }
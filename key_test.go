@@ -4,6 +4,7 @@ import (
 	"errors"
 	"reflect"
 	"testing"
+	"time"
 
 	sdb "github.com/hackborn/sqlittle/db"
 	"github.com/hackborn/sqlittle/sql"
@@ -58,3 +59,164 @@ func TestKeys(t *testing.T) {
 	)
 	test(Key{1, 2, 3}, twoCols, nil, errors.New("too many columns in Key"))
 }
+
+type color int
+
+func (c color) String() string { return [...]string{"red", "green"}[c] }
+
+func TestToDbValue(t *testing.T) {
+	for _, c := range []struct {
+		v    interface{}
+		want interface{}
+	}{
+		{int8(-3), int64(-3)},
+		{uint16(3), int64(3)},
+		{uint64(1<<63 - 1), int64(1<<63 - 1)},
+		{time.Date(2018, 3, 4, 12, 34, 56, 0, time.UTC), "2018-03-04 12:34:56"},
+		{time.Date(2018, 3, 4, 13, 34, 56, 789000000, time.FixedZone("", 3600)), "2018-03-04 12:34:56.789"},
+		{color(1), "green"},
+	} {
+		have, err := toDbValue(c.v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(have, c.want) {
+			t.Errorf("%v: have %#v, want %#v", c.v, have, c.want)
+		}
+	}
+
+	if _, err := toDbValue(uint64(1 << 63)); !reflect.DeepEqual(err, errors.New("Key value out of range: 9223372036854775808")) {
+		t.Errorf("have %v", err)
+	}
+	if _, err := toDbValue(struct{}{}); !reflect.DeepEqual(err, errors.New("unknown Key datatype: struct {}")) {
+		t.Errorf("have %v", err)
+	}
+}
+
+func TestToKeyValue(t *testing.T) {
+	tm := time.Date(2018, 3, 4, 12, 0, 0, 0, time.UTC)
+	for _, c := range []struct {
+		v    interface{}
+		aff  affinity
+		want interface{}
+	}{
+		{"42", affInteger, int64(42)},
+		{"4.0", affNumeric, int64(4)},
+		{"4.5", affReal, 4.5},
+		{3, affReal, int64(3)},
+		{"abc", affInteger, "abc"},
+		{42, affText, "42"},
+		{2.5, affText, "2.5"},
+		{42, affBlob, int64(42)},
+		{"42", affBlob, "42"},
+		{[]byte("42"), affInteger, []byte("42")},
+		{tm, affInteger, int64(1520164800)},
+		{tm, affReal, 2458182.0},
+		{tm, affNumeric, "2018-03-04 12:00:00"},
+		{color(0), affText, "red"},
+	} {
+		have, err := toKeyValue(c.v, c.aff, ScanOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(have, c.want) {
+			t.Errorf("%v (%d): have %#v, want %#v", c.v, c.aff, have, c.want)
+		}
+	}
+
+	loc := time.FixedZone("CET", 3600)
+	o := ScanOptions{Epoch: time.Millisecond, Location: loc}
+	for _, c := range []struct {
+		aff  affinity
+		want interface{}
+	}{
+		{affInteger, int64(1520164800250)},
+		{affReal, 2458182.0000028936},
+		{affText, "2018-03-04 13:00:00.250"},
+	} {
+		have, err := toKeyValue(tm.Add(250*time.Millisecond), c.aff, o)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(have, c.want) {
+			t.Errorf("%d: have %#v, want %#v", c.aff, have, c.want)
+		}
+	}
+}
+
+func TestKeyAffinity(t *testing.T) {
+	db, err := Open("testdata/words.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	count := func(f func(RowCB) error) int {
+		t.Helper()
+		n := 0
+		if err := f(func(Row) { n++ }); err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+	for name, c := range map[string]struct {
+		f    func(RowCB) error
+		want int
+	}{
+		"eq text": {func(cb RowCB) error {
+			return db.IndexedSelectEq("words", "words_index_2", Key{"3"}, cb, "word")
+		}, 8},
+		"eq real": {func(cb RowCB) error {
+			return db.IndexedSelectEq("words", "words_index_2", Key{3.0, "big"}, cb, "word")
+		}, 1},
+		"skipscan": {func(cb RowCB) error {
+			return db.IndexedSelectEqWith("words", "words_index_2", Key{"big"}, Options{SkipScan: 1}, cb, "word")
+		}, 1},
+		"where": {func(cb RowCB) error {
+			return db.Where("words", []Cond{Eq("length", " 3 ")}, cb, "word")
+		}, 8},
+		"rowid": {func(cb RowCB) error {
+			return db.Where("words", []Cond{Ge("rowid", "998")}, cb, "word")
+		}, 3},
+		"in": {func(cb RowCB) error {
+			return db.SelectWith("words", Options{Where: In("length", "3", 4.0)}, cb, "word")
+		}, 39},
+		"stringer": {func(cb RowCB) error {
+			return db.IndexedSelectEq("words", "words_index_1", Key{word("big")}, cb, "word")
+		}, 1},
+	} {
+		if have := count(c.f); have != c.want {
+			t.Errorf("%s: have %d, want %d", name, have, c.want)
+		}
+	}
+
+	music, err := Open("testdata/music.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer music.Close()
+	for _, c := range []struct {
+		table string
+		key   Key
+		want  string
+	}{
+		{"albums", Key{2}, "Abbey Road"},   // rowid
+		{"albums", Key{"2"}, "Abbey Road"}, // rowid
+		{"tracks", Key{"4"}, "Come Together"},
+		{"tracks", Key{uint64(5)}, "Something"},
+	} {
+		var have string
+		if err := music.PKSelect(c.table, c.key, func(r Row) {
+			r.Scan(&have)
+		}, "name"); err != nil {
+			t.Fatal(err)
+		}
+		if have != c.want {
+			t.Errorf("%s %v: have %q, want %q", c.table, c.key, have, c.want)
+		}
+	}
+}
+
+type word string
+
+func (w word) String() string { return string(w) }
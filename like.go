@@ -0,0 +1,116 @@
+package sqlittle
+
+// LIKE and GLOB pattern matching, as SQLite's patternCompare() does it.
+
+// matchLike matches a LIKE pattern. Only ASCII letters are case insensitive.
+func matchLike(pattern, s []rune) bool {
+	return matchPattern(pattern, s, '%', '_', false, true)
+}
+
+// matchGlob matches a GLOB pattern.
+func matchGlob(pattern, s []rune) bool {
+	return matchPattern(pattern, s, '*', '?', true, false)
+}
+
+// all matches any sequence, one matches a single character. If set is true
+// `[...]` is a character set.
+func matchPattern(p, s []rune, all, one rune, set, nocase bool) bool {
+	for len(p) > 0 {
+		c := p[0]
+		p = p[1:]
+		switch {
+		case c == all:
+			// any more wildcards can be handled right away
+			for len(p) > 0 && (p[0] == all || p[0] == one) {
+				if p[0] == one {
+					if len(s) == 0 {
+						return false
+					}
+					s = s[1:]
+				}
+				p = p[1:]
+			}
+			if len(p) == 0 {
+				return true
+			}
+			for i := range s {
+				if matchPattern(p, s[i:], all, one, set, nocase) {
+					return true
+				}
+			}
+			return false
+		case c == one:
+			if len(s) == 0 {
+				return false
+			}
+			s = s[1:]
+		case set && c == '[':
+			if len(s) == 0 {
+				return false
+			}
+			ok, rest := matchSet(p, s[0])
+			if !ok {
+				return false
+			}
+			p, s = rest, s[1:]
+		default:
+			if len(s) == 0 || !sameRune(c, s[0], nocase) {
+				return false
+			}
+			s = s[1:]
+		}
+	}
+	return len(s) == 0
+}
+
+// matchSet matches c against a `[...]` set. p starts just after the `[`. It
+// returns the pattern after the closing `]`. A set without a closing `]` never
+// matches.
+func matchSet(p []rune, c rune) (bool, []rune) {
+	var (
+		seen, invert bool
+		prior        rune
+		i            int
+	)
+	if i < len(p) && p[i] == '^' {
+		invert = true
+		i++
+	}
+	if i < len(p) && p[i] == ']' {
+		// a leading ']' is part of the set
+		seen = c == ']'
+		i++
+	}
+	for ; i < len(p) && p[i] != ']'; i++ {
+		if p[i] == '-' && prior > 0 && i+1 < len(p) && p[i+1] != ']' {
+			i++
+			if c >= prior && c <= p[i] {
+				seen = true
+			}
+			prior = 0
+			continue
+		}
+		if c == p[i] {
+			seen = true
+		}
+		prior = p[i]
+	}
+	if i >= len(p) {
+		return false, nil
+	}
+	return seen != invert, p[i+1:]
+}
+
+func sameRune(a, b rune, nocase bool) bool {
+	if nocase {
+		return lowerASCII(a) == lowerASCII(b)
+	}
+	return a == b
+}
+
+func lowerASCII(r rune) rune {
+	if r >= 'A' && r <= 'Z' {
+		return r + 'a' - 'A'
+	}
+	return r
+}
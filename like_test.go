@@ -0,0 +1,75 @@
+package sqlittle
+
+import (
+	"testing"
+)
+
+func TestLikeGlob(t *testing.T) {
+	// expectations from sqlite3
+	for _, c := range []struct {
+		op      string
+		s       string
+		pattern string
+		want    bool
+	}{
+		{"like", "abc", "abc", true},
+		{"like", "abc", "ABC", true},
+		{"like", "abc", "a%", true},
+		{"like", "abc", "%c", true},
+		{"like", "abc", "%b%", true},
+		{"like", "abc", "a_c", true},
+		{"like", "abc", "a__c", false},
+		{"like", "abc", "%", true},
+		{"like", "", "%", true},
+		{"like", "", "_", false},
+		{"like", "aXb", "a%%b", true},
+		{"like", "é", "É", false},
+		{"like", "é", "_", true},
+		{"like", "a%c", "a%c", true},
+		{"like", "abc", "%%_c", true},
+		{"like", "ab", "a%_b", false},
+		{"glob", "abc", "abc", true},
+		{"glob", "abc", "ABC", false},
+		{"glob", "abc", "a*", true},
+		{"glob", "abc", "*c", true},
+		{"glob", "abc", "a?c", true},
+		{"glob", "abc", "[a-c]bc", true},
+		{"glob", "abc", "[^a]bc", false},
+		{"glob", "abc", "[]a]bc", true},
+		{"glob", "]bc", "[]a]bc", true},
+		{"glob", "-bc", "[a-]bc", true},
+		{"glob", "abc", "[abc", false},
+		{"glob", "abc", "*[bx]*", true},
+		{"glob", "a^c", "a[^^]c", false},
+		{"glob", "a^c", "a[x^]c", true},
+		{"glob", "b", "[a-c-e]", true},
+		{"glob", "d", "[a-c-e]", false},
+		{"glob", "-", "[a-c-e]", true},
+		{"glob", "a", "[]", false},
+		{"glob", "]", "[]]", true},
+	} {
+		match := matchLike
+		if c.op == "glob" {
+			match = matchGlob
+		}
+		if have, want := match([]rune(c.pattern), []rune(c.s)), c.want; have != want {
+			t.Errorf("%q %s %q: have %t, want %t", c.s, c.op, c.pattern, have, want)
+		}
+	}
+}
+
+func TestRealText(t *testing.T) {
+	for f, want := range map[float64]string{
+		3:                    "3.0",
+		0.1:                  "0.1",
+		-3.14:                "-3.14",
+		1e20:                 "1.0e+20",
+		123456789012345678.0: "1.23456789012346e+17",
+		1.0 / 3:              "0.333333333333333",
+		0.00001:              "1.0e-05",
+	} {
+		if have := realText(f); have != want {
+			t.Errorf("%v: have %q, want %q", f, have, want)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package sqlittle
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strings"
+
+	sdb "github.com/hackborn/sqlittle/db"
+)
+
+// NamedRowCB is the callback for Options.Named.
+type NamedRowCB func(NamedRow)
+
+// namedColumns are the names and declared types of the columns of a select.
+// They're the same for every row.
+type namedColumns struct {
+	names []string
+	decl  []string
+}
+
+// newNamedColumns gives the column metadata for the selected columns, using
+// the same column lookup as the select itself.
+func newNamedColumns(s *sdb.Schema, columns []string) (*namedColumns, error) {
+	var (
+		cis []columnIndex
+		err error
+	)
+	if s.WithoutRowid {
+		cis, err = toColumnIndexNonRowid(s, columns)
+	} else {
+		cis, err = toColumnIndexRowid(s, columns)
+	}
+	if err != nil {
+		return nil, err
+	}
+	nc := &namedColumns{
+		names: append([]string(nil), columns...),
+		decl:  make([]string, len(cis)),
+	}
+	for i, c := range cis {
+		switch {
+		case c.col != nil:
+			nc.decl[i] = c.col.Type
+		case c.rowIndex >= 0:
+			// INTEGER PRIMARY KEY column
+			nc.decl[i] = s.Columns[c.rowIndex].Type
+		default:
+			// "rowid", "oid", or "_rowid_"
+			nc.decl[i] = "INTEGER"
+		}
+	}
+	return nc, nil
+}
+
+// wrap gives a callback which calls cb (if not nil), and then named.
+func (nc *namedColumns) wrap(cb RowCB, named NamedRowCB) RowCB {
+	return func(r Row) {
+		if cb != nil {
+			cb(r)
+		}
+		named(NamedRow{Row: r, cols: nc})
+	}
+}
+
+// NamedRow is a Row which knows the names and the declared types of its
+// columns. Use Options.Named to get them.
+//
+// The same as with Row, the values are only valid during the callback. Use
+// Scan(), or copy them.
+type NamedRow struct {
+	Row
+	cols *namedColumns
+}
+
+// Columns gives the column names, as they were given to the select.
+func (r NamedRow) Columns() []string {
+	return r.cols.names
+}
+
+// Get gives the value of a column. Column names are compared
+// case-insensitive, as in SQLite. If the column was selected more than once
+// the first one is used. It gives nil if the column isn't in the row, which
+// is the same as a NULL value; use Columns() to tell them apart.
+func (r NamedRow) Get(name string) interface{} {
+	for i, c := range r.cols.names {
+		if strings.EqualFold(c, name) {
+			return r.Row[i]
+		}
+	}
+	return nil
+}
+
+// Type gives the storage class of the value in column i, the same as SQLite's
+// typeof(): "null", "integer", "real", "text", or "blob".
+func (r NamedRow) Type(i int) string {
+	switch r.Row[i].(type) {
+	case nil:
+		return "null"
+	case int64:
+		return "integer"
+	case float64:
+		return "real"
+	case string:
+		return "text"
+	case []byte:
+		return "blob"
+	default:
+		panic("impossible")
+	}
+}
+
+// DeclType gives the type of column i as it was declared in the CREATE TABLE,
+// such as "VARCHAR(255)", or "" if the column has no type. The rowid is an
+// "INTEGER".
+func (r NamedRow) DeclType(i int) string {
+	return r.cols.decl[i]
+}
+
+// Map gives the row as a map from column name to value. If a column was
+// selected more than once the first one is used. The values are not copied.
+func (r NamedRow) Map() map[string]interface{} {
+	m := make(map[string]interface{}, len(r.Row))
+	for i, c := range r.cols.names {
+		if _, ok := m[c]; !ok {
+			m[c] = r.Row[i]
+		}
+	}
+	return m
+}
+
+// MarshalJSON gives the row as a JSON object, with the columns in order. BLOBs
+// are base64 encoded, the same as []byte in encoding/json, and infinite reals
+// are 9e999 and -9e999, the same as in SQLite's JSON functions.
+func (r NamedRow) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, c := range r.cols.names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		k, err := json.Marshal(c)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(k)
+		b.WriteByte(':')
+		switch v := r.Row[i].(type) {
+		case float64:
+			if math.IsInf(v, 1) {
+				b.WriteString("9e999")
+				continue
+			}
+			if math.IsInf(v, -1) {
+				b.WriteString("-9e999")
+				continue
+			}
+		}
+		v, err := json.Marshal(r.Row[i])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
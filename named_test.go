@@ -0,0 +1,116 @@
+package sqlittle
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestNamedRow(t *testing.T) {
+	db, err := Open("testdata/music.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	rec := &callRecorder{}
+	db.SetObserver(rec)
+
+	var rows []NamedRow
+	opts := Options{
+		Where:   Eq("album", 2),
+		OrderBy: []Order{Desc("ID")},
+		Named: func(r NamedRow) {
+			r.Row = copyRow(r.Row)
+			rows = append(rows, r)
+		},
+	}
+	if err := db.SelectWith("tracks", opts, nil, "ID", "name", "length", "album"); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := len(rows), 3; have != want {
+		t.Fatalf("have %d, want %d", have, want)
+	}
+	if have, want := rec.calls[0].Rows, 3; have != want {
+		t.Errorf("observer: have %d rows, want %d", have, want)
+	}
+	r := rows[0]
+	if have, want := r.Columns(), []string{"ID", "name", "length", "album"}; !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+	if have, want := r.Get("NAME"), interface{}("Maxwells Silver Hammer"); have != want {
+		t.Errorf("have %v, want %v", have, want)
+	}
+	if have := r.Get("nosuch"); have != nil {
+		t.Errorf("have %v", have)
+	}
+	var types, decls []string
+	for i := range r.Row {
+		types = append(types, r.Type(i))
+		decls = append(decls, r.DeclType(i))
+	}
+	if have, want := types, []string{"integer", "text", "integer", "integer"}; !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+	if have, want := decls, []string{"integer", "", "", "integer"}; !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+	want := map[string]interface{}{
+		"ID":     int64(6),
+		"name":   "Maxwells Silver Hammer",
+		"length": int64(207),
+		"album":  int64(2),
+	}
+	if have := r.Map(); !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+	js, err := r.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := string(js), `{"ID":6,"name":"Maxwells Silver Hammer","length":207,"album":2}`; have != want {
+		t.Errorf("have %s, want %s", have, want)
+	}
+
+	// rowid, and the callback is also called
+	var (
+		plain []string
+		named []string
+	)
+	opts = Options{
+		Named: func(r NamedRow) {
+			named = append(named, r.DeclType(0)+" "+r.DeclType(1)+" "+r.Type(2))
+		},
+	}
+	if err := db.IndexedSelectWith("albums", "albums_name", opts, func(r Row) {
+		plain = append(plain, r.ScanStrings()[2])
+	}, "id", "rowid", "name"); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := plain, []string{"Abbey Road", "Rubber Soul"}; !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+	if have, want := named, []string{"integer INTEGER text", "integer INTEGER text"}; !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+
+	if have, want := db.SelectWith("tracks", opts, nil, "nosuch"), errors.New(`no such column: "nosuch"`); !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+}
+
+func TestNamedRowJSON(t *testing.T) {
+	nc := &namedColumns{names: []string{"a", "b", "c", "d", "e", "f"}}
+	r := NamedRow{
+		Row:  Row{nil, 1.5, math.Inf(-1), "x", []byte("hi"), math.Inf(1)},
+		cols: nc,
+	}
+	js, err := r.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := string(js), `{"a":null,"b":1.5,"c":-9e999,"d":"x","e":"aGk=","f":9e999}`; have != want {
+		t.Errorf("have %s, want %s", have, want)
+	}
+}
@@ -0,0 +1,126 @@
+package sqlittle
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	sdb "github.com/hackborn/sqlittle/db"
+)
+
+// Observer gets events from the database, for metrics and tracing. See the
+// db package for the events, and for the ready made Counters and
+// SlogObserver observers.
+type Observer = sdb.Observer
+
+// SetObserver sets the observer which gets all events from the database. The
+// selects in this package also send an EventCall with a summary of every
+// call. nil disables it. Set it before the database is used.
+func (db *DB) SetObserver(o Observer) {
+	db.db.SetObserver(o)
+}
+
+// call keeps track of a single high level call, for the summary.
+type call struct {
+	db    *DB
+	obs   *callObserver // nil without an observer
+	start time.Time
+	sum   sdb.CallSummary
+	e     *emitter     // can be nil
+	rows  int64        // atomic, SelectParallel counts from many goroutines
+	scan  *scanOptions // nil for the zero ScanOptions
+}
+
+// rlock read locks the database, unless the context is already done. It gives
+// the database to read from until call.end(), which stops when the context is
+// done.
+func (db *DB) rlock(ctx context.Context, method, table, index string) (*sdb.Database, *call, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	if err := db.db.RLock(); err != nil {
+		return nil, nil, err
+	}
+	d := db.db.WithContext(ctx)
+	c := &call{db: db}
+	if db.scanOpts != (ScanOptions{}) {
+		c.scan = &scanOptions{db.scanOpts}
+	}
+	if o := d.Observer(); o != nil {
+		c.obs = &callObserver{next: o}
+		c.start = time.Now()
+		c.sum = sdb.CallSummary{Method: method, Table: table, Index: index}
+		d = d.WithObserver(c.obs)
+	}
+	return d, c, nil
+}
+
+// count wraps the callback to count the rows it gets, and to give the rows the
+// scan options of the database.
+func (c *call) count(cb RowCB) RowCB {
+	if c.obs == nil && c.scan == nil {
+		return cb
+	}
+	return func(r Row) {
+		atomic.AddInt64(&c.rows, 1)
+		// in place for rows from toRow(), so Options.Named gets them too
+		r = c.withOptions(r)
+		if cb != nil {
+			cb(r)
+		}
+	}
+}
+
+// withOptions gives the row the scan options of the database, if they're not
+// the zero ScanOptions.
+func (c *call) withOptions(r Row) Row {
+	if c.scan == nil {
+		return r
+	}
+	return r.withOptions(c.scan)
+}
+
+// end unlocks the database, and sends the summary to the observer.
+func (c *call) end(err *error) {
+	c.db.db.RUnlock()
+	if c.obs == nil {
+		return
+	}
+	c.sum.PagesRead = int(atomic.LoadInt64(&c.obs.pagesRead))
+	c.sum.PagesCached = int(atomic.LoadInt64(&c.obs.pagesCached))
+	c.sum.OverflowPages = int(atomic.LoadInt64(&c.obs.overflow))
+	c.sum.Rows = int(atomic.LoadInt64(&c.rows))
+	c.sum.RowsExamined = c.sum.Rows
+	if c.e != nil {
+		c.sum.RowsExamined = c.e.examined
+	}
+	c.obs.next.Observe(sdb.Event{
+		Type:     sdb.EventCall,
+		Duration: time.Since(c.start),
+		Err:      *err,
+		Call:     &c.sum,
+	})
+}
+
+// callObserver counts the pages read for a single call, and passes all events
+// on.
+type callObserver struct {
+	next        sdb.Observer
+	pagesRead   int64
+	pagesCached int64
+	overflow    int64
+}
+
+func (o *callObserver) Observe(e sdb.Event) {
+	switch e.Type {
+	case sdb.EventPageRead:
+		if e.Cached {
+			atomic.AddInt64(&o.pagesCached, 1)
+		} else {
+			atomic.AddInt64(&o.pagesRead, 1)
+		}
+	case sdb.EventOverflowRead:
+		atomic.AddInt64(&o.overflow, 1)
+	}
+	o.next.Observe(e)
+}
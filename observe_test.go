@@ -0,0 +1,153 @@
+package sqlittle
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/andreyvit/diff"
+	"github.com/davecgh/go-spew/spew"
+	sdb "github.com/hackborn/sqlittle/db"
+)
+
+type callRecorder struct {
+	calls []sdb.CallSummary
+	errs  []error
+}
+
+func (r *callRecorder) Observe(e sdb.Event) {
+	if e.Type == sdb.EventCall {
+		r.calls = append(r.calls, *e.Call)
+		r.errs = append(r.errs, e.Err)
+	}
+}
+
+func TestObserver(t *testing.T) {
+	db, err := Open("testdata/words.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	rec := &callRecorder{}
+	db.SetObserver(rec)
+	cb := func(Row) {}
+
+	if err := db.SelectWith("words", Options{Where: Eq("length", 4)}, cb, "word"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.IndexedSelectEq("words", "words_index_2", Key{4}, cb, "word"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.SelectRowid("words", 1, "word"); err != nil {
+		t.Fatal(err)
+	}
+	done, cancel := context.WithCancel(context.Background())
+	if err := db.SelectContext(done, "words", func(Row) { cancel() }, "word"); err != context.Canceled {
+		t.Fatalf("have %v, want %v", err, context.Canceled)
+	}
+
+	// page numbers depend on the cache
+	for i := range rec.calls {
+		c := &rec.calls[i]
+		if c.PagesRead+c.PagesCached == 0 {
+			t.Errorf("%s: no pages", c.Method)
+		}
+		c.PagesRead, c.PagesCached = 0, 0
+	}
+	want := []sdb.CallSummary{
+		{Method: "Select", Table: "words", RowsExamined: 1000, Rows: 31},
+		{Method: "IndexedSelectEq", Table: "words", Index: "words_index_2", RowsExamined: 31, Rows: 31},
+		{Method: "SelectRowid", Table: "words", RowsExamined: 1, Rows: 1},
+		{Method: "Select", Table: "words", RowsExamined: rec.calls[3].RowsExamined, Rows: rec.calls[3].Rows},
+	}
+	if have := rec.calls; !reflect.DeepEqual(have, want) {
+		t.Errorf("diff:\n%s", diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+	}
+	if have, want := rec.errs, []error{nil, nil, nil, context.Canceled}; !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+}
+
+func TestObserverCalls(t *testing.T) {
+	db, err := Open("testdata/words.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	rec := &callRecorder{}
+	db.SetObserver(rec)
+	cb := func(Row) {}
+
+	if err := db.Join(
+		JoinSpec{Table: "words", Columns: []string{"word"}, Where: Eq("length", 3)},
+		JoinSpec{Table: "words", Columns: []string{"length"}},
+		[]ColumnPair{{Left: "word", Right: "word"}},
+		cb,
+	); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Count("words"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Aggregate("words", []string{"length"}, []Agg{Count("*")}, cb); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Distinct("words", "words_index_2", 1, cb); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SelectParallel("words", 4, cb, "word"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Sample("words", 5, cb, "word"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.EstimateCount("words"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Indexes("words"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.FindIndex("words", "length"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Explain("words", []Cond{Eq("length", 3)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Distinct("words", "nosuch", 1, cb); err == nil {
+		t.Fatal("no error")
+	}
+
+	// these only need the schema, which is already loaded
+	schemaOnly := map[string]bool{"Indexes": true, "FindIndex": true, "Explain": true}
+	for i := range rec.calls {
+		c := &rec.calls[i]
+		if c.PagesRead+c.PagesCached == 0 && rec.errs[i] == nil && !schemaOnly[c.Method] {
+			t.Errorf("%s: no pages", c.Method)
+		}
+		c.PagesRead, c.PagesCached = 0, 0
+	}
+	want := []sdb.CallSummary{
+		{Method: "Join", Table: "words", RowsExamined: 1000, Rows: 8},
+		{Method: "Count", Table: "words", RowsExamined: 1000, Rows: 1000},
+		{Method: "Aggregate", Table: "words", RowsExamined: 1000, Rows: rec.calls[2].Rows},
+		{Method: "Distinct", Table: "words", Index: "words_index_2", RowsExamined: rec.calls[2].Rows, Rows: rec.calls[2].Rows},
+		{Method: "SelectParallel", Table: "words", RowsExamined: 1000, Rows: 1000},
+		{Method: "SampleRand", Table: "words", RowsExamined: 5, Rows: 5},
+		{Method: "EstimateCount", Table: "words"},
+		{Method: "Indexes", Table: "words"},
+		{Method: "FindIndex", Table: "words"},
+		{Method: "Explain", Table: "words"},
+		{Method: "Distinct", Table: "words", Index: "nosuch"},
+	}
+	if have := rec.calls; !reflect.DeepEqual(have, want) {
+		t.Errorf("diff:\n%s", diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+	}
+	if have, want := rec.errs[:10], make([]error, 10); !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+	if rec.errs[10] == nil {
+		t.Errorf("no error for Distinct")
+	}
+}
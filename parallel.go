@@ -0,0 +1,64 @@
+package sqlittle
+
+import (
+	"context"
+
+	sdb "github.com/hackborn/sqlittle/db"
+)
+
+// SelectParallel is Select(), but the table is split in parts which are read
+// by workers goroutines at the same time. workers < 1 means
+// runtime.GOMAXPROCS(0). That's faster for big tables when the callback does
+// real work.
+//
+// The callback is called concurrently from different goroutines, so it needs
+// to do its own locking. The parts are ranges of the rowid (or the primary
+// key, for WITHOUT ROWID tables), and the rows of a single part come in order,
+// from a single goroutine. There is no order between the parts.
+func (db *DB) SelectParallel(table string, workers int, cb RowCB, columns ...string) error {
+	return db.SelectParallelContext(context.Background(), table, workers, cb, columns...)
+}
+
+// SelectParallelContext is SelectParallel() with a context. See
+// SelectContext(). Every worker stops when the context is done.
+func (db *DB) SelectParallelContext(ctx context.Context, table string, workers int, cb RowCB, columns ...string) (err error) {
+	d, c, err := db.rlock(ctx, "SelectParallel", table, "")
+	if err != nil {
+		return err
+	}
+	defer c.end(&err)
+	cb = c.count(cb)
+
+	s, err := d.Schema(table)
+	if err != nil {
+		return err
+	}
+
+	if s.WithoutRowid {
+		ci, err := toColumnIndexNonRowid(s, columns)
+		if err != nil {
+			return err
+		}
+		t, err := d.NonRowidTable(s.Table)
+		if err != nil {
+			return err
+		}
+		return t.ParallelScan(workers, func(r sdb.Record) bool {
+			cb(toRow(0, ci, r))
+			return false
+		})
+	}
+
+	ci, err := toColumnIndexRowid(s, columns)
+	if err != nil {
+		return err
+	}
+	t, err := d.Table(s.Table)
+	if err != nil {
+		return err
+	}
+	return t.ParallelScan(workers, func(rowid int64, r sdb.Record) bool {
+		cb(toRow(rowid, ci, r))
+		return false
+	})
+}
@@ -0,0 +1,52 @@
+package sqlittle
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/andreyvit/diff"
+	"github.com/davecgh/go-spew/spew"
+)
+
+func TestSelectParallel(t *testing.T) {
+	for _, file := range []string{
+		"testdata/words.sqlite",
+		"testdata/withoutrowid.sqlite",
+	} {
+		db, err := Open(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var want [][]string
+		if err := db.Select("words", func(r Row) {
+			want = append(want, r.ScanStrings())
+		}, "word", "length"); err != nil {
+			t.Fatal(err)
+		}
+
+		var (
+			mu   sync.Mutex
+			have [][]string
+		)
+		if err := db.SelectParallel("words", 4, func(r Row) {
+			mu.Lock()
+			defer mu.Unlock()
+			have = append(have, r.ScanStrings())
+		}, "word", "length"); err != nil {
+			t.Fatal(err)
+		}
+		sort.Slice(want, func(i, j int) bool { return want[i][0] < want[j][0] })
+		sort.Slice(have, func(i, j int) bool { return have[i][0] < have[j][0] })
+		if !reflect.DeepEqual(have, want) {
+			t.Errorf("%s: diff:\n%s", file, diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+		}
+
+		if err := db.SelectParallel("words", 4, func(Row) {}, "nosuch"); err == nil {
+			t.Errorf("%s: expected an error", file)
+		}
+		db.Close()
+	}
+}
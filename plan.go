@@ -0,0 +1,528 @@
+package sqlittle
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	sdb "github.com/hackborn/sqlittle/db"
+	"github.com/hackborn/sqlittle/sql"
+)
+
+// Simple query planner for DB.Where(). It picks the access path which uses the
+// most conditions. Every row the access path finds is still checked against
+// all conditions, so an access path only has to find a superset of the
+// matching rows.
+
+type planKind int
+
+const (
+	planScan  planKind = iota // full table scan
+	planRowid                 // rowid lookup or rowid range
+	planIndex                 // index search; can be the primary key of a WITHOUT ROWID table
+)
+
+type plan struct {
+	kind     planKind
+	schema   *sdb.Schema
+	index    *sdb.SchemaIndex // for planIndex
+	pk       bool             // planIndex on the primary key of a WITHOUT ROWID table
+	covering bool             // planIndex doesn't need the table
+	unique   bool             // at most a single row will match
+	eq       []*cond          // == conditions on the first index columns, or the rowid
+	lo, hi   *cond            // range on the next column, or on the rowid
+}
+
+// makePlan picks the best way to find the rows matching the conds. columns are
+// all the columns we'll need to read.
+func makePlan(s *sdb.Schema, conds []cond, columns []string) *plan {
+	best := &plan{kind: planScan, schema: s}
+
+	if !s.WithoutRowid {
+		if p := rowidPlan(s, conds); p != nil {
+			best = p
+		}
+	} else {
+		pk := &sdb.SchemaIndex{Columns: s.PK}
+		if p := indexPlan(s, pk, conds); p != nil {
+			p.pk = true
+			if p.score() > best.score() {
+				best = p
+			}
+		}
+	}
+
+	for i := range s.Indexes {
+		ind := &s.Indexes[i]
+		if ind.Where != nil && !implies(s, conds, ind.Where) {
+			continue
+		}
+		p := indexPlan(s, ind, conds)
+		if p == nil {
+			continue
+		}
+		p.covering = isCovering(s, ind, columns)
+		if p.score() > best.score() {
+			best = p
+		}
+	}
+	return best
+}
+
+// score is a rough guess how good a plan is. Higher is better.
+func (p *plan) score() int {
+	switch p.kind {
+	case planRowid:
+		if len(p.eq) > 0 {
+			return 1000
+		}
+		return 6
+	case planIndex:
+		n := 10 * len(p.eq)
+		if p.lo != nil || p.hi != nil {
+			n += 5
+		}
+		if p.unique {
+			n += 100
+		}
+		if p.pk || p.covering {
+			n++
+		}
+		return n
+	default:
+		return 0
+	}
+}
+
+// rowidPlan uses the rowid conditions, if there are any usable ones
+func rowidPlan(s *sdb.Schema, conds []cond) *plan {
+	p := &plan{kind: planRowid, schema: s}
+	for i := range conds {
+		c := &conds[i]
+		if !c.rowid {
+			continue
+		}
+		switch c.Op {
+		case OpEq:
+			if _, ok := rowidValue(c.value, 0); ok {
+				p.eq = []*cond{c}
+				return p
+			}
+		case OpGt, OpGe:
+			if _, ok := rowidValue(c.value, 1); ok {
+				p.lo = tighter(p.lo, c, 1)
+			}
+		case OpLt, OpLe:
+			if _, ok := rowidValue(c.value, -1); ok {
+				p.hi = tighter(p.hi, c, -1)
+			}
+		}
+	}
+	if p.lo == nil && p.hi == nil {
+		return nil
+	}
+	return p
+}
+
+// rowidValue gives the rowid to search for. Floats are rounded up when dir > 0
+// (lower bound), down when dir < 0 (upper bound), and need to be exact when dir
+// is 0.
+func rowidValue(v interface{}, dir int) (int64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return v, true
+	case float64:
+		f := v
+		switch {
+		case dir > 0:
+			f = math.Ceil(v)
+		case dir < 0:
+			f = math.Floor(v)
+		}
+		if f != v && dir == 0 {
+			return 0, false
+		}
+		if f < math.MinInt64 || f >= math.MaxInt64 {
+			return 0, false
+		}
+		return int64(f), true
+	default:
+		return 0, false
+	}
+}
+
+// indexPlan uses the conditions on the first columns of an index. The index
+// can only be used for a column if it uses the same collate function as the
+// column.
+func indexPlan(s *sdb.Schema, ind *sdb.SchemaIndex, conds []cond) *plan {
+	p := &plan{kind: planIndex, schema: s, index: ind}
+loop:
+	for _, ic := range ind.Columns {
+		if ic.Column == "" {
+			break // expression
+		}
+		var (
+			eq     *cond
+			lo, hi *cond
+		)
+		for i := range conds {
+			c := &conds[i]
+			if !strings.EqualFold(c.column, ic.Column) || c.collate != normCollate(ic.Collate) {
+				continue
+			}
+			switch c.Op {
+			case OpEq, OpIsNull:
+				if eq == nil {
+					eq = c
+				}
+			case OpGt, OpGe:
+				lo = tighter(lo, c, 1)
+			case OpLt, OpLe:
+				hi = tighter(hi, c, -1)
+			}
+		}
+		switch {
+		case eq != nil:
+			p.eq = append(p.eq, eq)
+		case lo != nil || hi != nil:
+			p.lo, p.hi = lo, hi
+			break loop
+		default:
+			break loop
+		}
+	}
+	if len(p.eq) == 0 && p.lo == nil && p.hi == nil {
+		return nil
+	}
+	p.unique = ind.Unique && len(p.eq) == len(ind.Columns)
+	return p
+}
+
+// tighter picks the most restrictive bound. dir is 1 for lower bounds, -1 for
+// upper bounds.
+func tighter(have, c *cond, dir int) *cond {
+	if have == nil {
+		return c
+	}
+	n := sdb.Compare(c.value, have.value, c.collate) * dir
+	if n > 0 || (n == 0 && (c.Op == OpGt || c.Op == OpLt)) {
+		return c
+	}
+	return have
+}
+
+func isCovering(s *sdb.Schema, ind *sdb.SchemaIndex, columns []string) bool {
+	if s.WithoutRowid {
+		ci, err := toColumnIndexNonRowid(s, columns)
+		if err != nil {
+			return false
+		}
+		pkColumns(s, ind)
+		_, ok := coveringColumns(ind, ci)
+		return ok
+	}
+	ci, err := toColumnIndexRowid(s, columns)
+	if err != nil {
+		return false
+	}
+	_, ok := coveringColumns(ind, ci)
+	return ok
+}
+
+// implies is true if the conditions guarantee the expression is true. This is
+// used for partial indexes. Only simple `column op literal` expressions are
+// understood; for anything else this returns false.
+func implies(s *sdb.Schema, conds []cond, e sql.Expression) bool {
+	op, ok := e.(sql.ExBinaryOp)
+	if !ok {
+		return false
+	}
+	column, opName, lit, ok := columnComparison(s, op)
+	if !ok {
+		return false
+	}
+	var eq, lo, hi *cond
+	for i := range conds {
+		c := &conds[i]
+		if !strings.EqualFold(c.column, column) {
+			continue
+		}
+		switch c.Op {
+		case OpIsNull:
+			return false // NULL never compares to anything
+		case OpEq:
+			eq = c
+		case OpGt, OpGe:
+			lo = tighter(lo, c, 1)
+		case OpLt, OpLe:
+			hi = tighter(hi, c, -1)
+		}
+	}
+	if eq != nil {
+		lo, hi = eq, eq
+	}
+	// how the bounds compare to the literal
+	above := func() bool {
+		if lo == nil {
+			return false
+		}
+		n := sdb.Compare(lo.value, lit, lo.collate)
+		return n > 0 || (n == 0 && lo.Op == OpGt)
+	}
+	below := func() bool {
+		if hi == nil {
+			return false
+		}
+		n := sdb.Compare(hi.value, lit, hi.collate)
+		return n < 0 || (n == 0 && hi.Op == OpLt)
+	}
+	atLeast := func() bool {
+		return lo != nil && sdb.Compare(lo.value, lit, lo.collate) >= 0
+	}
+	atMost := func() bool {
+		return hi != nil && sdb.Compare(hi.value, lit, hi.collate) <= 0
+	}
+	switch opName {
+	case "=", "==":
+		return atLeast() && atMost()
+	case "!=", "<>":
+		return above() || below()
+	case ">":
+		return above()
+	case ">=":
+		return atLeast()
+	case "<":
+		return below()
+	case "<=":
+		return atMost()
+	default:
+		return false
+	}
+}
+
+// columnComparison understands `column op literal` and `literal op column`
+// expressions. The operator is flipped if needed so it's always `column op
+// literal`.
+func columnComparison(s *sdb.Schema, e sql.ExBinaryOp) (string, string, interface{}, bool) {
+	if col, ok := tableColumn(s, e.Left); ok {
+		if lit, ok := literal(s, e.Right); ok {
+			return col, e.Op, lit, true
+		}
+	}
+	if col, ok := tableColumn(s, e.Right); ok {
+		if lit, ok := literal(s, e.Left); ok {
+			flip := map[string]string{"<": ">", "<=": ">=", ">": "<", ">=": "<="}
+			op := e.Op
+			if f, ok := flip[op]; ok {
+				op = f
+			}
+			return col, op, lit, true
+		}
+	}
+	return "", "", nil, false
+}
+
+func tableColumn(s *sdb.Schema, e sql.Expression) (string, bool) {
+	c, ok := e.(sql.ExColumn)
+	if !ok || s.Column(string(c)) < 0 {
+		return "", false
+	}
+	return string(c), true
+}
+
+// literal values in an expression. Like SQLite, a "quoted" identifier which
+// isn't a column is a string.
+func literal(s *sdb.Schema, e sql.Expression) (interface{}, bool) {
+	switch v := e.(type) {
+	case int64, float64, string:
+		return v, true
+	case sql.ExColumn:
+		if s.Column(string(v)) >= 0 {
+			return nil, false
+		}
+		return string(v), true
+	default:
+		return nil, false
+	}
+}
+
+// String gives the access path, in the same format as SQLite's `EXPLAIN QUERY
+// PLAN`.
+func (p *plan) String() string {
+	table := p.schema.Table
+	switch p.kind {
+	case planRowid:
+		return fmt.Sprintf("SEARCH %s USING INTEGER PRIMARY KEY (%s)", table, p.terms("rowid"))
+	case planIndex:
+		terms := p.terms("")
+		switch {
+		case terms == "" && p.pk:
+			return fmt.Sprintf("SCAN %s", table)
+		case terms == "" && p.covering:
+			return fmt.Sprintf("SCAN %s USING COVERING INDEX %s", table, p.index.Index)
+		case terms == "":
+			return fmt.Sprintf("SCAN %s USING INDEX %s", table, p.index.Index)
+		case p.pk:
+			return fmt.Sprintf("SEARCH %s USING PRIMARY KEY (%s)", table, terms)
+		case p.covering:
+			return fmt.Sprintf("SEARCH %s USING COVERING INDEX %s (%s)", table, p.index.Index, terms)
+		default:
+			return fmt.Sprintf("SEARCH %s USING INDEX %s (%s)", table, p.index.Index, terms)
+		}
+	default:
+		return fmt.Sprintf("SCAN %s", table)
+	}
+}
+
+func (p *plan) terms(rowid string) string {
+	name := func(i int) string {
+		if rowid != "" {
+			return rowid
+		}
+		return p.index.Columns[i].Column
+	}
+	var ts []string
+	for i := range p.eq {
+		ts = append(ts, name(i)+"=?")
+	}
+	if p.lo != nil {
+		ts = append(ts, name(len(p.eq))+p.lo.Op.String()+"?")
+	}
+	if p.hi != nil {
+		ts = append(ts, name(len(p.eq))+p.hi.Op.String()+"?")
+	}
+	return strings.Join(ts, " AND ")
+}
+
+// run executes the plan. Every row found by the access path goes to the
+// emitter, which should check the conditions.
+func (p *plan) run(db *sdb.Database, e *emitter, columns []string) error {
+	s := p.schema
+	switch p.kind {
+	case planScan:
+		if s.WithoutRowid {
+			return selectNonRowid(db, s, e, columns)
+		}
+		return select_(db, s, e, columns)
+	case planRowid:
+		return p.runRowid(db, e, columns)
+	case planIndex:
+		return p.runIndex(db, e, columns)
+	default:
+		panic("impossible")
+	}
+}
+
+func (p *plan) runRowid(db *sdb.Database, e *emitter, columns []string) error {
+	s := p.schema
+	if len(p.eq) > 0 {
+		rowid, _ := rowidValue(p.eq[0].value, 0)
+		return emitRowid(db, s, rowid, e, columns)
+	}
+
+	ci, err := toColumnIndexRowid(s, columns)
+	if err != nil {
+		return err
+	}
+	t, err := db.Table(s.Table)
+	if err != nil {
+		return err
+	}
+	from := int64(math.MinInt64)
+	if p.lo != nil {
+		from, _ = rowidValue(p.lo.value, 1)
+	}
+	to := int64(math.MaxInt64)
+	if p.hi != nil {
+		to, _ = rowidValue(p.hi.value, -1)
+	}
+	return t.ScanMin(from, func(rowid int64, r sdb.Record) bool {
+		if rowid > to {
+			return true
+		}
+		return e.emit(rowid, ci, r)
+	})
+}
+
+func (p *plan) runIndex(db *sdb.Database, e *emitter, columns []string) error {
+	s := p.schema
+
+	var (
+		ind *sdb.Index
+		rcb sdb.RecordCB
+		err error
+	)
+	switch {
+	case p.pk:
+		ind, err = db.NonRowidTable(s.Table)
+		if err != nil {
+			return err
+		}
+		ci, err := toColumnIndexNonRowid(s, columns)
+		if err != nil {
+			return err
+		}
+		rcb = func(r sdb.Record) bool {
+			return e.emit(0, ci, r)
+		}
+	case s.WithoutRowid:
+		ind, err = db.Index(p.index.Index)
+		if err != nil {
+			return err
+		}
+		rcb, err = indexRecordCBNonRowid(db, s, p.index, e, columns)
+	default:
+		ind, err = db.Index(p.index.Index)
+		if err != nil {
+			return err
+		}
+		rcb, err = indexRecordCB(db, s, p.index, e, columns)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Start at the == values, followed by the start of the range, if any.
+	// Stop when the == values don't match anymore, or we're past the end of
+	// the range.
+	var (
+		key      Key
+		pos      = len(p.eq)
+		desc     bool
+		start    *cond
+		end      *cond
+		endOrder int
+	)
+	for _, c := range p.eq {
+		key = append(key, c.value)
+	}
+	if p.lo != nil || p.hi != nil {
+		desc = p.index.Columns[pos].SortOrder == sql.Desc
+		start, end, endOrder = p.lo, p.hi, 1
+		if desc {
+			start, end, endOrder = p.hi, p.lo, -1
+		}
+	}
+	eqKey, err := asDbKey(key, p.index.Columns)
+	if err != nil {
+		return err
+	}
+	if start != nil {
+		key = append(key, start.value)
+	}
+	from, err := asDbKey(key, p.index.Columns)
+	if err != nil {
+		return err
+	}
+
+	return ind.ScanMin(from, func(r sdb.Record) bool {
+		if !sdb.Equals(eqKey, r) {
+			return true
+		}
+		if end != nil && len(r) > pos &&
+			sdb.Compare(r[pos], end.value, end.collate)*endOrder > 0 {
+			return true
+		}
+		return rcb(r)
+	})
+}
@@ -0,0 +1,509 @@
+package sqlittle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	sdb "github.com/hackborn/sqlittle/db"
+	ssql "github.com/hackborn/sqlittle/sql"
+)
+
+// ErrReadOnly is returned by Prepare() and Query() for every statement which
+// would change the database.
+var ErrReadOnly = errors.New("sqlittle: database is read-only")
+
+// Stmt is a parsed SELECT statement, from Prepare().
+type Stmt struct {
+	db            *DB
+	table         string
+	columns       []string // can be "*"
+	where         ssql.Expression
+	orderBy       []Order
+	limit, offset ssql.Expression
+	params        int // highest parameter number
+}
+
+// statements which change the database
+var writes = map[string]bool{
+	"ALTER":   true,
+	"ANALYZE": true,
+	"ATTACH":  true,
+	"CREATE":  true,
+	"DELETE":  true,
+	"DETACH":  true,
+	"DROP":    true,
+	"INSERT":  true,
+	"REINDEX": true,
+	"REPLACE": true,
+	"UPDATE":  true,
+	"UPSERT":  true,
+	"VACUUM":  true,
+}
+
+// Prepare parses a SELECT statement, to run it later with Stmt.Query(). Only
+// SELECTs on a single table are supported:
+//
+//	SELECT * | column, ... FROM table
+//	    [WHERE expr]
+//	    [ORDER BY column [ASC|DESC], ...]
+//	    [LIMIT n [OFFSET m]]
+//
+// The WHERE expression can use AND, OR, NOT, and parentheses, with these
+// conditions on a column:
+//
+//	column op value
+//	column IS [NOT] NULL
+//	column [NOT] IN (value, ...)
+//	column [NOT] LIKE value
+//	column [NOT] GLOB value
+//
+// where op is one of =, ==, !=, <>, <, <=, >, >=, and a value is a number, a
+// 'string', NULL, or a `?` parameter. These are the same as the Filters for
+// Options.Where. The `column op value` conditions which are ANDed together at
+// the top are used the same way Where() uses them, so an index is used when
+// there is a usable one. Statements which would change the database return
+// ErrReadOnly.
+func (db *DB) Prepare(sqlText string) (*Stmt, error) {
+	q := strings.TrimRight(strings.TrimSpace(sqlText), "; \t\n")
+	if f := strings.Fields(q); len(f) > 0 && writes[strings.ToUpper(f[0])] {
+		return nil, ErrReadOnly
+	}
+	st, err := ssql.Parse(q)
+	if err != nil {
+		return nil, err
+	}
+	sel, ok := st.(ssql.SelectStmt)
+	if !ok {
+		return nil, ErrReadOnly
+	}
+
+	res := &Stmt{
+		db:      db,
+		table:   sel.Table,
+		columns: sel.Columns,
+		where:   sel.Where,
+		limit:   sel.Limit,
+		offset:  sel.Offset,
+	}
+	for _, c := range sel.Columns {
+		if c == "*" && len(sel.Columns) > 1 {
+			return nil, errors.New("* can't be used with other columns")
+		}
+	}
+	for _, o := range sel.OrderBy {
+		res.orderBy = append(res.orderBy, Order{
+			Column: o.Column,
+			Desc:   o.SortOrder == ssql.Desc,
+		})
+	}
+	for _, e := range []ssql.Expression{sel.Where, sel.Limit, sel.Offset} {
+		if n := maxParam(e); n > res.params {
+			res.params = n
+		}
+	}
+
+	// check the WHERE now, rather than when it's run
+	if _, _, err := toWhere(res.where, make([]interface{}, res.params)); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// NumInput gives the number of `?` parameters the statement needs.
+func (st *Stmt) NumInput() int {
+	return st.params
+}
+
+// Query runs the statement with the values for the `?` parameters.
+func (st *Stmt) Query(args ...interface{}) (*Rows, error) {
+	return st.QueryContext(context.Background(), args...)
+}
+
+// QueryContext is Query() with a context. See SelectContext().
+func (st *Stmt) QueryContext(ctx context.Context, args ...interface{}) (rows *Rows, err error) {
+	if len(args) != st.params {
+		return nil, fmt.Errorf("expected %d arguments, got %d", st.params, len(args))
+	}
+	conds, filter, err := toWhere(st.where, args)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := toInt(st.limit, args, -1)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := toInt(st.offset, args, 0)
+	if err != nil {
+		return nil, err
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	opts := Options{Where: filter, OrderBy: st.orderBy}
+	if limit >= 0 {
+		opts.Limit = limit + offset
+	}
+
+	d, c, err := st.db.rlock(ctx, "Query", st.table, "")
+	if err != nil {
+		return nil, err
+	}
+	defer c.end(&err)
+
+	s, err := d.Schema(st.table)
+	if err != nil {
+		return nil, err
+	}
+	columns := st.columns
+	if len(columns) == 1 && columns[0] == "*" {
+		columns = nil
+		for _, c := range s.Columns {
+			columns = append(columns, c.Column)
+		}
+	}
+	rows = &Rows{columns: columns, types: columnTypes(s, columns), opts: st.db.scanOpts, i: -1}
+	if limit == 0 {
+		return rows, nil
+	}
+	if err := where(d, s, c, conds, opts, c.count(func(r Row) {
+		if offset > 0 {
+			offset--
+			return
+		}
+		rows.rows = append(rows.rows, copyRow(r))
+	}), columns); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// Query parses and runs a SELECT statement. See Prepare() for what's
+// supported. All rows are read into memory before Query returns, so the
+// database isn't locked while the rows are used. Use a LIMIT, or Select() with
+// a callback, for results which might not fit in memory.
+//
+//	rows, err := db.Query("SELECT name FROM tracks WHERE length > ? ORDER BY name", 180)
+//	...
+//	for rows.Next() {
+//	    var name string
+//	    if err := rows.Scan(&name); err != nil {
+//	        ...
+//	    }
+//	}
+func (db *DB) Query(sqlText string, args ...interface{}) (*Rows, error) {
+	return db.QueryContext(context.Background(), sqlText, args...)
+}
+
+// QueryContext is Query() with a context. See SelectContext().
+func (db *DB) QueryContext(ctx context.Context, sqlText string, args ...interface{}) (*Rows, error) {
+	st, err := db.Prepare(sqlText)
+	if err != nil {
+		return nil, err
+	}
+	return st.QueryContext(ctx, args...)
+}
+
+// ColumnType is the declaration of a column in a Query() result.
+type ColumnType struct {
+	Name string
+	Type string // as declared in the CREATE TABLE
+	Null bool   // false for NOT NULL columns, and the rowid
+}
+
+// columnTypes looks up the declarations of the columns.
+func columnTypes(s *sdb.Schema, columns []string) []ColumnType {
+	var ts []ColumnType
+	for _, c := range columns {
+		t := ColumnType{Name: c, Type: "INTEGER"} // rowid
+		if n := s.Column(c); n >= 0 {
+			col := s.Columns[n]
+			t.Type, t.Null = col.Type, col.Null
+		}
+		ts = append(ts, t)
+	}
+	return ts
+}
+
+// Rows are the result of a Query(). Use Next() to go over the rows.
+type Rows struct {
+	columns []string
+	types   []ColumnType
+	rows    []Row
+	opts    ScanOptions
+	i       int
+}
+
+// Columns gives the column names, with `*` expanded.
+func (r *Rows) Columns() []string {
+	return r.columns
+}
+
+// ColumnTypes gives the declarations of the columns, in the order of
+// Columns().
+func (r *Rows) ColumnTypes() []ColumnType {
+	return r.types
+}
+
+// Len gives the number of rows.
+func (r *Rows) Len() int {
+	return len(r.rows)
+}
+
+// Next moves to the next row. It returns false when there are no more rows.
+func (r *Rows) Next() bool {
+	if r.i+1 >= len(r.rows) {
+		r.i = len(r.rows)
+		return false
+	}
+	r.i++
+	return true
+}
+
+// Row gives the current row, or nil if Next() wasn't called or returned false.
+func (r *Rows) Row() Row {
+	if r.i < 0 || r.i >= len(r.rows) {
+		return nil
+	}
+	return r.rows[r.i]
+}
+
+// Scan is Row.Scan() on the current row, with the options from
+// DB.SetScanOptions().
+func (r *Rows) Scan(args ...interface{}) error {
+	row := r.Row()
+	if row == nil {
+		return errors.New("no row")
+	}
+	return row.ScanWith(r.opts, args...)
+}
+
+// Close drops the rows. It always returns nil.
+func (r *Rows) Close() error {
+	r.rows = nil
+	r.i = 0
+	return nil
+}
+
+// maxParam gives the highest `?` number in the expression.
+func maxParam(e ssql.Expression) int {
+	switch v := e.(type) {
+	case ssql.ExParam:
+		return int(v)
+	case ssql.ExBinaryOp:
+		l, r := maxParam(v.Left), maxParam(v.Right)
+		if l > r {
+			return l
+		}
+		return r
+	case ssql.ExUnaryOp:
+		return maxParam(v.Expr)
+	case ssql.ExIn:
+		n := maxParam(v.Left)
+		for _, a := range v.Values {
+			if m := maxParam(a); m > n {
+				n = m
+			}
+		}
+		return n
+	case ssql.ExFunction:
+		n := 0
+		for _, a := range v.Args {
+			if m := maxParam(a); m > n {
+				n = m
+			}
+		}
+		return n
+	default:
+		return 0
+	}
+}
+
+var ops = map[string]Op{
+	"=":  OpEq,
+	"==": OpEq,
+	"!=": OpNe,
+	"<>": OpNe,
+	"<":  OpLt,
+	"<=": OpLe,
+	">":  OpGt,
+	">=": OpGe,
+}
+
+// flipped is the op with the operands swapped: `1 < a` is `a > 1`.
+var flipped = map[Op]Op{
+	OpEq: OpEq,
+	OpNe: OpNe,
+	OpLt: OpGt,
+	OpLe: OpGe,
+	OpGt: OpLt,
+	OpGe: OpLe,
+}
+
+// toWhere changes a WHERE expression to the conditions which can be used to
+// pick an index, and a filter for everything else. The `column op value`
+// terms of the top level AND are the conditions. All of them need to be
+// true. The filter is nil if there is nothing else.
+func toWhere(e ssql.Expression, args []interface{}) ([]Cond, Filter, error) {
+	var (
+		conds   []Cond
+		filters []Filter
+	)
+	var walk func(ssql.Expression) error
+	walk = func(e ssql.Expression) error {
+		if b, ok := e.(ssql.ExBinaryOp); ok && b.Op == "AND" {
+			if err := walk(b.Left); err != nil {
+				return err
+			}
+			return walk(b.Right)
+		}
+		f, err := toFilter(e, args)
+		if err != nil {
+			return err
+		}
+		if c, ok := f.(Cond); ok {
+			conds = append(conds, c)
+		} else {
+			filters = append(filters, f)
+		}
+		return nil
+	}
+	if e == nil {
+		return nil, nil, nil
+	}
+	if err := walk(e); err != nil {
+		return nil, nil, err
+	}
+	if len(filters) == 0 {
+		return conds, nil, nil
+	}
+	return conds, And(filters...), nil
+}
+
+// toFilter changes a WHERE expression to a Filter.
+func toFilter(e ssql.Expression, args []interface{}) (Filter, error) {
+	unsupported := fmt.Errorf("unsupported WHERE: %s", ssql.AsString(e))
+	switch e := e.(type) {
+	case ssql.ExUnaryOp:
+		f, err := toFilter(e.Expr, args)
+		if err != nil {
+			return nil, err
+		}
+		return Not(f), nil
+	case ssql.ExIn:
+		col, ok := e.Left.(ssql.ExColumn)
+		if !ok {
+			return nil, unsupported
+		}
+		vs := make([]interface{}, 0, len(e.Values))
+		for _, ev := range e.Values {
+			v, err := toValue(ev, args)
+			if err != nil {
+				return nil, err
+			}
+			vs = append(vs, v)
+		}
+		return In(string(col), vs...), nil
+	case ssql.ExBinaryOp:
+		switch e.Op {
+		case "AND", "OR":
+			l, err := toFilter(e.Left, args)
+			if err != nil {
+				return nil, err
+			}
+			r, err := toFilter(e.Right, args)
+			if err != nil {
+				return nil, err
+			}
+			if e.Op == "AND" {
+				return And(l, r), nil
+			}
+			return Or(l, r), nil
+		case "IS", "IS NOT":
+			col, ok := e.Left.(ssql.ExColumn)
+			if !ok || e.Right != nil {
+				return nil, unsupported
+			}
+			if e.Op == "IS" {
+				return IsNull(string(col)), nil
+			}
+			return Not(IsNull(string(col))), nil
+		case "LIKE", "GLOB":
+			col, ok := e.Left.(ssql.ExColumn)
+			if !ok {
+				return nil, unsupported
+			}
+			v, err := toValue(e.Right, args)
+			if err != nil {
+				return nil, err
+			}
+			dv, err := toDbValue(v)
+			if err != nil {
+				return nil, err
+			}
+			pattern, ok := asText(dv)
+			if !ok {
+				// anything LIKE NULL is NULL
+				return Eq(string(col), nil), nil
+			}
+			if e.Op == "LIKE" {
+				return Like(string(col), pattern), nil
+			}
+			return Glob(string(col), pattern), nil
+		}
+
+		op, ok := ops[e.Op]
+		if !ok {
+			return nil, unsupported
+		}
+		col, ok := e.Left.(ssql.ExColumn)
+		value := e.Right
+		if !ok {
+			col, ok = e.Right.(ssql.ExColumn)
+			value = e.Left
+			op = flipped[op]
+		}
+		if !ok {
+			return nil, unsupported
+		}
+		v, err := toValue(value, args)
+		if err != nil {
+			return nil, err
+		}
+		return Cond{Column: string(col), Op: op, Value: v}, nil
+	default:
+		return nil, unsupported
+	}
+}
+
+// toValue gives the value of a literal or a parameter.
+func toValue(e ssql.Expression, args []interface{}) (interface{}, error) {
+	switch v := e.(type) {
+	case nil, int64, float64, string:
+		return v, nil
+	case ssql.ExParam:
+		return args[v-1], nil
+	default:
+		return nil, fmt.Errorf("unsupported value: %s", ssql.AsString(e))
+	}
+}
+
+// toInt gives the value for LIMIT or OFFSET, or def if there is none.
+func toInt(e ssql.Expression, args []interface{}, def int) (int, error) {
+	if e == nil {
+		return def, nil
+	}
+	v, err := toValue(e, args)
+	if err != nil {
+		return 0, err
+	}
+	if dv, err := toDbValue(v); err == nil {
+		v = dv
+	}
+	n, ok := v.(int64)
+	if !ok {
+		return 0, fmt.Errorf("not an integer: %v", v)
+	}
+	return int(n), nil
+}
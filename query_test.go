@@ -0,0 +1,181 @@
+package sqlittle
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/andreyvit/diff"
+	"github.com/davecgh/go-spew/spew"
+)
+
+func TestQuery(t *testing.T) {
+	db, err := Open("testdata/words.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	rec := &callRecorder{}
+	db.SetObserver(rec)
+
+	type cas struct {
+		sql      string
+		args     []interface{}
+		columns  []string
+		examined int
+		want     [][]string
+	}
+	for _, c := range []cas{
+		{
+			sql:      "SELECT word FROM words WHERE length = 3 AND word > ?",
+			args:     []interface{}{"b"},
+			columns:  []string{"word"},
+			examined: 4,
+			want:     [][]string{{"big"}, {"fir"}, {"nab"}, {"pat"}},
+		},
+		{
+			sql:      "SELECT rowid, word FROM words WHERE 998 <= rowid",
+			columns:  []string{"rowid", "word"},
+			examined: 3,
+			want:     [][]string{{"998", "sensible"}, {"999", "boysenberry's"}, {"1000", "ideologist"}},
+		},
+		{
+			sql:      "SELECT * FROM words WHERE length = ? ORDER BY word DESC LIMIT ? OFFSET 1",
+			args:     []interface{}{3, 2},
+			columns:  []string{"word", "length"},
+			examined: 8,
+			want:     [][]string{{"nab", "3"}, {"fir", "3"}},
+		},
+		{
+			sql:      "SELECT word FROM words WHERE word = 'Bic' AND length != 4;",
+			columns:  []string{"word"},
+			examined: 1,
+			want:     [][]string{{"Bic"}},
+		},
+		{
+			sql:      "SELECT word FROM words WHERE length = 3 AND (word LIKE 'b%' OR word IN ('pat', ?)) ORDER BY word",
+			args:     []interface{}{"nab"},
+			columns:  []string{"word"},
+			examined: 8,
+			want:     [][]string{{"Bic"}, {"big"}, {"nab"}, {"pat"}},
+		},
+		{
+			sql:      "SELECT word FROM words WHERE length = 3 AND NOT word GLOB '[a-f]*' AND word IS NOT NULL ORDER BY word",
+			columns:  []string{"word"},
+			examined: 8,
+			want:     [][]string{{"Amy"}, {"Bic"}, {"Eva"}, {"Len"}, {"nab"}, {"pat"}},
+		},
+		{
+			sql:      "SELECT word FROM words WHERE word IS NULL OR length < 3",
+			columns:  []string{"word"},
+			examined: 1000,
+			want:     [][]string{{"am"}},
+		},
+		{
+			sql:      "SELECT word FROM words WHERE length = 3 AND word NOT IN ('Amy', 'Bic', 'Eva', 'Len', 'big') ORDER BY word",
+			columns:  []string{"word"},
+			examined: 8,
+			want:     [][]string{{"fir"}, {"nab"}, {"pat"}},
+		},
+		{
+			sql:     "SELECT word FROM words LIMIT 0",
+			columns: []string{"word"},
+		},
+	} {
+		rec.calls = nil
+		rows, err := db.Query(c.sql, c.args...)
+		if err != nil {
+			t.Fatalf("%s: %s", c.sql, err)
+		}
+		if have, want := rows.Columns(), c.columns; !reflect.DeepEqual(have, want) {
+			t.Errorf("%s: have %v, want %v", c.sql, have, want)
+		}
+		var have [][]string
+		for rows.Next() {
+			row := make([]string, len(rows.Columns()))
+			ps := make([]interface{}, len(row))
+			for i := range row {
+				ps[i] = &row[i]
+			}
+			if err := rows.Scan(ps...); err != nil {
+				t.Fatal(err)
+			}
+			have = append(have, row)
+		}
+		if want := c.want; !reflect.DeepEqual(have, want) {
+			t.Errorf("%s diff:\n%s", c.sql, diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+		}
+		if len(rec.calls) == 1 && rec.calls[0].RowsExamined != c.examined {
+			t.Errorf("%s: examined %d rows, want %d", c.sql, rec.calls[0].RowsExamined, c.examined)
+		}
+	}
+
+	// prepared
+	st, err := db.Prepare("SELECT word FROM words WHERE rowid = ?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := st.NumInput(), 1; have != want {
+		t.Errorf("have %d, want %d", have, want)
+	}
+	rows, err := st.Query(999)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rows.Next() {
+		t.Fatal("no row")
+	}
+	if have, want := rows.Row(), (Row{"boysenberry's"}); !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+	if rows.Next() {
+		t.Error("more than one row")
+	}
+
+	// column types
+	rows, err = db.Query("SELECT rowid, word, length FROM words LIMIT 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ColumnType{
+		{Name: "rowid", Type: "INTEGER"},
+		{Name: "word", Type: "varchar", Null: true},
+		{Name: "length", Type: "int", Null: true},
+	}
+	if have := rows.ColumnTypes(); !reflect.DeepEqual(have, want) {
+		t.Errorf("diff:\n%s", diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	db, err := Open("testdata/music.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	type cas struct {
+		sql  string
+		args []interface{}
+		err  error
+	}
+	for _, c := range []cas{
+		{sql: "DELETE FROM artists", err: ErrReadOnly},
+		{sql: "CREATE TABLE foo (a)", err: ErrReadOnly},
+		{sql: "SELECT nosuch FROM artists", err: errors.New(`no such column: "nosuch"`)},
+		{sql: "SELECT name FROM nosuch", err: errors.New(`no such table: "nosuch"`)},
+		{sql: "SELECT name FROM artists WHERE id + 1 = 2", err: errors.New(`unsupported WHERE: "id"+1=2`)},
+		{sql: "SELECT name FROM artists WHERE id = 1 OR id + 1 = 2", err: errors.New(`unsupported WHERE: "id"+1=2`)},
+		{sql: "SELECT name FROM artists WHERE name LIKE id", err: errors.New(`unsupported value: "id"`)},
+		{sql: "SELECT *, name FROM artists", err: errors.New("* can't be used with other columns")},
+		{sql: "SELECT name FROM artists LIMIT 'ten'", err: errors.New("not an integer: ten")},
+		{sql: "SELECT name FROM artists WHERE id = ?", err: errors.New("expected 1 arguments, got 0")},
+		{sql: "SELECT name FROM artists WHERE id = ?", args: []interface{}{struct{}{}}, err: errors.New("unknown Key datatype: struct {}")},
+	} {
+		_, err := db.Query(c.sql, c.args...)
+		if have, want := err, c.err; !reflect.DeepEqual(have, want) {
+			t.Errorf("%s: have %v, want %v", c.sql, have, want)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package sqlittle
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestReopen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sqlittle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "db.sqlite")
+	// replace the file the way deploy tools do it
+	deploy := func(from string) {
+		t.Helper()
+		b, err := ioutil.ReadFile(from)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(file+".tmp", b, 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(file+".tmp", file); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deploy("testdata/words.sqlite")
+
+	db, err := Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	reopened := 0
+	db.SetReopen(true, func() { reopened++ })
+
+	count := func(table string) (int, error) {
+		n := 0
+		err := db.Select(table, func(Row) { n++ })
+		return n, err
+	}
+	if have, _ := count("words"); have != 1000 {
+		t.Errorf("have %d", have)
+	}
+
+	deploy("testdata/music.sqlite")
+	_, err = count("words")
+	if have, want := err, errors.New(`no such table: "words"`); !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+	if n, err := count("artists"); err != nil || n == 0 {
+		t.Errorf("have %d, %v", n, err)
+	}
+	if have, want := reopened, 1; have != want {
+		t.Errorf("have %d, want %d", have, want)
+	}
+
+	deploy("testdata/words.sqlite")
+	if have, _ := count("words"); have != 1000 {
+		t.Errorf("have %d", have)
+	}
+	if have, want := reopened, 2; have != want {
+		t.Errorf("have %d, want %d", have, want)
+	}
+}
+
+func TestReopenInFlight(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sqlittle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "db.sqlite")
+	deploy := func(from string) {
+		t.Helper()
+		b, err := ioutil.ReadFile(from)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(file+".tmp", b, 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(file+".tmp", file); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deploy("testdata/words.sqlite")
+
+	db, err := Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	reopened := make(chan struct{}, 1)
+	db.SetReopen(true, func() { reopened <- struct{}{} })
+
+	// a scan which is busy while the file is replaced
+	started := make(chan struct{})
+	resume := make(chan struct{})
+	type result struct {
+		n   int
+		err error
+	}
+	scan := make(chan result)
+	go func() {
+		n := 0
+		err := db.Select("words", func(Row) {
+			if n == 0 {
+				close(started)
+				<-resume
+			}
+			n++
+		}, "word")
+		scan <- result{n, err}
+	}()
+	<-started
+
+	deploy("testdata/music.sqlite")
+	other := make(chan result)
+	go func() {
+		n := 0
+		err := db.Select("artists", func(Row) { n++ }, "name")
+		other <- result{n, err}
+	}()
+
+	// the reopen waits for the scan
+	select {
+	case <-reopened:
+		t.Fatal("reopened during a scan")
+	case r := <-other:
+		t.Fatalf("select during a scan: %v", r)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(resume)
+
+	// the scan finishes on the old file
+	if have, want := <-scan, (result{1000, nil}); have != want {
+		t.Errorf("have %v, want %v", have, want)
+	}
+	if r := <-other; r.err != nil || r.n == 0 {
+		t.Errorf("have %v", r)
+	}
+	select {
+	case <-reopened:
+	default:
+		t.Error("not reopened")
+	}
+}
@@ -1,8 +1,13 @@
 package sqlittle
 
 import (
+	"database/sql"
+	"encoding"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"reflect"
 	"strconv"
 	"time"
 
@@ -12,6 +17,11 @@ import (
 // A row with values as stored in the database. Use Row.Scan() to process these
 // values.
 //
+// Values have the affinity of their column applied, the same as SQLite does:
+// a REAL column gives float64 values, also when the value was stored as an
+// integer, and an INTEGER or NUMERIC column gives int64 values for numbers
+// without a fractional part.
+//
 // Values are allowed to point to bytes in the database and hence are
 // only valid during a DB transaction.
 type Row sdb.Record
@@ -19,79 +29,234 @@ type Row sdb.Record
 // Scan converts a row with database values to the Go values you want.
 // Supported Go types:
 //  - bool
-//  - float64
-//  - int
-//  - int32
-//  - int64
+//  - float32, float64
+//  - int, int8, int16, int32, int64
+//  - uint, uint8, uint16, uint32, uint64
 //  - string
-//  - []byte
+//  - []byte, json.RawMessage
 //  - time.Time
+//  - time.Duration
+//  - sql.NullString, sql.NullInt64, sql.NullTime, and the other sql.Null types
+//  - anything which implements sql.Scanner, encoding.TextUnmarshaler, or
+//    encoding.BinaryUnmarshaler (checked in that order)
+//  - a pointer to any of these, such as **string, which is set to nil for
+//    NULL, and to a new value otherwise
 //  - nil (skips the column)
 //
 // Conversions are usually stricter than in SQLite:
 //  - string to number does not accept trailing letters such as in "123test"
 //  - string to bool needs to convert to a number cleanly
 //  - numbers are stored as either int64 or float64, and are converted
-//    with the normal Go conversions.
+//    with the normal Go conversions, but it's an error if a number doesn't
+//    fit in the integer type
+//
+// NULL is the zero value for the basic types, and an empty text for
+// TextUnmarshaler and BinaryUnmarshaler. A sql.Scanner gets the database value
+// as is: nil, int64, float64, string, or []byte. Use a sql.Null type or a
+// pointer to tell NULL apart from zero.
+//
+// Times can be stored the ways SQLite's date and time functions understand
+// them: text such as "2006-01-02 15:04:05.000" or "2006-01-02T15:04:05Z", a
+// real with the julian day number, or an integer with unix seconds. Times
+// without a timezone are UTC. A time.Duration is an integer with seconds, a
+// real with seconds, or text such as "1h30m" or "01:30:00". Rows given by a
+// select use the options from DB.SetScanOptions(); other rows, such as a copy,
+// use the zero ScanOptions. Use ScanWith() for other units and locations.
 //
-// Values are a copy of the database bytes; they stay valid even after closing
-// the database.
+// It's an error to scan more columns than the row has.
 func (r Row) Scan(args ...interface{}) error {
-	var err error
+	return r.ScanWith(r.options(), args...)
+}
+
+// ScanWith is Scan() with options for times and durations.
+func (r Row) ScanWith(o ScanOptions, args ...interface{}) error {
 	for i, v := range args {
-		switch vt := v.(type) {
-		case nil:
-			// skip
-		case *string:
-			*vt = r.scanString(i)
-		case *[]byte:
-			*vt = r.scanBytes(i)
-		case *int64:
-			*vt, err = r.scanInt64(i)
-			if err != nil {
-				return err
-			}
-		case *int32:
-			n, err := r.scanInt64(i)
-			if err != nil {
-				return err
-			}
-			*vt = int32(n)
-		case *int:
-			n, err := r.scanInt64(i)
-			if err != nil {
-				return err
-			}
-			*vt = int(n)
-		case *bool:
-			n, err := r.scanInt64(i)
-			if err != nil {
-				return fmt.Errorf("invalid boolean: %q", r[i])
-			}
-			*vt = n != 0
-		case *float64:
-			n, err := r.scanFloat64(i)
-			if err != nil {
-				return err
-			}
-			*vt = n
-		case *time.Time:
-			t, err := r.scanTime(i)
-			if err != nil {
-				return err
-			}
-			*vt = t
-		default:
+		if v == nil {
+			continue // skip
+		}
+		if i >= len(r) {
+			return fmt.Errorf("can't scan column %d: row has %d columns", i, len(r))
+		}
+		if err := r.scan(o, i, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scan scans column i into v.
+func (r Row) scan(o ScanOptions, i int, v interface{}) error {
+	switch vt := v.(type) {
+	case *string:
+		*vt = r.scanString(i)
+	case *[]byte:
+		*vt = r.scanBytes(i)
+	case *json.RawMessage:
+		*vt = r.scanBytes(i)
+	case *int64:
+		n, err := r.scanInt64(i)
+		if err != nil {
+			return err
+		}
+		*vt = n
+	case *int32:
+		n, err := r.scanIntN(i, 32, "int32")
+		if err != nil {
+			return err
+		}
+		*vt = int32(n)
+	case *int16:
+		n, err := r.scanIntN(i, 16, "int16")
+		if err != nil {
+			return err
+		}
+		*vt = int16(n)
+	case *int8:
+		n, err := r.scanIntN(i, 8, "int8")
+		if err != nil {
+			return err
+		}
+		*vt = int8(n)
+	case *int:
+		n, err := r.scanIntN(i, strconv.IntSize, "int")
+		if err != nil {
+			return err
+		}
+		*vt = int(n)
+	case *uint64:
+		n, err := r.scanUintN(i, 64, "uint64")
+		if err != nil {
+			return err
+		}
+		*vt = n
+	case *uint32:
+		n, err := r.scanUintN(i, 32, "uint32")
+		if err != nil {
+			return err
+		}
+		*vt = uint32(n)
+	case *uint16:
+		n, err := r.scanUintN(i, 16, "uint16")
+		if err != nil {
+			return err
+		}
+		*vt = uint16(n)
+	case *uint8:
+		n, err := r.scanUintN(i, 8, "uint8")
+		if err != nil {
+			return err
+		}
+		*vt = uint8(n)
+	case *uint:
+		n, err := r.scanUintN(i, strconv.IntSize, "uint")
+		if err != nil {
+			return err
+		}
+		*vt = uint(n)
+	case *bool:
+		n, err := r.scanInt64(i)
+		if err != nil {
+			return fmt.Errorf("invalid boolean: %q", r[i])
+		}
+		*vt = n != 0
+	case *float64:
+		n, err := r.scanFloat64(i)
+		if err != nil {
+			return err
+		}
+		*vt = n
+	case *float32:
+		n, err := r.scanFloat64(i)
+		if err != nil {
+			return err
+		}
+		if math.Abs(n) > math.MaxFloat32 && !math.IsInf(n, 0) {
+			return fmt.Errorf("number out of range for float32: %g", n)
+		}
+		*vt = float32(n)
+	case *time.Time:
+		t, err := r.scanTime(o, i)
+		if err != nil {
+			return err
+		}
+		*vt = t
+	case *time.Duration:
+		d, err := r.scanDuration(o, i)
+		if err != nil {
+			return err
+		}
+		*vt = d
+	case *sql.NullTime:
+		// sql.NullTime.Scan() only takes time.Time values
+		if r[i] == nil {
+			*vt = sql.NullTime{}
+			return nil
+		}
+		t, err := r.scanTime(o, i)
+		if err != nil {
+			return err
+		}
+		*vt = sql.NullTime{Time: t, Valid: true}
+	case sql.Scanner:
+		return vt.Scan(copyValue(r[i]))
+	case encoding.TextUnmarshaler:
+		return vt.UnmarshalText(r.scanBytes(i))
+	case encoding.BinaryUnmarshaler:
+		return vt.UnmarshalBinary(r.scanBytes(i))
+	default:
+		p := reflect.ValueOf(v)
+		if p.Kind() != reflect.Ptr || p.IsNil() || p.Elem().Kind() != reflect.Ptr {
 			return fmt.Errorf("unsupported Scan() type: %T", v)
 		}
+		if r[i] == nil {
+			p.Elem().Set(reflect.Zero(p.Elem().Type()))
+			return nil
+		}
+		n := reflect.New(p.Elem().Type().Elem())
+		if err := r.scan(o, i, n.Interface()); err != nil {
+			return err
+		}
+		p.Elem().Set(n)
 	}
 	return nil
 }
 
-func (r Row) scanString(i int) string {
-	if len(r) <= i {
-		return "" // Or error?
+// scanIntN scans an integer which needs to fit in a signed type with bits
+// bits.
+func (r Row) scanIntN(i int, bits uint, typ string) (int64, error) {
+	n, err := r.scanInt64(i)
+	if err != nil {
+		return 0, err
+	}
+	if bits < 64 && (n < -1<<(bits-1) || n > 1<<(bits-1)-1) {
+		return 0, fmt.Errorf("number out of range for %s: %d", typ, n)
 	}
+	return n, nil
+}
+
+// scanUintN scans an integer which needs to fit in an unsigned type with bits
+// bits.
+func (r Row) scanUintN(i int, bits uint, typ string) (uint64, error) {
+	// strings can be larger than an int64
+	rv, ok := r[i].(string)
+	n, err := strconv.ParseUint(rv, 10, 64)
+	if !ok || err != nil {
+		s, err := r.scanInt64(i)
+		if err != nil {
+			return 0, err
+		}
+		if s < 0 {
+			return 0, fmt.Errorf("number out of range for %s: %d", typ, s)
+		}
+		n = uint64(s)
+	}
+	if bits < 64 && n > 1<<bits-1 {
+		return 0, fmt.Errorf("number out of range for %s: %d", typ, n)
+	}
+	return n, nil
+}
+
+func (r Row) scanString(i int) string {
 	switch rv := r[i].(type) {
 	case nil:
 		return ""
@@ -109,9 +274,6 @@ func (r Row) scanString(i int) string {
 }
 
 func (r Row) scanBytes(i int) []byte {
-	if len(r) <= i {
-		return nil // Or error?
-	}
 	switch rv := r[i].(type) {
 	case nil:
 		return nil
@@ -122,22 +284,22 @@ func (r Row) scanBytes(i int) []byte {
 	case string:
 		return []byte(rv)
 	case []byte:
-		return rv
+		return append([]byte(nil), rv...)
 	default:
 		panic("impossible")
 	}
 }
 
 func (r Row) scanInt64(i int) (int64, error) {
-	if len(r) <= i {
-		return 0, nil // Or error?
-	}
 	switch rv := r[i].(type) {
 	case nil:
 		return 0, nil
 	case int64:
 		return rv, nil
 	case float64:
+		if rv < -1<<63 || rv >= 1<<63 {
+			return 0, fmt.Errorf("number out of range for int64: %g", rv)
+		}
 		return int64(rv), nil
 	case string:
 		vt, err := stringToInt64(rv)
@@ -157,9 +319,6 @@ func (r Row) scanInt64(i int) (int64, error) {
 }
 
 func (r Row) scanFloat64(i int) (float64, error) {
-	if len(r) <= i {
-		return 0, nil // Or error?
-	}
 	switch rv := r[i].(type) {
 	case nil:
 		return 0, nil
@@ -184,37 +343,6 @@ func (r Row) scanFloat64(i int) (float64, error) {
 	}
 }
 
-func (r Row) scanTime(i int) (time.Time, error) {
-	if len(r) <= i {
-		return time.Time{}, nil // Or error?
-	}
-	switch rv := r[i].(type) {
-	case nil:
-		return time.Time{}, nil
-	case int64:
-		return time.Unix(rv, 0), nil
-	case float64:
-		// Should be:
-		// "the number of days since noon in Greenwich on November 24, 4714
-		// B.C. according to the proleptic Gregorian calendar"
-		return time.Time{}, errors.New("float timestamps not supported")
-	case string:
-		// docs specify with milliseconds, but my sqlite3 doesn't add those
-		if t, err := time.Parse("2006-01-02 15:04:05", rv); err == nil {
-			return t, nil
-		}
-		t, err := time.Parse("2006-01-02 15:04:05.000", rv)
-		if err != nil {
-			return time.Time{}, fmt.Errorf("invalid time: %q", rv)
-		}
-		return t, nil
-	case []byte:
-		return time.Time{}, errors.New("BLOB timestamps are invalid")
-	default:
-		panic("impossible")
-	}
-}
-
 // ScanString is a shortcut for row.Scan(&string)
 func (r Row) ScanString() (string, error) {
 	var s1 string
@@ -243,5 +371,11 @@ func stringToInt64(s string) (int64, error) {
 		return v, nil
 	}
 	f, err := strconv.ParseFloat(s, 64)
-	return int64(f), err
+	if err != nil {
+		return 0, err
+	}
+	if f < -1<<63 || f >= 1<<63 {
+		return 0, errors.New("out of range")
+	}
+	return int64(f), nil
 }
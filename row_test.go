@@ -1,11 +1,18 @@
 package sqlittle
 
 import (
+	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/andreyvit/diff"
+	"github.com/davecgh/go-spew/spew"
 )
 
 func TestScanNil(t *testing.T) {
@@ -122,10 +129,15 @@ func TestScanInt32(t *testing.T) {
 	}
 	test(nil, 0)
 	test(int64(42), 42)
-	test(int64(1<<42+1<<4), 1<<4)
+	test(int64(-1<<31), -1<<31)
 	test(float64(3.14), 3)
 	test("-3.14", -3)
 	test([]byte("2.71828"), 2)
+
+	var n int32
+	if have, want := (Row{int64(1<<42 + 1<<4)}).Scan(&n), errors.New("number out of range for int32: 4398046511120"); !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
 }
 
 func TestScanInt(t *testing.T) {
@@ -201,7 +213,8 @@ func TestScanTime(t *testing.T) {
 	test("1999-02-22 23:45:34.333", time.Date(1999, 2, 22, 23, 45, 34, 333000000, time.UTC))
 	fail([]byte("aaa"), errors.New("BLOB timestamps are invalid"))
 	fail("aaa", errors.New(`invalid time: "aaa"`))
-	fail(float64(3.14), errors.New("float timestamps not supported")) // TODO
+	test(float64(2440587.5), time.Unix(0, 0))
+	fail(float64(-1), errors.New("invalid julian day: -1"))
 }
 
 func TestScanStrings(t *testing.T) {
@@ -217,3 +230,227 @@ func TestScanStrings(t *testing.T) {
 	test(Row{"foo", "bar"}, []string{"foo", "bar"})
 	test(Row{"foo", "bar", int64(12)}, []string{"foo", "bar", "12"})
 }
+
+func TestScanNarrow(t *testing.T) {
+	var (
+		i8  int8
+		i16 int16
+		u   uint
+		u8  uint8
+		u16 uint16
+		u32 uint32
+		u64 uint64
+		f32 float32
+	)
+	for _, c := range []struct {
+		v    interface{}
+		dst  interface{}
+		want interface{}
+	}{
+		{int64(-128), &i8, int8(-128)},
+		{"127", &i8, int8(127)},
+		{int64(-32768), &i16, int16(-32768)},
+		{int64(42), &u, uint(42)},
+		{float64(255.5), &u8, uint8(255)},
+		{int64(65535), &u16, uint16(65535)},
+		{int64(1<<32 - 1), &u32, uint32(1<<32 - 1)},
+		{int64(1<<63 - 1), &u64, uint64(1<<63 - 1)},
+		{"18446744073709551615", &u64, uint64(1<<64 - 1)},
+		{[]byte("12"), &u64, uint64(12)},
+		{nil, &u64, uint64(0)},
+		{float64(1.5), &f32, float32(1.5)},
+		{"-2.5", &f32, float32(-2.5)},
+	} {
+		if err := (Row{c.v}).Scan(c.dst); err != nil {
+			t.Fatalf("%v: %s", c.v, err)
+		}
+		if have := reflect.ValueOf(c.dst).Elem().Interface(); have != c.want {
+			t.Errorf("have %v, want %v", have, c.want)
+		}
+	}
+
+	for _, c := range []struct {
+		v   interface{}
+		dst interface{}
+		err error
+	}{
+		{int64(128), &i8, errors.New("number out of range for int8: 128")},
+		{int64(-129), &i8, errors.New("number out of range for int8: -129")},
+		{int64(40000), &i16, errors.New("number out of range for int16: 40000")},
+		{int64(-1), &u, errors.New("number out of range for uint: -1")},
+		{int64(256), &u8, errors.New("number out of range for uint8: 256")},
+		{int64(1 << 32), &u32, errors.New("number out of range for uint32: 4294967296")},
+		{"-1", &u64, errors.New("number out of range for uint64: -1")},
+		{float64(1e30), &u64, errors.New("number out of range for int64: 1e+30")},
+		{"hi", &u64, errors.New(`invalid number: "hi"`)},
+		{"1e30", &i8, errors.New(`invalid number: "1e30"`)},
+		{float64(1e40), &f32, errors.New("number out of range for float32: 1e+40")},
+	} {
+		if have, want := (Row{c.v}).Scan(c.dst), c.err; !reflect.DeepEqual(have, want) {
+			t.Errorf("%v: have %v, want %v", c.v, have, want)
+		}
+	}
+}
+
+type upper string
+
+func (u *upper) UnmarshalText(b []byte) error {
+	*u = upper(strings.ToUpper(string(b)))
+	return nil
+}
+
+type blob []byte
+
+func (b *blob) UnmarshalBinary(v []byte) error {
+	if len(v) == 0 {
+		return errors.New("empty")
+	}
+	*b = append(blob{'#'}, v...)
+	return nil
+}
+
+type scanner struct {
+	v interface{}
+}
+
+func (s *scanner) Scan(v interface{}) error {
+	s.v = v
+	return nil
+}
+
+func TestScanNull(t *testing.T) {
+	var (
+		ps   *string
+		pi   *int64
+		ppi  **int
+		ns   sql.NullString
+		ni   sql.NullInt64
+		ni32 sql.NullInt32
+		nf   sql.NullFloat64
+		nb   sql.NullBool
+		nt   sql.NullTime
+	)
+	for _, c := range []struct {
+		row  Row
+		args []interface{}
+		want []interface{}
+	}{
+		{
+			row:  Row{nil, nil, nil},
+			args: []interface{}{&ps, &pi, &ppi},
+			want: []interface{}{(*string)(nil), (*int64)(nil), (**int)(nil)},
+		},
+		{
+			row:  Row{"hi", int64(3), int64(4)},
+			args: []interface{}{&ps, &pi, &ppi},
+			want: []interface{}{sp("hi"), ip(3), ipp(4)},
+		},
+		{
+			row:  Row{nil, nil, nil, nil, nil, nil},
+			args: []interface{}{&ns, &ni, &ni32, &nf, &nb, &nt},
+			want: []interface{}{sql.NullString{}, sql.NullInt64{}, sql.NullInt32{}, sql.NullFloat64{}, sql.NullBool{}, sql.NullTime{}},
+		},
+		{
+			row:  Row{[]byte("hi"), "12", int64(-3), int64(2), int64(1), "2018-01-02 03:04:05"},
+			args: []interface{}{&ns, &ni, &ni32, &nf, &nb, &nt},
+			want: []interface{}{
+				sql.NullString{String: "hi", Valid: true},
+				sql.NullInt64{Int64: 12, Valid: true},
+				sql.NullInt32{Int32: -3, Valid: true},
+				sql.NullFloat64{Float64: 2, Valid: true},
+				sql.NullBool{Bool: true, Valid: true},
+				sql.NullTime{Time: time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC), Valid: true},
+			},
+		},
+	} {
+		if err := c.row.Scan(c.args...); err != nil {
+			t.Fatal(err)
+		}
+		var have []interface{}
+		for _, a := range c.args {
+			have = append(have, reflect.ValueOf(a).Elem().Interface())
+		}
+		if !reflect.DeepEqual(have, c.want) {
+			t.Errorf("diff:\n%s", diff.LineDiff(spew.Sdump(c.want), spew.Sdump(have)))
+		}
+	}
+
+	// sql.NullInt32.Scan() does the range check
+	if err := (Row{int64(1 << 40)}).Scan(&ni32); err == nil {
+		t.Error("no error")
+	}
+}
+
+func sp(s string) *string { return &s }
+func ip(n int64) *int64   { return &n }
+func ipp(n int) **int {
+	p := &n
+	return &p
+}
+
+func TestScanUnmarshal(t *testing.T) {
+	var (
+		u   upper
+		b   blob
+		raw json.RawMessage
+		s   scanner
+		ip  net.IP
+	)
+	row := Row{"foo", []byte("bar"), []byte(`{"a": 1}`), []byte("baz"), "10.0.0.1"}
+	if err := row.Scan(&u, &b, &raw, &s, &ip); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := u, upper("FOO"); have != want {
+		t.Errorf("have %v, want %v", have, want)
+	}
+	if have, want := b, blob("#bar"); !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+	if have, want := raw, json.RawMessage(`{"a": 1}`); !reflect.DeepEqual(have, want) {
+		t.Errorf("have %s, want %s", have, want)
+	}
+	if have, want := s.v, []byte("baz"); !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+	if have, want := ip, net.IPv4(10, 0, 0, 1); !have.Equal(want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+
+	// values are copies
+	page := []byte("bar")
+	if err := (Row{page, page}).Scan(&b, &s); err != nil {
+		t.Fatal(err)
+	}
+	page[0] = 'X'
+	if have, want := s.v, []byte("bar"); !reflect.DeepEqual(have, want) {
+		t.Errorf("have %s, want %s", have, want)
+	}
+	if have, want := b, blob("#bar"); !reflect.DeepEqual(have, want) {
+		t.Errorf("have %s, want %s", have, want)
+	}
+
+	for _, c := range []struct {
+		row Row
+		dst interface{}
+		err error
+	}{
+		{Row{nil}, &b, errors.New("empty")},
+		{Row{int64(1)}, &struct{}{}, errors.New("unsupported Scan() type: *struct {}")},
+		{Row{int64(1)}, new(*struct{}), errors.New("unsupported Scan() type: *struct {}")},
+	} {
+		if have, want := c.row.Scan(c.dst), c.err; !reflect.DeepEqual(have, want) {
+			t.Errorf("have %v, want %v", have, want)
+		}
+	}
+}
+
+func TestScanPastEnd(t *testing.T) {
+	var a, b string
+	if have, want := (Row{"foo"}).Scan(&a, &b), errors.New("can't scan column 1: row has 1 columns"); !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+	// skipped columns are fine
+	if err := (Row{"foo"}).Scan(&a, nil); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,218 @@
+package sqlittle
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	sdb "github.com/hackborn/sqlittle/db"
+)
+
+// EstimateCount guesses the number of rows in a table, without reading the
+// whole table. It follows a few random paths down the btree and extrapolates
+// from the pages it found. For big tables that's much faster than Count(), but
+// it can easily be off by tens of percents. Tables which fit on a single page
+// are counted exactly.
+func (db *DB) EstimateCount(table string) (int, error) {
+	return db.EstimateCountContext(context.Background(), table)
+}
+
+// EstimateCountContext is EstimateCount() with a context. See
+// SelectContext().
+func (db *DB) EstimateCountContext(ctx context.Context, table string) (n int, err error) {
+	d, c, err := db.rlock(ctx, "EstimateCount", table, "")
+	if err != nil {
+		return 0, err
+	}
+	defer c.end(&err)
+
+	s, err := d.Schema(table)
+	if err != nil {
+		return 0, err
+	}
+	if s.WithoutRowid {
+		t, err := d.NonRowidTable(s.Table)
+		if err != nil {
+			return 0, err
+		}
+		return t.EstimateCount()
+	}
+	t, err := d.Table(s.Table)
+	if err != nil {
+		return 0, err
+	}
+	return t.EstimateCount()
+}
+
+// Sample calls the callback for n random rows from the table. See SampleRand.
+func (db *DB) Sample(table string, n int, cb RowCB, columns ...string) error {
+	return db.SampleContext(context.Background(), table, n, cb, columns...)
+}
+
+// SampleContext is Sample() with a context. See SelectContext().
+func (db *DB) SampleContext(ctx context.Context, table string, n int, cb RowCB, columns ...string) error {
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+	return db.SampleRandContext(ctx, table, n, rnd, cb, columns...)
+}
+
+// SampleRand calls the callback for n random rows from the table, using rnd for
+// all random choices. With the same seed and an unchanged database the same
+// rows are given.
+//
+// The rows are found by following random paths down the btree, so big tables
+// aren't read completely. The rows are approximately uniform: rows on pages
+// with fewer rows than their sibling pages are a bit more likely to be picked.
+// No row is given twice. When the table is small all rows are read, and the
+// sample is exactly uniform. There can be fewer than n rows when the table
+// doesn't have n rows, or when the random paths keep finding the same rows.
+func (db *DB) SampleRand(table string, n int, rnd *rand.Rand, cb RowCB, columns ...string) error {
+	return db.SampleRandContext(context.Background(), table, n, rnd, cb, columns...)
+}
+
+// SampleRandContext is SampleRand() with a context. See SelectContext().
+func (db *DB) SampleRandContext(ctx context.Context, table string, n int, rnd *rand.Rand, cb RowCB, columns ...string) (err error) {
+	if n < 0 {
+		return fmt.Errorf("invalid number of rows: %d", n)
+	}
+	d, c, err := db.rlock(ctx, "SampleRand", table, "")
+	if err != nil {
+		return err
+	}
+	defer c.end(&err)
+	cb = c.count(cb)
+
+	s, err := d.Schema(table)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return nil
+	}
+	if s.WithoutRowid {
+		return sampleNonRowid(d, s, n, rnd, cb, columns)
+	}
+	return sample(d, s, n, rnd, cb, columns)
+}
+
+// number of random paths per wanted row before Sample gives up
+const sampleTries = 10
+
+func sample(db *sdb.Database, s *sdb.Schema, n int, rnd *rand.Rand, cb RowCB, columns []string) error {
+	ci, err := toColumnIndexRowid(s, columns)
+	if err != nil {
+		return err
+	}
+	t, err := db.Table(s.Table)
+	if err != nil {
+		return err
+	}
+	est, err := t.EstimateCount()
+	if err != nil {
+		return err
+	}
+
+	if est <= 2*n {
+		var res reservoir
+		if err := t.Scan(func(rowid int64, r sdb.Record) bool {
+			res.add(rnd, n, toRow(rowid, ci, r))
+			return false
+		}); err != nil {
+			return err
+		}
+		res.flush(rnd, cb)
+		return nil
+	}
+
+	seen := map[int64]bool{}
+	for try := 0; try < n*sampleTries && len(seen) < n; try++ {
+		rowid, r, err := t.Random(rnd)
+		if err != nil {
+			return err
+		}
+		if r == nil || seen[rowid] {
+			continue
+		}
+		seen[rowid] = true
+		cb(toRow(rowid, ci, r))
+	}
+	return nil
+}
+
+func sampleNonRowid(db *sdb.Database, s *sdb.Schema, n int, rnd *rand.Rand, cb RowCB, columns []string) error {
+	ci, err := toColumnIndexNonRowid(s, columns)
+	if err != nil {
+		return err
+	}
+	t, err := db.NonRowidTable(s.Table)
+	if err != nil {
+		return err
+	}
+	est, err := t.EstimateCount()
+	if err != nil {
+		return err
+	}
+
+	if est <= 2*n {
+		var res reservoir
+		if err := t.Scan(func(r sdb.Record) bool {
+			res.add(rnd, n, toRow(0, ci, r))
+			return false
+		}); err != nil {
+			return err
+		}
+		res.flush(rnd, cb)
+		return nil
+	}
+
+	seen := map[string]bool{}
+	for try := 0; try < n*sampleTries && len(seen) < n; try++ {
+		r, err := t.Random(rnd)
+		if err != nil {
+			return err
+		}
+		if r == nil {
+			continue
+		}
+		// the primary key columns come first in the record
+		var b strings.Builder
+		for _, v := range r[:len(s.PK)] {
+			writeKey(&b, v, "binary")
+		}
+		k := b.String()
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		cb(toRow(0, ci, r))
+	}
+	return nil
+}
+
+// reservoir keeps a uniform random sample of all rows added.
+type reservoir struct {
+	rows []Row
+	seen int
+}
+
+func (res *reservoir) add(rnd *rand.Rand, n int, r Row) {
+	res.seen++
+	if len(res.rows) < n {
+		res.rows = append(res.rows, copyRow(r))
+		return
+	}
+	if i := rnd.Intn(res.seen); i < n {
+		res.rows[i] = copyRow(r)
+	}
+}
+
+// flush gives the rows in random order.
+func (res *reservoir) flush(rnd *rand.Rand, cb RowCB) {
+	rnd.Shuffle(len(res.rows), func(i, j int) {
+		res.rows[i], res.rows[j] = res.rows[j], res.rows[i]
+	})
+	for _, r := range res.rows {
+		cb(r)
+	}
+}
@@ -0,0 +1,120 @@
+package sqlittle
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestEstimateCount(t *testing.T) {
+	for file, want := range map[string]int{
+		"testdata/words.sqlite":        1000,
+		"testdata/withoutrowid.sqlite": 1000,
+	} {
+		db, err := Open(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n, err := db.EstimateCount("words")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n < want/2 || n > want*2 {
+			t.Errorf("%s: have %d, want about %d", file, n, want)
+		}
+		db.Close()
+	}
+
+	db, err := Open("testdata/music.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	n, err := db.EstimateCount("tracks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := n, 6; have != want {
+		t.Errorf("have %d, want %d", have, want)
+	}
+	if _, err := db.EstimateCount("nosuch"); err == nil {
+		t.Errorf("expected an error")
+	}
+}
+
+func TestSample(t *testing.T) {
+	for _, file := range []string{
+		"testdata/words.sqlite",
+		"testdata/withoutrowid.sqlite",
+	} {
+		db, err := Open(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sample := func(seed int64, n int) []string {
+			var words []string
+			if err := db.SampleRand("words", n, rand.New(rand.NewSource(seed)), func(r Row) {
+				var (
+					w string
+					l int
+				)
+				if err := r.Scan(&w, &l); err != nil {
+					t.Fatal(err)
+				}
+				if utf8.RuneCountInString(w) != l {
+					t.Fatalf("%s: unexpected row: %v", file, r)
+				}
+				words = append(words, w)
+			}, "word", "length"); err != nil {
+				t.Fatal(err)
+			}
+			return words
+		}
+
+		// random paths
+		a := sample(42, 50)
+		if have, want := len(a), 50; have != want {
+			t.Errorf("%s: have %d, want %d", file, have, want)
+		}
+		seen := map[string]bool{}
+		for _, w := range a {
+			if seen[w] {
+				t.Errorf("%s: %q twice", file, w)
+			}
+			seen[w] = true
+		}
+		if have, want := sample(42, 50), a; !reflect.DeepEqual(have, want) {
+			t.Errorf("%s: same seed, have %v, want %v", file, have, want)
+		}
+		if b := sample(43, 50); reflect.DeepEqual(b, a) {
+			t.Errorf("%s: different seed, same sample", file)
+		}
+
+		// reads the whole table
+		if have, want := len(sample(42, 900)), 900; have != want {
+			t.Errorf("%s: have %d, want %d", file, have, want)
+		}
+		if have, want := len(sample(42, 2000)), 1000; have != want {
+			t.Errorf("%s: have %d, want %d", file, have, want)
+		}
+		db.Close()
+	}
+
+	db, err := Open("testdata/empty.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	n := 0
+	if err := db.Sample("foo", 10, func(Row) { n++ }); err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 {
+		t.Errorf("have %d, want 0", n)
+	}
+	if err := db.Sample("foo", -1, func(Row) {}); err == nil {
+		t.Errorf("expected an error")
+	}
+}
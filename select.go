@@ -2,11 +2,12 @@ package sqlittle
 
 import (
 	"errors"
+	"math"
 
 	sdb "github.com/hackborn/sqlittle/db"
 )
 
-func select_(db *sdb.Database, s *sdb.Schema, cb RowCB, columns []string) error {
+func select_(db *sdb.Database, s *sdb.Schema, e *emitter, columns []string) error {
 	ci, err := toColumnIndexRowid(s, columns)
 	if err != nil {
 		return err
@@ -16,13 +17,26 @@ func select_(db *sdb.Database, s *sdb.Schema, cb RowCB, columns []string) error
 	if err != nil {
 		return err
 	}
-	return t.Scan(func(rowid int64, r sdb.Record) bool {
-		cb(toRow(rowid, ci, r))
+	cb := func(rowid int64, r sdb.Record) bool {
+		if e.emit(rowid, ci, r) {
+			return e.stop(sdb.Record{rowid})
+		}
 		return false
-	})
+	}
+	if e.after != nil {
+		rowid, ok := e.after[0].(int64)
+		if !ok || len(e.after) != 1 {
+			return ErrInvalidToken
+		}
+		if rowid == math.MaxInt64 {
+			return nil
+		}
+		return t.ScanMin(rowid+1, cb)
+	}
+	return t.Scan(cb)
 }
 
-func selectNonRowid(db *sdb.Database, s *sdb.Schema, cb RowCB, columns []string) error {
+func selectNonRowid(db *sdb.Database, s *sdb.Schema, e *emitter, columns []string) error {
 	ci, err := toColumnIndexNonRowid(s, columns)
 	if err != nil {
 		return err
@@ -32,55 +46,77 @@ func selectNonRowid(db *sdb.Database, s *sdb.Schema, cb RowCB, columns []string)
 	if err != nil {
 		return err
 	}
-	return t.Scan(func(r sdb.Record) bool {
-		cb(toRow(0, ci, r))
+	// the primary key columns come first in the record
+	cb := func(r sdb.Record) bool {
+		if e.emit(0, ci, r) {
+			return e.stop(r[:len(s.PK)])
+		}
 		return false
-	})
+	}
+	if e.after != nil {
+		if len(e.after) != len(s.PK) {
+			return ErrInvalidToken
+		}
+		from, err := asDbKey(Key(e.after), s.PK)
+		if err != nil {
+			return err
+		}
+		return t.ScanAfter(from, cb)
+	}
+	return t.Scan(cb)
 }
 
 func selectRowid(db *sdb.Database, s *sdb.Schema, rowid int64, columns []string) (Row, error) {
+	var row Row
+	e := &emitter{ncols: len(columns), cb: func(r Row) { row = r }}
+	// TODO: decide what to do with shared []byte pointers
+	return row, emitRowid(db, s, rowid, e, columns)
+}
+
+// emitRowid emits the row with the given rowid, if it exists.
+func emitRowid(db *sdb.Database, s *sdb.Schema, rowid int64, e *emitter, columns []string) error {
 	ci, err := toColumnIndexRowid(s, columns)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	t, err := db.Table(s.Table)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	r, err := t.Rowid(rowid)
 	if err != nil || r == nil {
-		return nil, err
+		return err
 	}
-	// TODO: decide what to do with shared []byte pointers
-	return toRow(rowid, ci, r), nil
+	e.emit(rowid, ci, r)
+	return nil
 }
 
-func pkSelect(db *sdb.Database, s *sdb.Schema, key Key, cb RowCB, columns []string) error {
+func pkSelect(db *sdb.Database, s *sdb.Schema, key Key, e *emitter, columns []string) error {
 	if s.RowidPK {
 		// `integer primary key` table.
-		var rowid int64
 		if len(key) == 0 {
 			return errors.New("invalid key")
 		}
-		rowid, ok := key[0].(int64)
-		if !ok {
-			return errors.New("invalid key")
-		}
-		row, err := selectRowid(db, s, rowid, columns)
+		v, err := toKeyValue(key[0], affInteger, e.scan)
 		if err != nil {
 			return err
 		}
-		if row != nil {
-			cb(row)
+		rowid, ok := v.(int64)
+		if !ok {
+			return errors.New("invalid key")
 		}
-		return nil
+		return emitRowid(db, s, rowid, e, columns)
 	}
 	ind := s.NamedIndex(s.PrimaryKey)
 	if ind == nil {
 		return errors.New("table has no primary key")
 	}
 
+	key, err := keyValues(s, ind.Columns, key, e.scan)
+	if err != nil {
+		return err
+	}
 	dbkey, err := asDbKey(key, ind.Columns)
 	if err != nil {
 		return err
@@ -91,12 +127,12 @@ func pkSelect(db *sdb.Database, s *sdb.Schema, key Key, cb RowCB, columns []stri
 		s,
 		ind,
 		dbkey,
-		cb,
+		e,
 		columns,
 	)
 }
 
-func pkSelectNonRowid(db *sdb.Database, s *sdb.Schema, key Key, cb RowCB, columns []string) error {
+func pkSelectNonRowid(db *sdb.Database, s *sdb.Schema, key Key, e *emitter, columns []string) error {
 	ci, err := toColumnIndexNonRowid(s, columns)
 	if err != nil {
 		return err
@@ -106,6 +142,9 @@ func pkSelectNonRowid(db *sdb.Database, s *sdb.Schema, key Key, cb RowCB, column
 		return err
 	}
 
+	if key, err = keyValues(s, s.PK, key, e.scan); err != nil {
+		return err
+	}
 	dbkey, err := asDbKey(key, s.PK)
 	if err != nil {
 		return err
@@ -114,7 +153,7 @@ func pkSelectNonRowid(db *sdb.Database, s *sdb.Schema, key Key, cb RowCB, column
 	return t.ScanEq(
 		dbkey,
 		func(r sdb.Record) bool {
-			cb(toRow(0, ci, r))
+			e.emit(0, ci, r)
 			return false
 		},
 	)
@@ -1,8 +1,11 @@
 package sqlittle
 
 import (
+	"context"
 	"errors"
+	"math/rand"
 	"reflect"
+	"sync/atomic"
 	"testing"
 
 	"github.com/andreyvit/diff"
@@ -310,3 +313,96 @@ func TestSelectOverflow(t *testing.T) {
 
 	db.Select("test", func(row Row) {}, "id")
 }
+
+func TestSelectContext(t *testing.T) {
+	db, err := Open("testdata/words.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	done, cancel := context.WithCancel(context.Background())
+	cancel()
+	cb := func(Row) {}
+	for name, err := range map[string]error{
+		"select":  db.SelectContext(done, "words", cb, "word"),
+		"index":   db.IndexedSelectContext(done, "words", "words_index_1", cb, "word"),
+		"eq":      db.IndexedSelectEqContext(done, "words", "words_index_2", Key{4}, cb, "word"),
+		"pk":      db.PKSelectContext(done, "words", Key{int64(1)}, cb, "word"),
+		"where":   db.WhereContext(done, "words", []Cond{Eq("length", 4)}, cb, "word"),
+		"withctx": db.SelectWithContext(done, "words", Options{Limit: 2}, cb, "word"),
+		"join": db.JoinContext(done,
+			JoinSpec{Table: "words", Columns: []string{"word"}},
+			JoinSpec{Table: "words", Columns: []string{"word"}},
+			[]ColumnPair{{Left: "word", Right: "word"}}, cb),
+		"leftjoin": db.LeftJoinContext(done,
+			JoinSpec{Table: "words", Columns: []string{"word"}},
+			JoinSpec{Table: "words", Columns: []string{"word"}},
+			[]ColumnPair{{Left: "word", Right: "word"}}, cb),
+		"aggregate":  db.AggregateContext(done, "words", nil, []Agg{Count("*")}, cb),
+		"distinct":   db.DistinctContext(done, "words", "words_index_2", 1, cb),
+		"parallel":   db.SelectParallelContext(done, "words", 2, cb, "word"),
+		"sample":     db.SampleContext(done, "words", 10, cb, "word"),
+		"samplerand": db.SampleRandContext(done, "words", 10, rand.New(rand.NewSource(1)), cb, "word"),
+	} {
+		if have, want := err, context.Canceled; have != want {
+			t.Errorf("%s: have %v, want %v", name, have, want)
+		}
+	}
+	if _, have := db.SelectRowidContext(done, "words", 1, "word"); have != context.Canceled {
+		t.Errorf("have %v, want %v", have, context.Canceled)
+	}
+	if _, have := db.CountContext(done, "words"); have != context.Canceled {
+		t.Errorf("have %v, want %v", have, context.Canceled)
+	}
+	if _, have := db.EstimateCountContext(done, "words"); have != context.Canceled {
+		t.Errorf("have %v, want %v", have, context.Canceled)
+	}
+	if _, have := db.IndexesContext(done, "words"); have != context.Canceled {
+		t.Errorf("have %v, want %v", have, context.Canceled)
+	}
+	if _, have := db.FindIndexContext(done, "words", "word"); have != context.Canceled {
+		t.Errorf("have %v, want %v", have, context.Canceled)
+	}
+	if _, have := db.ExplainContext(done, "words", nil); have != context.Canceled {
+		t.Errorf("have %v, want %v", have, context.Canceled)
+	}
+
+	// cancel from the callback
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	n := 0
+	err = db.IndexedSelectContext(ctx, "words", "words_index_2", func(Row) {
+		n++
+		if n == 10 {
+			cancel()
+		}
+	}, "word")
+	if have, want := err, context.Canceled; have != want {
+		t.Errorf("have %v, want %v", have, want)
+	}
+	if n >= 1000 {
+		t.Errorf("didn't stop: %d", n)
+	}
+
+	// parallel workers stop too
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	var pn int64
+	err = db.SelectParallelContext(ctx, "words", 2, func(Row) {
+		if atomic.AddInt64(&pn, 1) == 10 {
+			cancel()
+		}
+	}, "word")
+	if have, want := err, context.Canceled; have != want {
+		t.Errorf("have %v, want %v", have, want)
+	}
+	if pn >= 1000 {
+		t.Errorf("didn't stop: %d", pn)
+	}
+
+	// the lock is released
+	if err := db.Select("words", cb, "word"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,379 @@
+package sqlittle
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	sdb "github.com/hackborn/sqlittle/db"
+)
+
+// DefaultSortMemory is the memory budget for Options.OrderBy, if
+// Options.SortMemory isn't set.
+const DefaultSortMemory = 64 << 20
+
+// Order is a single term in Options.OrderBy.
+type Order struct {
+	Column string
+	Desc   bool
+	// Collate is the collate function to use ("binary", "nocase", or
+	// "rtrim"). Leave empty to use the collate function of the column.
+	Collate string
+}
+
+// Asc is a shortcut for Order{Column: column}
+func Asc(column string) Order { return Order{Column: column} }
+
+// Desc is a shortcut for Order{Column: column, Desc: true}
+func Desc(column string) Order { return Order{Column: column, Desc: true} }
+
+type sortKey struct {
+	pos     int // position in the row
+	desc    bool
+	collate string
+}
+
+// sorter is an external merge sort. Rows are kept in memory until they use
+// more than the budget, then they are sorted and written to a temporary file.
+// When all rows are in, all those sorted runs are merged.
+type sorter struct {
+	keys   []sortKey
+	budget int
+	rows   []Row
+	size   int // estimated bytes used by rows
+	runs   []*os.File
+	err    error
+}
+
+// newSorter makes a sorter for the order. The columns needed for the order are
+// added to the columns.
+func newSorter(s *sdb.Schema, order []Order, budget int, columns []string) (*sorter, []string, error) {
+	if budget <= 0 {
+		budget = DefaultSortMemory
+	}
+	st := &sorter{budget: budget}
+	for _, o := range order {
+		_, _, collate, err := lookupColumn(s, o.Column)
+		if err != nil {
+			return nil, nil, err
+		}
+		if o.Collate != "" {
+			collate = strings.ToLower(o.Collate)
+			if _, ok := sdb.CollateFuncs[collate]; !ok {
+				return nil, nil, fmt.Errorf("unknown collate function: %q", o.Collate)
+			}
+		}
+		st.keys = append(st.keys, sortKey{
+			pos:     len(columns),
+			desc:    o.Desc,
+			collate: collate,
+		})
+		columns = append(columns, o.Column)
+	}
+	return st, columns, nil
+}
+
+func (st *sorter) less(a, b Row) bool {
+	for _, k := range st.keys {
+		n := sdb.Compare(a[k.pos], b[k.pos], k.collate)
+		if k.desc {
+			n = -n
+		}
+		if n != 0 {
+			return n < 0
+		}
+	}
+	return false
+}
+
+// add adds a row. The row is copied, so it can be used after the scan
+// callback.
+func (st *sorter) add(r Row) {
+	if st.err != nil {
+		return
+	}
+	size := 24 + 16*len(r)
+	for i, v := range r {
+		switch v := v.(type) {
+		case string:
+			size += len(v)
+		case []byte:
+			r[i] = append([]byte(nil), v...)
+			size += len(v)
+		}
+	}
+	st.rows = append(st.rows, r)
+	st.size += size
+	if st.size > st.budget {
+		st.err = st.spill()
+	}
+}
+
+// spill sorts the rows in memory and writes them to a temporary file.
+func (st *sorter) spill() error {
+	f, err := ioutil.TempFile("", "sqlittle-sort-")
+	if err != nil {
+		return err
+	}
+	st.runs = append(st.runs, f)
+
+	st.sortRows()
+	w := bufio.NewWriter(f)
+	for _, r := range st.rows {
+		if err := writeRow(w, r); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	st.rows = nil
+	st.size = 0
+	return nil
+}
+
+func (st *sorter) sortRows() {
+	sort.SliceStable(st.rows, func(i, j int) bool {
+		return st.less(st.rows[i], st.rows[j])
+	})
+}
+
+// flush calls the callback for all rows, in order. Rows are cut to ncols. With
+// a limit other than 0 it stops after that many rows.
+func (st *sorter) flush(ncols, limit int, cb RowCB) error {
+	if st.err != nil {
+		return st.err
+	}
+	st.sortRows()
+	if len(st.runs) == 0 {
+		for i, r := range st.rows {
+			if limit > 0 && i == limit {
+				break
+			}
+			cb(r[:ncols])
+		}
+		return nil
+	}
+
+	// merge all runs, and what's left in memory as the last run
+	m := &merger{less: st.less}
+	for i, f := range st.runs {
+		size, err := f.Seek(0, io.SeekEnd)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		src := &fileRun{r: bufio.NewReader(f), size: uint64(size)}
+		if err := m.push(i, src); err != nil {
+			return err
+		}
+	}
+	if err := m.push(len(st.runs), &memRun{rows: st.rows}); err != nil {
+		return err
+	}
+	for n := 0; m.Len() > 0 && (limit == 0 || n < limit); n++ {
+		h := m.heads[0]
+		cb(h.row[:ncols])
+		next, err := h.src.next()
+		switch err {
+		case nil:
+			h.row = next
+			heap.Fix(m, 0)
+		case io.EOF:
+			heap.Pop(m)
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+// close removes all temporary files.
+func (st *sorter) close() {
+	for _, f := range st.runs {
+		f.Close()
+		os.Remove(f.Name())
+	}
+	st.runs = nil
+	st.rows = nil
+}
+
+// a sorted run of rows
+type run interface {
+	next() (Row, error) // io.EOF at the end
+}
+
+type memRun struct {
+	rows []Row
+}
+
+func (m *memRun) next() (Row, error) {
+	if len(m.rows) == 0 {
+		return nil, io.EOF
+	}
+	r := m.rows[0]
+	m.rows = m.rows[1:]
+	return r, nil
+}
+
+type fileRun struct {
+	r    *bufio.Reader
+	size uint64 // of the file
+}
+
+func (f *fileRun) next() (Row, error) {
+	return readRow(f.r, f.size)
+}
+
+type mergeHead struct {
+	row Row
+	n   int // run number, to keep the sort stable
+	src run
+}
+
+// merger is a heap with the next row of every run.
+type merger struct {
+	less  func(a, b Row) bool
+	heads []*mergeHead
+}
+
+func (m *merger) push(n int, src run) error {
+	r, err := src.next()
+	switch err {
+	case nil:
+		heap.Push(m, &mergeHead{row: r, n: n, src: src})
+		return nil
+	case io.EOF:
+		return nil
+	default:
+		return err
+	}
+}
+
+func (m *merger) Len() int { return len(m.heads) }
+func (m *merger) Less(i, j int) bool {
+	a, b := m.heads[i], m.heads[j]
+	if m.less(a.row, b.row) {
+		return true
+	}
+	if m.less(b.row, a.row) {
+		return false
+	}
+	return a.n < b.n
+}
+func (m *merger) Swap(i, j int)      { m.heads[i], m.heads[j] = m.heads[j], m.heads[i] }
+func (m *merger) Push(x interface{}) { m.heads = append(m.heads, x.(*mergeHead)) }
+func (m *merger) Pop() interface{} {
+	h := m.heads[len(m.heads)-1]
+	m.heads = m.heads[:len(m.heads)-1]
+	return h
+}
+
+// value types in temporary files
+const (
+	tagNull byte = iota
+	tagInt
+	tagFloat
+	tagText
+	tagBlob
+)
+
+var errSortFile = errors.New("corrupt sort file")
+
+func writeRow(w *bufio.Writer, r Row) error {
+	var buf [binary.MaxVarintLen64]byte
+	writeUvarint := func(v uint64) {
+		n := binary.PutUvarint(buf[:], v)
+		w.Write(buf[:n])
+	}
+	writeUvarint(uint64(len(r)))
+	for _, v := range r {
+		switch v := v.(type) {
+		case nil:
+			w.WriteByte(tagNull)
+		case int64:
+			w.WriteByte(tagInt)
+			n := binary.PutVarint(buf[:], v)
+			w.Write(buf[:n])
+		case float64:
+			w.WriteByte(tagFloat)
+			writeUvarint(math.Float64bits(v))
+		case string:
+			w.WriteByte(tagText)
+			writeUvarint(uint64(len(v)))
+			w.WriteString(v)
+		case []byte:
+			w.WriteByte(tagBlob)
+			writeUvarint(uint64(len(v)))
+			w.Write(v)
+		default:
+			return fmt.Errorf("can't sort value of type %T", v)
+		}
+	}
+	// bufio.Writer remembers the first error
+	_, err := w.Write(nil)
+	return err
+}
+
+// readRow reads a row written by writeRow(). Gives io.EOF if there are no more
+// rows. max is the number of bytes there are at most, such as the size of the
+// file; longer lengths are an error, so a corrupt length can't allocate more.
+func readRow(r *bufio.Reader, max uint64) (Row, error) {
+	l, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	// every value has at least its tag byte
+	if l > max {
+		return nil, errSortFile
+	}
+	row := make(Row, l)
+	for i := range row {
+		tag, err := r.ReadByte()
+		if err != nil {
+			return nil, errSortFile
+		}
+		switch tag {
+		case tagNull:
+		case tagInt:
+			v, err := binary.ReadVarint(r)
+			if err != nil {
+				return nil, errSortFile
+			}
+			row[i] = v
+		case tagFloat:
+			v, err := binary.ReadUvarint(r)
+			if err != nil {
+				return nil, errSortFile
+			}
+			row[i] = math.Float64frombits(v)
+		case tagText, tagBlob:
+			n, err := binary.ReadUvarint(r)
+			if err != nil || n > max {
+				return nil, errSortFile
+			}
+			b := make([]byte, n)
+			if _, err := io.ReadFull(r, b); err != nil {
+				return nil, errSortFile
+			}
+			if tag == tagText {
+				row[i] = string(b)
+			} else {
+				row[i] = b
+			}
+		default:
+			return nil, errSortFile
+		}
+	}
+	return row, nil
+}
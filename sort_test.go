@@ -0,0 +1,217 @@
+package sqlittle
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/andreyvit/diff"
+	"github.com/davecgh/go-spew/spew"
+)
+
+func TestSelectOrderBy(t *testing.T) {
+	db, err := Open("testdata/words.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var words []string
+	cb := func(r Row) {
+		w, _ := r.ScanString()
+		words = append(words, w)
+	}
+
+	order := []Order{Desc("length"), {Column: "word", Collate: "nocase"}}
+	for _, mem := range []int{0, 1000} {
+		words = nil
+		opts := Options{OrderBy: order, SortMemory: mem}
+		if err := db.SelectWith("words", opts, cb, "word"); err != nil {
+			t.Fatal(err)
+		}
+		if have, want := len(words), 1000; have != want {
+			t.Fatalf("have %d, want %d", have, want)
+		}
+		want := []string{
+			"bloodthirstiness's",
+			"internationalism's",
+			"electrification's",
+			"unconsciousness's",
+			"authentication's",
+			"immaculateness's",
+			"multiplication's",
+			"mysteriousness's",
+		}
+		if have, want := words[:8], want; !reflect.DeepEqual(have, want) {
+			t.Errorf("mem %d diff:\n%s", mem, diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+		}
+	}
+
+	words = nil
+	opts := Options{
+		Where:   Eq("length", 4),
+		OrderBy: []Order{{Column: "word", Desc: true, Collate: "NOCASE"}},
+	}
+	if err := db.SelectWith("words", opts, cb, "word"); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"yard", "whoa", "weds", "taps", "shut", "sacs", "rags", "pi's",
+		"pets", "pass", "loci", "life", "Juan", "jamb", "icon", "Huns",
+		"hums", "hows", "hear", "Hahn", "gyro", "gist", "fora", "Eu's",
+		"dude", "drub", "Crux", "coil", "clot", "café", "brat",
+	}
+	if have, want := words, want; !reflect.DeepEqual(have, want) {
+		t.Errorf("diff:\n%s", diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+	}
+
+	// via an index, in the opposite order
+	words = nil
+	opts = Options{OrderBy: []Order{Asc("word")}}
+	if err := db.IndexedSelectEqWith("words", "words_index_2", Key{4}, opts, cb, "word"); err != nil {
+		t.Fatal(err)
+	}
+	want = []string{
+		"Crux", "Eu's", "Hahn", "Huns", "Juan", "brat", "café", "clot",
+		"coil", "drub", "dude", "fora", "gist", "gyro", "hear", "hows",
+		"hums", "icon", "jamb", "life", "loci", "pass", "pets", "pi's",
+		"rags", "sacs", "shut", "taps", "weds", "whoa", "yard",
+	}
+	if have, want := words, want; !reflect.DeepEqual(have, want) {
+		t.Errorf("diff:\n%s", diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+	}
+
+	cb = func(r Row) {}
+	opts = Options{OrderBy: []Order{Asc("nosuch")}}
+	if have, want := db.SelectWith("words", opts, cb, "word"), errors.New(`no such column: "nosuch"`); !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+	opts = Options{OrderBy: []Order{{Column: "word", Collate: "nosuch"}}}
+	if have, want := db.IndexedSelectWith("words", "words_index_1", opts, cb, "word"), errors.New(`unknown collate function: "nosuch"`); !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+}
+
+func TestSelectOrderBySpillError(t *testing.T) {
+	db, err := Open("testdata/words.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	rec := &callRecorder{}
+	db.SetObserver(rec)
+
+	// temporary files can't be made
+	t.Setenv("TMPDIR", t.TempDir()+"/nosuch")
+	opts := Options{OrderBy: []Order{Asc("word")}, SortMemory: 1000}
+	if err := db.SelectWith("words", opts, func(Row) {}, "word"); err == nil {
+		t.Fatal("no error")
+	}
+	// the scan stopped at the first spill
+	if n := rec.calls[0].RowsExamined; n >= 100 {
+		t.Errorf("examined %d rows", n)
+	}
+}
+
+func TestSorter(t *testing.T) {
+	random := func(r *rand.Rand) interface{} {
+		switch r.Intn(5) {
+		case 0:
+			return nil
+		case 1:
+			return int64(r.Intn(20))
+		case 2:
+			return float64(r.Intn(20)) / 2
+		case 3:
+			return string('a' + rune(r.Intn(20)))
+		default:
+			return []byte{byte(r.Intn(20))}
+		}
+	}
+
+	r := rand.New(rand.NewSource(42))
+	var rows []Row
+	for i := 0; i < 2000; i++ {
+		rows = append(rows, Row{random(r), random(r), int64(i)})
+	}
+
+	st := &sorter{
+		keys:   []sortKey{{pos: 0}, {pos: 1, desc: true}},
+		budget: 2000,
+	}
+	for _, r := range rows {
+		st.add(append(Row(nil), r...))
+	}
+	defer st.close()
+	if have, want := len(st.runs), 40; have < want {
+		t.Errorf("have %d runs, want at least %d", have, want)
+	}
+	files := []string{}
+	for _, f := range st.runs {
+		files = append(files, f.Name())
+	}
+
+	var have []Row
+	if err := st.flush(3, 0, func(r Row) {
+		have = append(have, r)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := append([]Row(nil), rows...)
+	sort.SliceStable(want, func(i, j int) bool {
+		return st.less(want[i], want[j])
+	})
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("diff:\n%s", diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+	}
+
+	st.close()
+	for _, f := range files {
+		if _, err := os.Stat(f); !os.IsNotExist(err) {
+			t.Errorf("file not removed: %s", f)
+		}
+	}
+}
+
+func TestRowEncoding(t *testing.T) {
+	rows := []Row{
+		{nil, int64(0), int64(-1 << 60), 3.14, "", "hello", []byte{}, []byte("bl\x00b")},
+		{},
+		{int64(42)},
+	}
+	var b bytes.Buffer
+	w := bufio.NewWriter(&b)
+	for _, r := range rows {
+		if err := writeRow(w, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w.Flush()
+
+	size := uint64(b.Len())
+	r := bufio.NewReader(&b)
+	for _, want := range rows {
+		have, err := readRow(r, size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(have, want) {
+			t.Errorf("have %#v, want %#v", have, want)
+		}
+	}
+	if _, err := readRow(r, size); err != io.EOF {
+		t.Errorf("have %v, want EOF", err)
+	}
+
+	if have, want := writeRow(w, Row{true}), errors.New("can't sort value of type bool"); !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+}
@@ -7,5 +7,8 @@ https://sqlite.org/lang_createindex.html
 
 It is used by sqlittle to read the table and index definitions embedded in
 `.sqlite` files.
+
+It also parses simple `SELECT` statements on a single table, with `WHERE`,
+`ORDER BY`, `LIMIT`, and `?` parameters, for the database/sql driver.
 */
 package sql
@@ -1,9 +1,12 @@
+// Code generated by goyacc -o parser.go parser.go.y. DO NOT EDIT.
+
 //line parser.go.y:2
 package sql
 
 import __yyfmt__ "fmt"
 
 //line parser.go.y:2
+
 //line parser.go.y:5
 type yySymType struct {
 	yys                  int
@@ -34,51 +37,59 @@ type yySymType struct {
 	expr                 Expression
 	exprList             []Expression
 	float                float64
+	orderingTerm         OrderingTerm
+	orderingTermList     []OrderingTerm
+	limit                limit
 }
 
 const ACTION = 57346
 const AND = 57347
 const ASC = 57348
 const AUTOINCREMENT = 57349
-const CASCADE = 57350
-const COLLATE = 57351
-const CONSTRAINT = 57352
-const CREATE = 57353
-const DEFAULT = 57354
-const DELETE = 57355
-const DESC = 57356
-const FOREIGN = 57357
-const FROM = 57358
-const GLOB = 57359
-const IN = 57360
-const INDEX = 57361
-const IS = 57362
-const KEY = 57363
-const LIKE = 57364
-const MATCH = 57365
-const NO = 57366
-const NOT = 57367
-const NULL = 57368
-const ON = 57369
-const OR = 57370
-const PRIMARY = 57371
-const REFERENCES = 57372
-const REGEXP = 57373
-const RESTRICT = 57374
-const ROWID = 57375
-const SELECT = 57376
-const SET = 57377
-const TABLE = 57378
-const UNIQUE = 57379
-const UPDATE = 57380
-const WHERE = 57381
-const WITHOUT = 57382
-const tBare = 57383
-const tLiteral = 57384
-const tIdentifier = 57385
-const tOperator = 57386
-const tSignedNumber = 57387
-const tFloat = 57388
+const BY = 57350
+const CASCADE = 57351
+const COLLATE = 57352
+const CONSTRAINT = 57353
+const CREATE = 57354
+const DEFAULT = 57355
+const DELETE = 57356
+const DESC = 57357
+const FOREIGN = 57358
+const FROM = 57359
+const GLOB = 57360
+const IN = 57361
+const INDEX = 57362
+const IS = 57363
+const KEY = 57364
+const LIKE = 57365
+const LIMIT = 57366
+const MATCH = 57367
+const NO = 57368
+const NOT = 57369
+const NULL = 57370
+const OFFSET = 57371
+const ON = 57372
+const OR = 57373
+const ORDER = 57374
+const PRIMARY = 57375
+const REFERENCES = 57376
+const REGEXP = 57377
+const RESTRICT = 57378
+const ROWID = 57379
+const SELECT = 57380
+const SET = 57381
+const TABLE = 57382
+const UNIQUE = 57383
+const UPDATE = 57384
+const WHERE = 57385
+const WITHOUT = 57386
+const tBare = 57387
+const tLiteral = 57388
+const tIdentifier = 57389
+const tOperator = 57390
+const tSignedNumber = 57391
+const tParam = 57392
+const tFloat = 57393
 
 var yyToknames = [...]string{
 	"$end",
@@ -88,6 +99,7 @@ var yyToknames = [...]string{
 	"AND",
 	"ASC",
 	"AUTOINCREMENT",
+	"BY",
 	"CASCADE",
 	"COLLATE",
 	"CONSTRAINT",
@@ -103,12 +115,15 @@ var yyToknames = [...]string{
 	"IS",
 	"KEY",
 	"LIKE",
+	"LIMIT",
 	"MATCH",
 	"NO",
 	"NOT",
 	"NULL",
+	"OFFSET",
 	"ON",
 	"OR",
+	"ORDER",
 	"PRIMARY",
 	"REFERENCES",
 	"REGEXP",
@@ -126,13 +141,15 @@ var yyToknames = [...]string{
 	"tIdentifier",
 	"tOperator",
 	"tSignedNumber",
+	"tParam",
 	"tFloat",
-	"'-'",
 	"'+'",
+	"'-'",
 	"','",
 	"'('",
 	"')'",
 }
+
 var yyStatenames = [...]string{}
 
 const yyEofCode = 1
@@ -140,149 +157,203 @@ const yyErrCode = 2
 const yyInitialStackSize = 16
 
 //line yacctab:1
-var yyExca = [...]int{
+var yyExca = [...]int16{
 	-1, 1,
 	1, -1,
 	-2, 0,
-	-1, 77,
-	50, 6,
-	-2, 77,
-	-1, 78,
-	50, 7,
+	-1, 39,
+	55, 6,
 	-2, 78,
+	-1, 40,
+	55, 7,
+	-2, 79,
+	-1, 94,
+	18, 0,
+	19, 0,
+	21, 0,
+	23, 0,
+	-2, 84,
+	-1, 99,
+	18, 0,
+	19, 0,
+	21, 0,
+	23, 0,
+	-2, 87,
+	-1, 100,
+	18, 0,
+	19, 0,
+	21, 0,
+	23, 0,
+	-2, 89,
+	-1, 137,
+	18, 0,
+	19, 0,
+	21, 0,
+	23, 0,
+	-2, 88,
+	-1, 138,
+	18, 0,
+	19, 0,
+	21, 0,
+	23, 0,
+	-2, 90,
 }
 
 const yyPrivate = 57344
 
-const yyLast = 182
-
-var yyAct = [...]int{
-
-	73, 147, 9, 125, 88, 71, 10, 68, 74, 48,
-	127, 61, 75, 132, 18, 144, 69, 10, 21, 132,
-	23, 133, 72, 26, 95, 17, 131, 31, 32, 26,
-	130, 95, 129, 124, 139, 108, 52, 77, 76, 78,
-	106, 62, 82, 80, 81, 59, 79, 102, 95, 99,
-	96, 84, 101, 100, 60, 67, 122, 94, 16, 93,
-	65, 66, 47, 86, 62, 46, 63, 64, 22, 36,
-	28, 37, 91, 92, 62, 82, 80, 81, 99, 65,
-	66, 101, 100, 55, 62, 103, 63, 64, 35, 91,
-	92, 114, 107, 104, 105, 11, 87, 12, 145, 39,
-	149, 136, 115, 111, 51, 117, 118, 119, 121, 10,
-	116, 126, 112, 33, 123, 25, 151, 143, 49, 11,
-	128, 12, 27, 146, 150, 154, 50, 148, 6, 44,
-	13, 15, 45, 10, 58, 135, 134, 137, 56, 153,
-	10, 19, 126, 141, 34, 43, 42, 85, 152, 40,
-	53, 5, 83, 57, 8, 89, 35, 41, 110, 98,
-	155, 120, 70, 90, 113, 140, 142, 29, 38, 109,
-	97, 20, 54, 14, 30, 24, 7, 138, 4, 3,
-	2, 1,
+const yyLast = 270
+
+var yyAct = [...]uint8{
+	35, 189, 9, 171, 120, 126, 11, 36, 129, 25,
+	132, 90, 107, 104, 73, 19, 127, 79, 11, 22,
+	173, 24, 42, 34, 29, 175, 175, 186, 176, 181,
+	51, 52, 149, 131, 174, 33, 149, 140, 170, 168,
+	39, 38, 40, 160, 44, 41, 47, 46, 45, 29,
+	43, 67, 68, 69, 71, 140, 11, 155, 91, 149,
+	148, 150, 147, 88, 158, 92, 93, 94, 56, 142,
+	99, 100, 101, 102, 103, 105, 111, 140, 76, 139,
+	77, 62, 59, 63, 58, 118, 61, 64, 65, 136,
+	60, 112, 95, 119, 125, 44, 115, 47, 46, 45,
+	87, 18, 86, 66, 105, 23, 137, 138, 48, 135,
+	44, 63, 37, 122, 121, 64, 65, 62, 59, 114,
+	58, 12, 61, 13, 10, 144, 60, 146, 26, 69,
+	71, 75, 11, 12, 91, 13, 178, 28, 17, 153,
+	123, 124, 187, 154, 145, 105, 6, 63, 159, 157,
+	156, 64, 65, 14, 16, 56, 163, 167, 70, 72,
+	165, 11, 166, 172, 169, 12, 164, 13, 62, 59,
+	188, 58, 5, 61, 32, 56, 11, 60, 177, 179,
+	196, 57, 11, 185, 172, 183, 74, 30, 62, 59,
+	194, 58, 110, 61, 56, 195, 117, 60, 63, 130,
+	143, 57, 64, 65, 54, 141, 106, 62, 59, 108,
+	58, 75, 61, 116, 98, 96, 60, 109, 63, 97,
+	57, 191, 64, 65, 123, 124, 84, 20, 44, 85,
+	133, 122, 121, 152, 8, 55, 162, 63, 193, 134,
+	197, 64, 65, 83, 82, 53, 31, 89, 192, 80,
+	128, 190, 21, 182, 184, 49, 78, 81, 161, 151,
+	113, 15, 50, 27, 7, 180, 4, 3, 2, 1,
 }
-var yyPact = [...]int{
-
-	117, -1000, -1000, -1000, -1000, 54, 94, 9, -1000, -1000,
-	-1000, -1000, -1000, 54, 122, -1000, 54, 54, 18, 54,
-	-1000, -1000, 54, 95, 21, -1000, 54, 54, 78, 20,
-	120, 15, 12, 89, -1000, 54, 146, 43, 120, -1000,
-	132, -1000, -1000, 108, 54, 19, 39, -4, -1000, 131,
-	1, 126, -1000, 89, -1000, 63, -1000, 149, -1000, -1000,
-	-1000, -1000, -1000, 39, 39, -1000, -1000, 8, -1, -1000,
-	150, 34, -1000, -3, -1000, -1000, -1000, -1000, -1000, -4,
-	29, 29, -1000, -10, -4, -15, -1000, -1000, 151, -1000,
-	-1000, -1000, -1000, -1000, 39, -4, 52, 149, 38, -4,
-	-4, -4, -4, 5, -1000, -1000, -4, -18, 54, -1000,
-	-1000, -41, -1000, -1000, -4, -1000, -1000, 34, 34, 34,
-	-19, 34, -1000, -25, -1000, -30, -1000, -1000, 34, -1000,
-	-4, -1000, 54, 71, 34, -1000, 54, -16, -1000, 54,
-	90, -36, -1000, 85, -1000, 92, 92, -1000, 113, -1000,
-	-1000, 156, -1000, -1000, -1000, -1000,
+
+var yyPact = [...]int16{
+	134, -32768, -32768, -32768, -32768, 76, 113, 84, -32768, -32768,
+	-32768, -32768, -32768, -32768, 88, 207, -32768, 76, 88, 50,
+	88, -32768, 85, 88, 157, 142, -5, 54, -32768, 88,
+	88, 180, 227, 189, -32768, 48, -32768, -32768, -32768, -32768,
+	-32768, -32768, -5, -5, -32768, 46, 46, -32768, 120, 24,
+	216, 47, 45, -32768, -5, 88, -5, -5, -5, 37,
+	196, -5, -5, -5, -5, -5, -5, 99, 150, -32768,
+	-32768, -32768, -32768, 176, -32768, 88, 200, 75, 216, -32768,
+	191, -32768, -32768, 168, 88, 179, 61, -5, 170, -21,
+	-32768, 224, 99, 63, 35, -5, 34, -5, -5, 35,
+	35, 35, 35, 35, 23, 189, -32768, -32768, 183, 14,
+	178, -32768, 176, -32768, 107, -32768, 224, -32768, -32768, -32768,
+	-32768, 61, 61, -32768, -32768, 6, 5, -32768, 223, 189,
+	-5, 88, -32768, -32768, -32768, 1, -5, 35, 35, -32768,
+	-5, 9, -5, -12, -32768, -32768, 229, -32768, 61, -5,
+	85, 224, 95, 189, -32768, -32768, -17, 189, -5, -18,
+	88, -32768, -32768, -36, -32768, -32768, -32768, -32768, -32768, -22,
+	-32768, -28, -32768, -32768, -32768, 88, 102, -32768, 88, -26,
+	-32768, 88, 153, -29, -32768, 128, -32768, 212, 212, -32768,
+	167, -32768, -32768, 236, -32768, -32768, -32768, -32768,
 }
-var yyPgo = [...]int{
 
-	0, 181, 180, 179, 178, 0, 11, 8, 12, 2,
-	154, 3, 177, 176, 175, 115, 7, 16, 174, 113,
-	173, 172, 170, 4, 169, 99, 168, 9, 167, 1,
-	166, 165, 164, 162, 5, 161,
+var yyPgo = [...]int16{
+	0, 269, 268, 267, 266, 0, 4, 7, 112, 2,
+	234, 3, 265, 264, 263, 137, 5, 16, 262, 14,
+	261, 260, 259, 10, 258, 17, 256, 12, 255, 1,
+	254, 253, 9, 250, 8, 13, 11, 247, 246, 245,
 }
-var yyR1 = [...]int{
 
+var yyR1 = [...]int8{
 	0, 1, 1, 1, 6, 6, 5, 5, 7, 7,
-	7, 8, 8, 8, 9, 11, 11, 12, 10, 13,
-	13, 25, 25, 25, 25, 25, 25, 25, 26, 26,
-	26, 27, 27, 27, 19, 19, 28, 28, 28, 24,
-	24, 14, 14, 15, 18, 18, 18, 18, 22, 22,
-	23, 23, 23, 21, 21, 20, 20, 16, 16, 33,
-	17, 29, 29, 29, 29, 29, 30, 30, 31, 31,
-	32, 32, 34, 34, 34, 34, 34, 34, 34, 34,
-	34, 34, 34, 35, 35, 35, 2, 3, 4,
+	7, 8, 8, 8, 9, 11, 11, 12, 10, 10,
+	13, 13, 25, 25, 25, 25, 25, 25, 25, 26,
+	26, 26, 27, 27, 27, 19, 19, 28, 28, 28,
+	24, 24, 14, 14, 15, 18, 18, 18, 18, 22,
+	22, 23, 23, 23, 21, 21, 20, 20, 16, 16,
+	33, 17, 29, 29, 29, 29, 29, 30, 30, 31,
+	31, 32, 32, 34, 34, 34, 34, 34, 34, 34,
+	34, 34, 34, 34, 34, 34, 34, 34, 34, 34,
+	34, 34, 34, 34, 34, 35, 35, 35, 36, 37,
+	37, 38, 38, 39, 39, 39, 2, 3, 4,
 }
-var yyR2 = [...]int{
 
+var yyR2 = [...]int8{
 	0, 1, 1, 1, 1, 1, 1, 1, 1, 2,
 	2, 1, 2, 2, 1, 1, 3, 3, 1, 1,
-	3, 4, 1, 1, 2, 2, 2, 2, 0, 1,
-	2, 5, 4, 9, 0, 2, 0, 3, 4, 0,
-	1, 1, 3, 3, 0, 1, 4, 6, 0, 2,
-	0, 1, 1, 0, 2, 0, 1, 1, 3, 1,
-	3, 2, 2, 1, 1, 2, 3, 3, 0, 2,
-	0, 2, 1, 4, 1, 1, 1, 1, 1, 3,
-	3, 3, 3, 0, 1, 3, 4, 8, 10,
+	1, 3, 4, 1, 1, 2, 2, 2, 2, 0,
+	1, 2, 5, 4, 9, 0, 2, 0, 3, 4,
+	0, 1, 1, 3, 3, 0, 1, 4, 6, 0,
+	2, 0, 1, 1, 0, 2, 0, 1, 1, 3,
+	1, 3, 2, 2, 1, 1, 2, 3, 3, 0,
+	2, 0, 2, 1, 4, 1, 1, 1, 1, 1,
+	1, 3, 3, 2, 3, 5, 6, 3, 4, 3,
+	4, 3, 3, 3, 3, 0, 1, 3, 2, 1,
+	3, 0, 3, 0, 2, 4, 7, 8, 10,
 }
-var yyChk = [...]int{
-
-	-1000, -1, -2, -3, -4, 34, 11, -13, -10, -9,
-	-5, 41, 43, 36, -20, 37, 49, 16, -5, 19,
-	-10, -5, 50, -5, -14, -15, -5, 27, 49, -28,
-	-18, -5, -5, -19, -15, 10, 49, 51, -26, -25,
-	29, 37, 26, 25, 9, 12, 50, 50, -27, 29,
-	37, 15, -5, -19, -21, 40, -25, 21, 26, -5,
-	-7, -6, 45, 47, 48, 41, 42, -7, -16, -17,
-	-33, -34, 26, -5, -7, -8, 42, 41, 43, 50,
-	47, 48, 46, 21, 50, 21, -27, 33, -23, 6,
-	14, -7, -7, 51, 49, 49, 51, -22, 9, 44,
-	48, 47, 50, -34, -8, -8, 50, -16, 50, -24,
-	7, -7, -17, -32, 39, -23, -6, -34, -34, -34,
-	-35, -34, 51, -16, 51, -11, -9, 51, -34, 51,
-	49, 51, 49, 51, -34, -9, 30, -5, -12, 50,
-	-31, -11, -30, 27, 51, 13, 38, -29, 35, 8,
-	32, 24, -29, 26, 12, 4,
+
+var yyChk = [...]int16{
+	-32768, -1, -2, -3, -4, 38, 12, -13, -10, -9,
+	48, -5, 45, 47, 40, -20, 41, 54, 17, -5,
+	20, -10, -5, 55, -5, -32, 43, -14, -15, -5,
+	30, -38, 32, -34, 28, -5, -7, -8, 46, 45,
+	47, 50, 27, 55, 49, 53, 52, 51, 54, -28,
+	-18, -5, -5, -39, 24, 8, 5, 31, 21, 19,
+	27, 23, 18, 48, 52, 53, 55, -34, -34, -7,
+	-8, -7, -8, -19, -15, 11, 54, 56, -26, -25,
+	33, 41, 28, 27, 10, 13, 55, 55, -34, -37,
+	-36, -9, -34, -34, -34, 55, 19, 23, 18, -34,
+	-34, -34, -34, -34, -35, -34, 56, -27, 33, 41,
+	16, -5, -19, -21, 44, -25, 22, 28, -5, -7,
+	-6, 53, 52, 45, 46, -7, -16, -17, -33, -34,
+	29, 54, -23, 6, 15, -35, 55, -34, -34, 56,
+	54, 22, 55, 22, -27, 37, -23, 56, 54, 54,
+	56, -22, 10, -34, -36, 56, -35, -34, 55, -16,
+	55, -24, 7, -7, -17, -32, -23, -6, 56, -16,
+	56, -11, -9, 56, 56, 54, 56, -9, 34, -5,
+	-12, 55, -31, -11, -30, 30, 56, 14, 42, -29,
+	39, 9, 36, 26, -29, 28, 13, 4,
 }
-var yyDef = [...]int{
-
-	0, -2, 1, 2, 3, 0, 55, 0, 19, 18,
-	14, 6, 7, 0, 0, 56, 0, 0, 0, 0,
-	20, 86, 0, 0, 36, 41, 44, 0, 34, 0,
-	28, 45, 0, 0, 42, 0, 34, 53, 43, 29,
-	0, 22, 23, 0, 0, 0, 0, 0, 37, 0,
-	0, 0, 35, 0, 87, 0, 30, 50, 24, 25,
-	26, 27, 8, 0, 0, 4, 5, 0, 0, 57,
-	48, 59, 72, 0, 74, 75, 76, -2, -2, 0,
-	0, 0, 11, 0, 0, 0, 38, 54, 39, 51,
-	52, 9, 10, 46, 0, 0, 70, 50, 0, 0,
-	0, 0, 83, 0, 12, 13, 0, 0, 0, 21,
-	40, 0, 58, 88, 0, 60, 49, 79, 80, 81,
-	0, 84, 82, 0, 32, 0, 15, 47, 71, 73,
-	0, 31, 0, 0, 85, 16, 0, 0, 68, 0,
-	33, 0, 69, 0, 17, 0, 0, 66, 0, 63,
-	64, 0, 67, 61, 62, 65,
+
+var yyDef = [...]int8{
+	0, -2, 1, 2, 3, 0, 56, 0, 20, 18,
+	19, 14, 6, 7, 0, 0, 57, 0, 0, 0,
+	0, 21, 71, 0, 0, 101, 0, 37, 42, 45,
+	0, 103, 0, 72, 73, 0, 75, 76, 77, -2,
+	-2, 80, 0, 0, 8, 0, 0, 11, 35, 0,
+	29, 46, 0, 106, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 95, 83, 0, 9,
+	12, 10, 13, 0, 43, 0, 35, 54, 44, 30,
+	0, 23, 24, 0, 0, 0, 0, 0, 104, 102,
+	99, 51, 81, 82, -2, 95, 0, 0, 0, -2,
+	-2, 91, 92, 93, 0, 96, 94, 38, 0, 0,
+	0, 36, 0, 107, 0, 31, 51, 25, 26, 27,
+	28, 0, 0, 4, 5, 0, 0, 58, 49, 60,
+	0, 0, 98, 52, 53, 0, 95, -2, -2, 74,
+	0, 0, 0, 0, 39, 55, 40, 47, 0, 0,
+	71, 51, 0, 105, 100, 85, 0, 97, 0, 0,
+	0, 22, 41, 0, 59, 108, 61, 50, 86, 0,
+	33, 0, 15, 48, 32, 0, 0, 16, 0, 0,
+	69, 0, 34, 0, 70, 0, 17, 0, 0, 67,
+	0, 64, 65, 0, 68, 62, 63, 66,
 }
-var yyTok1 = [...]int{
 
+var yyTok1 = [...]int8{
 	1, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
-	50, 51, 3, 48, 49, 47,
+	55, 56, 3, 52, 54, 53,
 }
-var yyTok2 = [...]int{
 
+var yyTok2 = [...]int8{
 	2, 3, 4, 5, 6, 7, 8, 9, 10, 11,
 	12, 13, 14, 15, 16, 17, 18, 19, 20, 21,
 	22, 23, 24, 25, 26, 27, 28, 29, 30, 31,
 	32, 33, 34, 35, 36, 37, 38, 39, 40, 41,
-	42, 43, 44, 45, 46,
+	42, 43, 44, 45, 46, 47, 48, 49, 50, 51,
 }
-var yyTok3 = [...]int{
+
+var yyTok3 = [...]int8{
 	0,
 }
 
@@ -325,7 +396,7 @@ func yyNewParser() yyParser {
 	return &yyParserImpl{}
 }
 
-const yyFlag = -1000
+const yyFlag = -32768
 
 func yyTokname(c int) string {
 	if c >= 1 && c-1 < len(yyToknames) {
@@ -364,9 +435,9 @@ func yyErrorMessage(state, lookAhead int) string {
 	expected := make([]int, 0, 4)
 
 	// Look for shiftable tokens.
-	base := yyPact[state]
+	base := int(yyPact[state])
 	for tok := TOKSTART; tok-1 < len(yyToknames); tok++ {
-		if n := base + tok; n >= 0 && n < yyLast && yyChk[yyAct[n]] == tok {
+		if n := base + tok; n >= 0 && n < yyLast && int(yyChk[int(yyAct[n])]) == tok {
 			if len(expected) == cap(expected) {
 				return res
 			}
@@ -376,13 +447,13 @@ func yyErrorMessage(state, lookAhead int) string {
 
 	if yyDef[state] == -2 {
 		i := 0
-		for yyExca[i] != -1 || yyExca[i+1] != state {
+		for yyExca[i] != -1 || int(yyExca[i+1]) != state {
 			i += 2
 		}
 
 		// Look for tokens that we accept or reduce.
 		for i += 2; yyExca[i] >= 0; i += 2 {
-			tok := yyExca[i]
+			tok := int(yyExca[i])
 			if tok < TOKSTART || yyExca[i+1] == 0 {
 				continue
 			}
@@ -413,30 +484,30 @@ func yylex1(lex yyLexer, lval *yySymType) (char, token int) {
 	token = 0
 	char = lex.Lex(lval)
 	if char <= 0 {
-		token = yyTok1[0]
+		token = int(yyTok1[0])
 		goto out
 	}
 	if char < len(yyTok1) {
-		token = yyTok1[char]
+		token = int(yyTok1[char])
 		goto out
 	}
 	if char >= yyPrivate {
 		if char < yyPrivate+len(yyTok2) {
-			token = yyTok2[char-yyPrivate]
+			token = int(yyTok2[char-yyPrivate])
 			goto out
 		}
 	}
 	for i := 0; i < len(yyTok3); i += 2 {
-		token = yyTok3[i+0]
+		token = int(yyTok3[i+0])
 		if token == char {
-			token = yyTok3[i+1]
+			token = int(yyTok3[i+1])
 			goto out
 		}
 	}
 
 out:
 	if token == 0 {
-		token = yyTok2[1] /* unknown char */
+		token = int(yyTok2[1]) /* unknown char */
 	}
 	if yyDebug >= 3 {
 		__yyfmt__.Printf("lex %s(%d)\n", yyTokname(token), uint(char))
@@ -491,7 +562,7 @@ yystack:
 	yyS[yyp].yys = yystate
 
 yynewstate:
-	yyn = yyPact[yystate]
+	yyn = int(yyPact[yystate])
 	if yyn <= yyFlag {
 		goto yydefault /* simple state */
 	}
@@ -502,8 +573,8 @@ yynewstate:
 	if yyn < 0 || yyn >= yyLast {
 		goto yydefault
 	}
-	yyn = yyAct[yyn]
-	if yyChk[yyn] == yytoken { /* valid shift */
+	yyn = int(yyAct[yyn])
+	if int(yyChk[yyn]) == yytoken { /* valid shift */
 		yyrcvr.char = -1
 		yytoken = -1
 		yyVAL = yyrcvr.lval
@@ -516,7 +587,7 @@ yynewstate:
 
 yydefault:
 	/* default state action */
-	yyn = yyDef[yystate]
+	yyn = int(yyDef[yystate])
 	if yyn == -2 {
 		if yyrcvr.char < 0 {
 			yyrcvr.char, yytoken = yylex1(yylex, &yyrcvr.lval)
@@ -525,18 +596,18 @@ yydefault:
 		/* look through exception table */
 		xi := 0
 		for {
-			if yyExca[xi+0] == -1 && yyExca[xi+1] == yystate {
+			if yyExca[xi+0] == -1 && int(yyExca[xi+1]) == yystate {
 				break
 			}
 			xi += 2
 		}
 		for xi += 2; ; xi += 2 {
-			yyn = yyExca[xi+0]
+			yyn = int(yyExca[xi+0])
 			if yyn < 0 || yyn == yytoken {
 				break
 			}
 		}
-		yyn = yyExca[xi+1]
+		yyn = int(yyExca[xi+1])
 		if yyn < 0 {
 			goto ret0
 		}
@@ -558,10 +629,10 @@ yydefault:
 
 			/* find a state where "error" is a legal shift action */
 			for yyp >= 0 {
-				yyn = yyPact[yyS[yyp].yys] + yyErrCode
+				yyn = int(yyPact[yyS[yyp].yys]) + yyErrCode
 				if yyn >= 0 && yyn < yyLast {
-					yystate = yyAct[yyn] /* simulate a shift of "error" */
-					if yyChk[yystate] == yyErrCode {
+					yystate = int(yyAct[yyn]) /* simulate a shift of "error" */
+					if int(yyChk[yystate]) == yyErrCode {
 						goto yystack
 					}
 				}
@@ -597,7 +668,7 @@ yydefault:
 	yypt := yyp
 	_ = yypt // guard against "declared and not used"
 
-	yyp -= yyR2[yyn]
+	yyp -= int(yyR2[yyn])
 	// yyp is now the index of $0. Perform the default action. Iff the
 	// reduced production is ε, $1 is possibly out of range.
 	if yyp+1 >= len(yyS) {
@@ -608,16 +679,16 @@ yydefault:
 	yyVAL = yyS[yyp+1]
 
 	/* consult goto table to find next state */
-	yyn = yyR1[yyn]
-	yyg := yyPgo[yyn]
+	yyn = int(yyR1[yyn])
+	yyg := int(yyPgo[yyn])
 	yyj := yyg + yyS[yyp].yys + 1
 
 	if yyj >= yyLast {
-		yystate = yyAct[yyg]
+		yystate = int(yyAct[yyg])
 	} else {
-		yystate = yyAct[yyj]
-		if yyChk[yystate] != -yyn {
-			yystate = yyAct[yyg]
+		yystate = int(yyAct[yyj])
+		if int(yyChk[yystate]) != -yyn {
+			yystate = int(yyAct[yyg])
 		}
 	}
 	// dummy call; replaced with literal code
@@ -625,181 +696,190 @@ yydefault:
 
 	case 4:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line parser.go.y:117
+//line parser.go.y:133
 		{
 			yyVAL.literal = yyDollar[1].identifier
 		}
 	case 5:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line parser.go.y:120
+//line parser.go.y:136
 		{
 			yyVAL.literal = yyDollar[1].identifier
 		}
 	case 6:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line parser.go.y:125
+//line parser.go.y:141
 		{
 			yyVAL.identifier = yyDollar[1].identifier
 		}
 	case 7:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line parser.go.y:128
+//line parser.go.y:144
 		{
 			yyVAL.identifier = yyDollar[1].identifier
 		}
 	case 8:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line parser.go.y:133
+//line parser.go.y:149
 		{
 			yyVAL.signedNumber = yyDollar[1].signedNumber
 		}
 	case 9:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line parser.go.y:136
+//line parser.go.y:152
 		{
 			yyVAL.signedNumber = -yyDollar[2].signedNumber
 		}
 	case 10:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line parser.go.y:139
+//line parser.go.y:155
 		{
 			yyVAL.signedNumber = yyDollar[2].signedNumber
 		}
 	case 11:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line parser.go.y:144
+//line parser.go.y:160
 		{
 			yyVAL.float = yyDollar[1].float
 		}
 	case 12:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line parser.go.y:147
+//line parser.go.y:163
 		{
 			yyVAL.float = -yyDollar[2].float
 		}
 	case 13:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line parser.go.y:150
+//line parser.go.y:166
 		{
 			yyVAL.float = yyDollar[2].float
 		}
 	case 14:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line parser.go.y:155
+//line parser.go.y:171
 		{
 			yyVAL.columnName = yyDollar[1].identifier
 		}
 	case 15:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line parser.go.y:160
+//line parser.go.y:176
 		{
 			yyVAL.columnNameList = []string{yyDollar[1].columnName}
 		}
 	case 16:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line parser.go.y:163
+//line parser.go.y:179
 		{
 			yyVAL.columnNameList = append(yyDollar[1].columnNameList, yyDollar[3].columnName)
 		}
 	case 17:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line parser.go.y:168
+//line parser.go.y:184
 		{
 			yyVAL.columnNameList = yyDollar[2].columnNameList
 		}
 	case 18:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line parser.go.y:173
+//line parser.go.y:189
 		{
 			yyVAL.columnName = yyDollar[1].columnName
 		}
 	case 19:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line parser.go.y:178
+//line parser.go.y:192
 		{
-			yyVAL.columnNameList = []string{yyDollar[1].columnName}
+			if yyDollar[1].identifier != "*" {
+				yylex.Error("syntax error")
+			}
+			yyVAL.columnName = yyDollar[1].identifier
 		}
 	case 20:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:200
+		{
+			yyVAL.columnNameList = []string{yyDollar[1].columnName}
+		}
+	case 21:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line parser.go.y:181
+//line parser.go.y:203
 		{
 			yyVAL.columnNameList = append(yyDollar[1].columnNameList, yyDollar[3].columnName)
 		}
-	case 21:
+	case 22:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line parser.go.y:187
+//line parser.go.y:209
 		{
 			yyVAL.columnConstraint = ccPrimaryKey{yyDollar[3].sortOrder, yyDollar[4].bool}
 		}
-	case 22:
+	case 23:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line parser.go.y:190
+//line parser.go.y:212
 		{
 			yyVAL.columnConstraint = ccUnique(true)
 		}
-	case 23:
+	case 24:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line parser.go.y:193
+//line parser.go.y:215
 		{
 			yyVAL.columnConstraint = ccNull(true)
 		}
-	case 24:
+	case 25:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line parser.go.y:196
+//line parser.go.y:218
 		{
 			yyVAL.columnConstraint = ccNull(false)
 		}
-	case 25:
+	case 26:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line parser.go.y:199
+//line parser.go.y:221
 		{
 			yyVAL.columnConstraint = ccCollate(yyDollar[2].identifier)
 		}
-	case 26:
+	case 27:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line parser.go.y:202
+//line parser.go.y:224
 		{
 			yyVAL.columnConstraint = ccDefault(yyDollar[2].signedNumber)
 		}
-	case 27:
+	case 28:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line parser.go.y:205
+//line parser.go.y:227
 		{
 			yyVAL.columnConstraint = ccDefault(yyDollar[2].literal)
 		}
-	case 28:
+	case 29:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line parser.go.y:210
+//line parser.go.y:232
 		{
 			yyVAL.columnConstraintList = nil
 		}
-	case 29:
+	case 30:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line parser.go.y:213
+//line parser.go.y:235
 		{
 			yyVAL.columnConstraintList = []columnConstraint{yyDollar[1].columnConstraint}
 		}
-	case 30:
+	case 31:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line parser.go.y:216
+//line parser.go.y:238
 		{
 			yyVAL.columnConstraintList = append(yyDollar[1].columnConstraintList, yyDollar[2].columnConstraint)
 		}
-	case 31:
+	case 32:
 		yyDollar = yyS[yypt-5 : yypt+1]
-		//line parser.go.y:221
+//line parser.go.y:243
 		{
 			yyVAL.tableConstraint = TablePrimaryKey{yyDollar[4].indexedColumnList}
 		}
-	case 32:
+	case 33:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line parser.go.y:224
+//line parser.go.y:246
 		{
 			yyVAL.tableConstraint = TableUnique{yyDollar[3].indexedColumnList}
 		}
-	case 33:
+	case 34:
 		yyDollar = yyS[yypt-9 : yypt+1]
-		//line parser.go.y:227
+//line parser.go.y:249
 		{
 			yyVAL.tableConstraint = TableForeignKey{
 				Columns:        yyDollar[4].columnNameList,
@@ -808,320 +888,445 @@ yydefault:
 				Triggers:       yyDollar[9].triggerList,
 			}
 		}
-	case 34:
+	case 35:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line parser.go.y:237
+//line parser.go.y:259
 		{
 		}
-	case 35:
+	case 36:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line parser.go.y:238
+//line parser.go.y:260
 		{
 		}
-	case 36:
+	case 37:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line parser.go.y:242
+//line parser.go.y:264
 		{
 		}
-	case 37:
+	case 38:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line parser.go.y:243
+//line parser.go.y:265
 		{
 			yyVAL.tableConstraintList = []TableConstraint{yyDollar[3].tableConstraint}
 		}
-	case 38:
+	case 39:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line parser.go.y:246
+//line parser.go.y:268
 		{
 			yyVAL.tableConstraintList = append(yyDollar[1].tableConstraintList, yyDollar[4].tableConstraint)
 		}
-	case 39:
+	case 40:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line parser.go.y:252
+//line parser.go.y:274
 		{
 		}
-	case 40:
+	case 41:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line parser.go.y:253
+//line parser.go.y:275
 		{
 			yyVAL.bool = true
 		}
-	case 41:
+	case 42:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line parser.go.y:258
+//line parser.go.y:280
 		{
 			yyVAL.columnDefList = []ColumnDef{yyDollar[1].columnDef}
 		}
-	case 42:
+	case 43:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line parser.go.y:261
+//line parser.go.y:283
 		{
 			yyVAL.columnDefList = append(yyDollar[1].columnDefList, yyDollar[3].columnDef)
 		}
-	case 43:
+	case 44:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line parser.go.y:266
+//line parser.go.y:288
 		{
 			yyVAL.columnDef = makeColumnDef(yyDollar[1].identifier, yyDollar[2].name, yyDollar[3].columnConstraintList)
 		}
-	case 44:
+	case 45:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line parser.go.y:271
+//line parser.go.y:293
 		{
 			yyVAL.name = ""
 		}
-	case 45:
+	case 46:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line parser.go.y:274
+//line parser.go.y:296
 		{
 			yyVAL.name = yyDollar[1].identifier
 		}
-	case 46:
+	case 47:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line parser.go.y:277
+//line parser.go.y:299
 		{
 			yyVAL.name = yyDollar[1].identifier
 		}
-	case 47:
+	case 48:
 		yyDollar = yyS[yypt-6 : yypt+1]
-		//line parser.go.y:280
+//line parser.go.y:302
 		{
 			yyVAL.name = yyDollar[1].identifier
 		}
-	case 48:
+	case 49:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line parser.go.y:285
+//line parser.go.y:307
 		{
 		}
-	case 49:
+	case 50:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line parser.go.y:286
+//line parser.go.y:308
 		{
 			yyVAL.collate = yyDollar[2].literal
 		}
-	case 50:
+	case 51:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line parser.go.y:291
+//line parser.go.y:313
 		{
 			yyVAL.sortOrder = Asc
 		}
-	case 51:
+	case 52:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line parser.go.y:294
+//line parser.go.y:316
 		{
 			yyVAL.sortOrder = Asc
 		}
-	case 52:
+	case 53:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line parser.go.y:297
+//line parser.go.y:319
 		{
 			yyVAL.sortOrder = Desc
 		}
-	case 53:
+	case 54:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line parser.go.y:302
+//line parser.go.y:324
 		{
 			yyVAL.withoutRowid = false
 		}
-	case 54:
+	case 55:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line parser.go.y:305
+//line parser.go.y:327
 		{
 			yyVAL.withoutRowid = true
 		}
-	case 55:
+	case 56:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line parser.go.y:310
+//line parser.go.y:332
 		{
 			yyVAL.unique = false
 		}
-	case 56:
+	case 57:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line parser.go.y:313
+//line parser.go.y:335
 		{
 			yyVAL.unique = true
 		}
-	case 57:
+	case 58:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line parser.go.y:318
+//line parser.go.y:340
 		{
 			yyVAL.indexedColumnList = []IndexedColumn{yyDollar[1].indexedColumn}
 		}
-	case 58:
+	case 59:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line parser.go.y:321
+//line parser.go.y:343
 		{
 			yyVAL.indexedColumnList = append(yyDollar[1].indexedColumnList, yyDollar[3].indexedColumn)
 		}
-	case 59:
+	case 60:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line parser.go.y:326
+//line parser.go.y:348
 		{
 			yyVAL.expr = yyDollar[1].expr
 		}
-	case 60:
+	case 61:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line parser.go.y:331
+//line parser.go.y:353
 		{
 			yyVAL.indexedColumn = newIndexColumn(yyDollar[1].expr, yyDollar[2].collate, yyDollar[3].sortOrder)
 		}
-	case 61:
+	case 62:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line parser.go.y:336
+//line parser.go.y:358
 		{
 			yyVAL.triggerAction = ActionSetNull
 		}
-	case 62:
+	case 63:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line parser.go.y:339
+//line parser.go.y:361
 		{
 			yyVAL.triggerAction = ActionSetDefault
 		}
-	case 63:
+	case 64:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line parser.go.y:342
+//line parser.go.y:364
 		{
 			yyVAL.triggerAction = ActionCascade
 		}
-	case 64:
+	case 65:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line parser.go.y:345
+//line parser.go.y:367
 		{
 			yyVAL.triggerAction = ActionRestrict
 		}
-	case 65:
+	case 66:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line parser.go.y:348
+//line parser.go.y:370
 		{
 			yyVAL.triggerAction = ActionNoAction
 		}
-	case 66:
+	case 67:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line parser.go.y:353
+//line parser.go.y:375
 		{
 			yyVAL.trigger = TriggerOnDelete(yyDollar[3].triggerAction)
 		}
-	case 67:
+	case 68:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line parser.go.y:356
+//line parser.go.y:378
 		{
 			yyVAL.trigger = TriggerOnUpdate(yyDollar[3].triggerAction)
 		}
-	case 68:
+	case 69:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line parser.go.y:361
+//line parser.go.y:383
 		{
 		}
-	case 69:
+	case 70:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line parser.go.y:362
+//line parser.go.y:384
 		{
 			yyVAL.triggerList = append(yyDollar[1].triggerList, yyDollar[2].trigger)
 		}
-	case 70:
+	case 71:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line parser.go.y:367
+//line parser.go.y:389
 		{
 		}
-	case 71:
+	case 72:
 		yyDollar = yyS[yypt-2 : yypt+1]
-		//line parser.go.y:368
+//line parser.go.y:390
 		{
 			yyVAL.where = yyDollar[2].expr
 		}
-	case 72:
+	case 73:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line parser.go.y:373
+//line parser.go.y:395
 		{
 			yyVAL.expr = nil
 		}
-	case 73:
+	case 74:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line parser.go.y:376
+//line parser.go.y:398
 		{
 			yyVAL.expr = ExFunction{yyDollar[1].identifier, yyDollar[3].exprList}
 		}
-	case 74:
+	case 75:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line parser.go.y:379
+//line parser.go.y:401
 		{
 			yyVAL.expr = yyDollar[1].signedNumber
 		}
-	case 75:
+	case 76:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line parser.go.y:382
+//line parser.go.y:404
 		{
 			yyVAL.expr = yyDollar[1].float
 		}
-	case 76:
+	case 77:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line parser.go.y:385
+//line parser.go.y:407
 		{
 			yyVAL.expr = yyDollar[1].identifier
 		}
-	case 77:
+	case 78:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line parser.go.y:388
+//line parser.go.y:410
 		{
 			yyVAL.expr = ExColumn(yyDollar[1].identifier)
 		}
-	case 78:
+	case 79:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line parser.go.y:391
+//line parser.go.y:413
 		{
 			yyVAL.expr = ExColumn(yyDollar[1].identifier)
 		}
-	case 79:
+	case 80:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:416
+		{
+			yyVAL.expr = ExParam(yyDollar[1].signedNumber)
+		}
+	case 81:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:419
+		{
+			yyVAL.expr = ExBinaryOp{"AND", yyDollar[1].expr, yyDollar[3].expr}
+		}
+	case 82:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:422
+		{
+			yyVAL.expr = ExBinaryOp{"OR", yyDollar[1].expr, yyDollar[3].expr}
+		}
+	case 83:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:425
+		{
+			yyVAL.expr = ExUnaryOp{"NOT", yyDollar[2].expr}
+		}
+	case 84:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line parser.go.y:394
+//line parser.go.y:428
+		{
+			// `a IS NOT b` is parsed as `a IS (NOT b)`
+			if n, ok := yyDollar[3].expr.(ExUnaryOp); ok && n.Op == "NOT" {
+				yyVAL.expr = ExBinaryOp{"IS NOT", yyDollar[1].expr, n.Expr}
+			} else {
+				yyVAL.expr = ExBinaryOp{"IS", yyDollar[1].expr, yyDollar[3].expr}
+			}
+		}
+	case 85:
+		yyDollar = yyS[yypt-5 : yypt+1]
+//line parser.go.y:436
+		{
+			yyVAL.expr = ExIn{yyDollar[1].expr, yyDollar[4].exprList}
+		}
+	case 86:
+		yyDollar = yyS[yypt-6 : yypt+1]
+//line parser.go.y:439
+		{
+			yyVAL.expr = ExUnaryOp{"NOT", ExIn{yyDollar[1].expr, yyDollar[5].exprList}}
+		}
+	case 87:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:442
+		{
+			yyVAL.expr = ExBinaryOp{"LIKE", yyDollar[1].expr, yyDollar[3].expr}
+		}
+	case 88:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line parser.go.y:445
+		{
+			yyVAL.expr = ExUnaryOp{"NOT", ExBinaryOp{"LIKE", yyDollar[1].expr, yyDollar[4].expr}}
+		}
+	case 89:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:448
+		{
+			yyVAL.expr = ExBinaryOp{"GLOB", yyDollar[1].expr, yyDollar[3].expr}
+		}
+	case 90:
+		yyDollar = yyS[yypt-4 : yypt+1]
+//line parser.go.y:451
+		{
+			yyVAL.expr = ExUnaryOp{"NOT", ExBinaryOp{"GLOB", yyDollar[1].expr, yyDollar[4].expr}}
+		}
+	case 91:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:454
 		{
 			yyVAL.expr = ExBinaryOp{yyDollar[2].identifier, yyDollar[1].expr, yyDollar[3].expr}
 		}
-	case 80:
+	case 92:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line parser.go.y:397
+//line parser.go.y:457
 		{
 			yyVAL.expr = ExBinaryOp{"+", yyDollar[1].expr, yyDollar[3].expr}
 		}
-	case 81:
+	case 93:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line parser.go.y:400
+//line parser.go.y:460
 		{
 			yyVAL.expr = ExBinaryOp{"-", yyDollar[1].expr, yyDollar[3].expr}
 		}
-	case 82:
+	case 94:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line parser.go.y:403
+//line parser.go.y:463
 		{
 			yyVAL.expr = yyDollar[2].expr
 		}
-	case 83:
+	case 95:
 		yyDollar = yyS[yypt-0 : yypt+1]
-		//line parser.go.y:408
+//line parser.go.y:468
 		{
 			yyVAL.exprList = nil
 		}
-	case 84:
+	case 96:
 		yyDollar = yyS[yypt-1 : yypt+1]
-		//line parser.go.y:411
+//line parser.go.y:471
 		{
 			yyVAL.exprList = []Expression{yyDollar[1].expr}
 		}
-	case 85:
+	case 97:
 		yyDollar = yyS[yypt-3 : yypt+1]
-		//line parser.go.y:414
+//line parser.go.y:474
 		{
 			yyVAL.exprList = append(yyDollar[1].exprList, yyDollar[3].expr)
 		}
-	case 86:
+	case 98:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:479
+		{
+			yyVAL.orderingTerm = OrderingTerm{Column: yyDollar[1].columnName, SortOrder: yyDollar[2].sortOrder}
+		}
+	case 99:
+		yyDollar = yyS[yypt-1 : yypt+1]
+//line parser.go.y:484
+		{
+			yyVAL.orderingTermList = []OrderingTerm{yyDollar[1].orderingTerm}
+		}
+	case 100:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:487
+		{
+			yyVAL.orderingTermList = append(yyDollar[1].orderingTermList, yyDollar[3].orderingTerm)
+		}
+	case 101:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line parser.go.y:492
+		{
+			yyVAL.orderingTermList = nil
+		}
+	case 102:
+		yyDollar = yyS[yypt-3 : yypt+1]
+//line parser.go.y:495
+		{
+			yyVAL.orderingTermList = yyDollar[3].orderingTermList
+		}
+	case 103:
+		yyDollar = yyS[yypt-0 : yypt+1]
+//line parser.go.y:500
+		{
+		}
+	case 104:
+		yyDollar = yyS[yypt-2 : yypt+1]
+//line parser.go.y:501
+		{
+			yyVAL.limit = limit{yyDollar[2].expr, nil}
+		}
+	case 105:
 		yyDollar = yyS[yypt-4 : yypt+1]
-		//line parser.go.y:419
+//line parser.go.y:504
 		{
-			yylex.(*lexer).result = SelectStmt{Columns: yyDollar[2].columnNameList, Table: yyDollar[4].identifier}
+			yyVAL.limit = limit{yyDollar[2].expr, yyDollar[4].expr}
 		}
-	case 87:
+	case 106:
+		yyDollar = yyS[yypt-7 : yypt+1]
+//line parser.go.y:509
+		{
+			yylex.(*lexer).result = SelectStmt{
+				Columns: yyDollar[2].columnNameList,
+				Table:   yyDollar[4].identifier,
+				Where:   yyDollar[5].where,
+				OrderBy: yyDollar[6].orderingTermList,
+				Limit:   yyDollar[7].limit.limit,
+				Offset:  yyDollar[7].limit.offset,
+			}
+		}
+	case 107:
 		yyDollar = yyS[yypt-8 : yypt+1]
-		//line parser.go.y:424
+//line parser.go.y:521
 		{
 			yylex.(*lexer).result = CreateTableStmt{
 				Table:        yyDollar[3].identifier,
@@ -1130,9 +1335,9 @@ yydefault:
 				WithoutRowid: yyDollar[8].withoutRowid,
 			}
 		}
-	case 88:
+	case 108:
 		yyDollar = yyS[yypt-10 : yypt+1]
-		//line parser.go.y:434
+//line parser.go.y:531
 		{
 			yylex.(*lexer).result = CreateIndexStmt{
 				Index:          yyDollar[4].identifier,
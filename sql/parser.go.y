@@ -30,6 +30,9 @@ package sql
 	expr Expression
 	exprList []Expression
 	float float64
+	orderingTerm OrderingTerm
+	orderingTermList []OrderingTerm
+	limit limit
 }
 
 %type<statement> program
@@ -63,11 +66,15 @@ package sql
 %type<expr> indexedColumnExpr
 %type<expr> expr
 %type<exprList> exprList
+%type<orderingTerm> orderingTerm
+%type<orderingTermList> orderingTermList orderBy
+%type<limit> limit
 
 %token ACTION
 %token AND
 %token ASC
 %token AUTOINCREMENT
+%token BY
 %token CASCADE
 %token COLLATE
 %token CONSTRAINT
@@ -83,12 +90,15 @@ package sql
 %token IS
 %token KEY
 %token LIKE
+%token LIMIT
 %token MATCH
 %token NO
 %token NOT
 %token NULL
+%token OFFSET
 %token ON
 %token OR
+%token ORDER
 %token PRIMARY
 %token REFERENCES
 %token REGEXP
@@ -103,9 +113,15 @@ package sql
 %token WITHOUT
 %token<identifier> tBare tLiteral tIdentifier
 %token<identifier> tOperator
-%token<signedNumber> tSignedNumber
+%token<signedNumber> tSignedNumber tParam
 %token<float> tFloat
 
+%left OR
+%left AND
+%right NOT
+%nonassoc IS IN LIKE GLOB
+%right tOperator '+' '-'
+
 %%
 
 program:
@@ -172,6 +188,12 @@ optColumnNameList:
 resultColumn:
 	columnName {
 		$$ = $1
+	} |
+	tOperator {
+		if $1 != "*" {
+			yylex.Error("syntax error")
+		}
+		$$ = $1
 	}
 
 resultColumnList:
@@ -391,6 +413,44 @@ expr:
 	tIdentifier {
 		$$ = ExColumn($1)
 	} |
+	tParam {
+		$$ = ExParam($1)
+	} |
+	expr AND expr {
+		$$ = ExBinaryOp{"AND", $1, $3}
+	} |
+	expr OR expr {
+		$$ = ExBinaryOp{"OR", $1, $3}
+	} |
+	NOT expr {
+		$$ = ExUnaryOp{"NOT", $2}
+	} |
+	expr IS expr {
+		// `a IS NOT b` is parsed as `a IS (NOT b)`
+		if n, ok := $3.(ExUnaryOp); ok && n.Op == "NOT" {
+			$$ = ExBinaryOp{"IS NOT", $1, n.Expr}
+		} else {
+			$$ = ExBinaryOp{"IS", $1, $3}
+		}
+	} |
+	expr IN '(' exprList ')' {
+		$$ = ExIn{$1, $4}
+	} |
+	expr NOT IN '(' exprList ')' %prec IN {
+		$$ = ExUnaryOp{"NOT", ExIn{$1, $5}}
+	} |
+	expr LIKE expr {
+		$$ = ExBinaryOp{"LIKE", $1, $3}
+	} |
+	expr NOT LIKE expr %prec LIKE {
+		$$ = ExUnaryOp{"NOT", ExBinaryOp{"LIKE", $1, $4}}
+	} |
+	expr GLOB expr {
+		$$ = ExBinaryOp{"GLOB", $1, $3}
+	} |
+	expr NOT GLOB expr %prec GLOB {
+		$$ = ExUnaryOp{"NOT", ExBinaryOp{"GLOB", $1, $4}}
+	} |
 	expr tOperator expr {
 		$$ = ExBinaryOp{$2, $1, $3}
 	} |
@@ -415,9 +475,46 @@ exprList:
 		$$ = append($1, $3)
 	}
 
+orderingTerm:
+	columnName sortOrder {
+		$$ = OrderingTerm{Column: $1, SortOrder: $2}
+	}
+
+orderingTermList:
+	orderingTerm {
+		$$ = []OrderingTerm{$1}
+	} |
+	orderingTermList ',' orderingTerm {
+		$$ = append($1, $3)
+	}
+
+orderBy:
+	{
+		$$ = nil
+	} |
+	ORDER BY orderingTermList {
+		$$ = $3
+	}
+
+limit:
+	{ } |
+	LIMIT expr {
+		$$ = limit{$2, nil}
+	} |
+	LIMIT expr OFFSET expr {
+		$$ = limit{$2, $4}
+	}
+
 selectStmt:
-	SELECT resultColumnList FROM identifier {
-		yylex.(*lexer).result = SelectStmt{ Columns: $2, Table: $4 }
+	SELECT resultColumnList FROM identifier where orderBy limit {
+		yylex.(*lexer).result = SelectStmt{
+			Columns: $2,
+			Table: $4,
+			Where: $5,
+			OrderBy: $6,
+			Limit: $7.limit,
+			Offset: $7.offset,
+		}
 	}
 
 createTableStmt:
@@ -23,10 +23,25 @@ func (so SortOrder) String() string {
 	}
 }
 
-// A `SELECT` statement
+// A `SELECT` statement. Columns can be "*".
 type SelectStmt struct {
 	Columns []string
 	Table   string
+	Where   Expression // nil if there is no WHERE
+	OrderBy []OrderingTerm
+	Limit   Expression // nil if there is no LIMIT
+	Offset  Expression // nil if there is no OFFSET
+}
+
+// A column in an `ORDER BY`
+type OrderingTerm struct {
+	Column    string
+	SortOrder SortOrder
+}
+
+// LIMIT and OFFSET, while parsing
+type limit struct {
+	limit, offset Expression
 }
 
 // A `CREATE TABLE` statement
@@ -162,8 +177,24 @@ type ExBinaryOp struct {
 	Left, Right Expression
 }
 
+// A unary operator. The only one is "NOT".
+type ExUnaryOp struct {
+	Op   string
+	Expr Expression
+}
+
+// `Left IN (Values...)`
+type ExIn struct {
+	Left   Expression
+	Values []Expression
+}
+
 type ExColumn string
 
+// A `?` parameter. They are numbered from 1, in the order they appear, unless
+// they have an explicit number (`?3`).
+type ExParam int64
+
 type ExFunction struct {
 	F    string
 	Args []Expression
@@ -171,6 +202,8 @@ type ExFunction struct {
 
 func AsString(e Expression) string {
 	switch v := e.(type) {
+	case nil:
+		return "NULL"
 	case int64:
 		return fmt.Sprintf("%d", v)
 	case float64:
@@ -179,6 +212,8 @@ func AsString(e Expression) string {
 		return fmt.Sprintf("'%s'", v)
 	case ExColumn:
 		return fmt.Sprintf(`"%s"`, string(v))
+	case ExParam:
+		return fmt.Sprintf("?%d", int64(v))
 	case ExFunction:
 		var args []string
 		for _, a := range v.Args {
@@ -186,7 +221,19 @@ func AsString(e Expression) string {
 		}
 		return fmt.Sprintf(`"%s"(%s)`, v.F, strings.Join(args, `, `))
 	case ExBinaryOp:
+		switch v.Op {
+		case "AND", "OR", "IS", "IS NOT", "LIKE", "GLOB":
+			return fmt.Sprintf(`%s %s %s`, AsString(v.Left), v.Op, AsString(v.Right))
+		}
 		return fmt.Sprintf(`%s%s%s`, AsString(v.Left), v.Op, AsString(v.Right))
+	case ExUnaryOp:
+		return fmt.Sprintf(`%s (%s)`, v.Op, AsString(v.Expr))
+	case ExIn:
+		var vs []string
+		for _, a := range v.Values {
+			vs = append(vs, AsString(a))
+		}
+		return fmt.Sprintf(`%s IN (%s)`, AsString(v.Left), strings.Join(vs, `, `))
 	default:
 		return "bug"
 	}
@@ -43,6 +43,9 @@ func TestExpr(t *testing.T) {
 	test(Expression(ExFunction{"foo", []Expression{int64(123)}}), `"foo"(123)`)
 	test(Expression(ExBinaryOp{"+", int64(1), int64(2)}), "1+2")
 	test(Expression(ExFunction{"foo", []Expression{ExBinaryOp{"*", int64(1), int64(2)}}}), `"foo"(1*2)`)
+	test(Expression(ExBinaryOp{"AND", ExBinaryOp{"=", ExColumn("a"), int64(1)}, ExBinaryOp{">", ExColumn("b"), int64(2)}}), `"a"=1 AND "b">2`)
+	test(Expression(ExBinaryOp{"OR", ExBinaryOp{"IS NOT", ExColumn("a"), nil}, ExUnaryOp{"NOT", ExIn{ExColumn("b"), []Expression{int64(1), "x"}}}}), `"a" IS NOT NULL OR NOT ("b" IN (1, 'x'))`)
+	test(Expression(ExParam(2)), "?2")
 }
 
 func sqlOK(t *testing.T, sql string, want interface{}) {
@@ -75,6 +78,49 @@ func TestSelect(t *testing.T) {
 		SelectStmt{Columns: []string{"aap", "noot", "mies"}, Table: "foo2"},
 	)
 
+	sqlOK(t,
+		"SELECT * FROM foo",
+		SelectStmt{Columns: []string{"*"}, Table: "foo"},
+	)
+
+	sqlOK(t,
+		"SELECT rowid, a FROM foo WHERE a = ? AND b >= 'x' AND rowid < ?5 ORDER BY b DESC, a LIMIT ? OFFSET 10",
+		SelectStmt{
+			Columns: []string{"rowid", "a"},
+			Table:   "foo",
+			Where: ExBinaryOp{
+				"AND",
+				ExBinaryOp{
+					"AND",
+					ExBinaryOp{"=", ExColumn("a"), ExParam(1)},
+					ExBinaryOp{">=", ExColumn("b"), "x"},
+				},
+				ExBinaryOp{"<", ExColumn("rowid"), ExParam(5)},
+			},
+			OrderBy: []OrderingTerm{
+				{Column: "b", SortOrder: Desc},
+				{Column: "a", SortOrder: Asc},
+			},
+			Limit:  ExParam(6),
+			Offset: int64(10),
+		},
+	)
+
+	// BY and OFFSET are only keywords after ORDER and LIMIT
+	sqlOK(t,
+		"SELECT by, offset FROM foo ORDER BY offset LIMIT 1",
+		SelectStmt{
+			Columns: []string{"by", "offset"},
+			Table:   "foo",
+			OrderBy: []OrderingTerm{{Column: "offset"}},
+			Limit:   int64(1),
+		},
+	)
+
+	sqlError(t, "SELECT / FROM foo", errors.New("syntax error"))
+	sqlError(t, "SELECT a FROM foo LIMIT", errors.New("syntax error"))
+	sqlError(t, "SELECT a FROM foo WHERE a = ?0", errors.New("invalid parameter number"))
+
 	// create what?
 	sqlError(t, "CREATE nothing foo", errors.New("syntax error"))
 }
@@ -378,6 +424,30 @@ func TestCreateIndex(t *testing.T) {
 		)
 	}
 
+	// boolean WHERE expressions
+	for sql, where := range map[string]interface{}{
+		"a OR b AND c":          ExBinaryOp{"OR", ExColumn("a"), ExBinaryOp{"AND", ExColumn("b"), ExColumn("c")}},
+		"NOT a = 1 OR b":        ExBinaryOp{"OR", ExUnaryOp{"NOT", ExBinaryOp{"=", ExColumn("a"), int64(1)}}, ExColumn("b")},
+		"a IS NULL":             ExBinaryOp{"IS", ExColumn("a"), nil},
+		"a IS NOT NULL AND b":   ExBinaryOp{"AND", ExBinaryOp{"IS NOT", ExColumn("a"), nil}, ExColumn("b")},
+		"a IN (1, 'x')":         ExIn{ExColumn("a"), []Expression{int64(1), "x"}},
+		"a NOT IN ()":           ExUnaryOp{"NOT", ExIn{ExColumn("a"), nil}},
+		"a LIKE 'x%'":           ExBinaryOp{"LIKE", ExColumn("a"), "x%"},
+		"a NOT GLOB 'x*' AND b": ExBinaryOp{"AND", ExUnaryOp{"NOT", ExBinaryOp{"GLOB", ExColumn("a"), "x*"}}, ExColumn("b")},
+	} {
+		sqlOK(t,
+			"CREATE INDEX foo_index ON foo (name) WHERE "+sql,
+			CreateIndexStmt{
+				Index: "foo_index",
+				Table: "foo",
+				IndexedColumns: []IndexedColumn{
+					{Column: "name"},
+				},
+				Where: where,
+			},
+		)
+	}
+
 	sqlOK(t,
 		"CREATE INDEX foo_index ON foo (length(name) + 12)",
 		CreateIndexStmt{
@@ -30,17 +30,18 @@ var (
 		"IS":            IS,
 		"KEY":           KEY,
 		"LIKE":          LIKE,
+		"LIMIT":         LIMIT,
 		"MATCH":         MATCH,
 		"NO":            NO,
 		"NOT":           NOT,
 		"NULL":          NULL,
 		"ON":            ON,
 		"OR":            OR,
+		"ORDER":         ORDER,
 		"PRIMARY":       PRIMARY,
 		"REFERENCES":    REFERENCES,
 		"REGEXP":        REGEXP,
 		"RESTRICT":      RESTRICT,
-		"ROWID":         ROWID,
 		"SELECT":        SELECT,
 		"SET":           SET,
 		"TABLE":         TABLE,
@@ -49,6 +50,15 @@ var (
 		"WHERE":         WHERE,
 		"WITHOUT":       WITHOUT,
 	}
+	// keywords which are only keywords right after another keyword, so they
+	// can still be used as names everywhere else.
+	contextKeywords = map[string]struct {
+		typ   int
+		after int
+	}{
+		"BY":    {BY, ORDER},
+		"ROWID": {ROWID, WITHOUT},
+	}
 	operators = map[string]struct{}{
 		"||": struct{}{},
 		">=": struct{}{},
@@ -97,7 +107,11 @@ func optoken(s string) token {
 }
 
 func tokenize(s string) ([]token, error) {
-	var res []token
+	var (
+		res    []token
+		limit  bool  // seen a LIMIT
+		params int64 // highest parameter number
+	)
 	for i := 0; ; {
 		if i >= len(s) {
 			return res, nil
@@ -110,9 +124,15 @@ func tokenize(s string) ([]token, error) {
 		case unicode.IsLetter(c) || c == '_':
 			bt, bl := readBareword(s[i:])
 			tnr := tBare
-			if n, ok := keywords[strings.ToUpper(bt)]; ok {
+			up := strings.ToUpper(bt)
+			if n, ok := keywords[up]; ok {
 				tnr = n
+			} else if ck, ok := contextKeywords[up]; ok && len(res) > 0 && res[len(res)-1].typ == ck.after {
+				tnr = ck.typ
+			} else if up == "OFFSET" && limit {
+				tnr = OFFSET
 			}
+			limit = limit || tnr == LIMIT
 			res = append(res, stoken(tnr, bt))
 			i += bl - 1
 		case unicode.IsDigit(c) || c == '.':
@@ -129,6 +149,20 @@ func tokenize(s string) ([]token, error) {
 				op := readOp(s[i:])
 				res = append(res, stoken(tOperator, op))
 				i += len(op) - 1
+			case '?':
+				// `?` or `?NNN`
+				n, l := readParam(s[i+1:])
+				if l == 0 {
+					params++
+					n = params
+				} else if n < 1 {
+					return res, errors.New("invalid parameter number")
+				}
+				if n > params {
+					params = n
+				}
+				res = append(res, token{typ: tParam, n: n})
+				i += l
 			case '(', ')', ',', '+', '-', '~':
 				// + and - might be binary or unary. let the lexer figure that out
 				res = append(res, stoken(int(c), string(c)))
@@ -173,6 +207,22 @@ func readBareword(s string) (string, int) {
 	return s, len(s)
 }
 
+// readParam reads the digits after a `?`.
+func readParam(s string) (int64, int) {
+	l := 0
+	for l < len(s) && s[l] >= '0' && s[l] <= '9' {
+		l++
+	}
+	if l == 0 {
+		return 0, 0
+	}
+	n, err := strconv.ParseInt(s[:l], 10, 64)
+	if err != nil {
+		return -1, l
+	}
+	return n, l
+}
+
 func readOp(s string) string {
 	if len(s) == 1 {
 		return s
@@ -1,6 +1,7 @@
 package sqlittle
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
@@ -11,24 +12,151 @@ type columnIndex struct {
 	col      *sdb.TableColumn
 	rowIndex int
 	rowid    bool
+	aff      affinity
+}
+
+// newColumnIndex gives the columnIndex for a column which is at position n in
+// the record.
+func newColumnIndex(col *sdb.TableColumn, n int) columnIndex {
+	return columnIndex{col: col, rowIndex: n, aff: affinityOf(col.Type)}
 }
 
 // Regroups a Record to a Row, filling in missing columns as needed.
 func toRow(rowid int64, cis []columnIndex, r sdb.Record) Row {
-	row := make(Row, len(cis))
+	// with a free slot for the scan options, see Row.withOptions()
+	row := make(Row, len(cis), len(cis)+1)
+	row[:len(cis)+1][len(cis)] = (*scanOptions)(nil)
 	for i, c := range cis {
-		if c.rowid {
-			row[i] = rowid
-			continue
+		row[i] = c.value(rowid, r)
+	}
+	return row
+}
+
+// value gets the value of the column from a record, with the column affinity
+// applied.
+func (c columnIndex) value(rowid int64, r sdb.Record) interface{} {
+	if c.rowid {
+		return rowid
+	}
+	if len(r) <= c.rowIndex {
+		// use 'DEFAULT' when the record is too short
+		return c.aff.apply(c.col.Default)
+	}
+	return c.aff.apply(r[c.rowIndex])
+}
+
+// emitter makes Rows from records and passes them to the callback. The
+// columnIndexes can have more columns than the Row gets: the ones after the
+// first ncols are only there for the filter.
+//
+// With an OrderBy all rows go to a sorter first, and they are only passed on
+// in flush().
+//
+// For paging it keeps the position to resume after, and the position of the
+// row where the Limit stopped the scan. Positions are the rowid, the primary
+// key, or the index record; see Token.
+type emitter struct {
+	ncols    int
+	filter   matcher // can be nil
+	cb       RowCB
+	sort     *sorter     // can be nil
+	out      RowCB       // callback for the sorted rows
+	nout     int         // number of columns the sorted rows get
+	limit    int         // stop after this many rows; 0 for no limit
+	n        int         // number of rows passed on
+	examined int         // number of rows looked at
+	after    sdb.Record  // resume after this position; nil to start at the beginning
+	last     sdb.Record  // position of the last row, if the Limit stopped the scan
+	scan     ScanOptions // of the database, to convert time.Time keys
+	abort    *error      // the callback sets this to stop the scan; can be nil
+}
+
+// newEmitter sets up the emitter for the options. It returns the emitter, and
+// all the columns which need to be loaded: the ones requested, followed by
+// the ones needed by the OrderBy and the filter.
+func newEmitter(s *sdb.Schema, opts Options, scan ScanOptions, cb RowCB, columns []string) (*emitter, []string, error) {
+	if opts.Limit < 0 {
+		return nil, nil, fmt.Errorf("invalid Limit: %d", opts.Limit)
+	}
+	if opts.Named != nil {
+		nc, err := newNamedColumns(s, columns)
+		if err != nil {
+			return nil, nil, err
 		}
-		if len(r) <= c.rowIndex {
-			// use 'DEFAULT' when the record is too short
-			row[i] = c.col.Default
-		} else {
-			row[i] = r[c.rowIndex]
+		cb = nc.wrap(cb, opts.Named)
+	}
+	e := &emitter{ncols: len(columns), cb: cb, limit: opts.Limit, scan: scan}
+	all := columns
+	if len(opts.OrderBy) > 0 {
+		if opts.After != "" || opts.Next != nil {
+			return nil, nil, errors.New("After and Next can't be used with OrderBy")
 		}
+		st, cols, err := newSorter(s, opts.OrderBy, opts.SortMemory, append([]string(nil), columns...))
+		if err != nil {
+			return nil, nil, err
+		}
+		e.sort, e.out, e.nout = st, cb, len(columns)
+		e.cb = st.add
+		e.ncols = len(cols)
+		all = cols
+	}
+	if opts.Where != nil {
+		fc := &filterCompiler{
+			schema:  s,
+			columns: append([]string(nil), all...),
+			scan:    scan,
+		}
+		m, err := opts.Where.compile(fc)
+		if err != nil {
+			return nil, nil, err
+		}
+		e.filter = m
+		all = fc.columns
+	}
+	return e, all, nil
+}
+
+// emit calls the callback if the record passes the filter. It returns true
+// when the Limit is reached, when the callback set the abort error, or when
+// the sorter failed to spill, and the scan should stop. The spill error is
+// returned by flush().
+func (e *emitter) emit(rowid int64, ci []columnIndex, r sdb.Record) bool {
+	e.examined++
+	if e.filter != nil && e.filter(rowid, ci, r) != isTrue {
+		return false
+	}
+	e.cb(toRow(rowid, ci[:e.ncols], r))
+	e.n++
+	if e.abort != nil && *e.abort != nil {
+		return true
+	}
+	if e.sort != nil {
+		// with an OrderBy the limit is used in flush()
+		return e.sort.err != nil
+	}
+	return e.limit > 0 && e.n >= e.limit
+}
+
+// stop remembers the position of the row which reached the Limit.
+func (e *emitter) stop(pos sdb.Record) bool {
+	e.last = sdb.Record(copyRow(Row(pos)))
+	return true
+}
+
+// flush passes on the sorted rows, if there is an OrderBy. Call this after the
+// scan is done.
+func (e *emitter) flush() error {
+	if e.sort == nil {
+		return nil
+	}
+	return e.sort.flush(e.nout, e.limit, e.out)
+}
+
+// close cleans up any temporary files.
+func (e *emitter) close() {
+	if e.sort != nil {
+		e.sort.close()
 	}
-	return row
 }
 
 // given column names returns the index in a Row this column is expected, and
@@ -38,9 +166,8 @@ func toColumnIndexRowid(s *sdb.Schema, columns []string) ([]columnIndex, error)
 	for _, c := range columns {
 		n := s.Column(c)
 		if n < 0 {
-			cup := strings.ToUpper(c)
-			if cup == "ROWID" || cup == "OID" || cup == "_ROWID_" {
-				res = append(res, columnIndex{nil, n, true})
+			if isRowidName(c) {
+				res = append(res, columnIndex{rowIndex: n, rowid: true})
 				continue
 			} else {
 				return nil, fmt.Errorf("no such column: %q", c)
@@ -48,14 +175,30 @@ func toColumnIndexRowid(s *sdb.Schema, columns []string) ([]columnIndex, error)
 		}
 		c := &s.Columns[n]
 		if c.Rowid {
-			res = append(res, columnIndex{nil, n, true})
+			res = append(res, columnIndex{rowIndex: n, rowid: true})
 		} else {
-			res = append(res, columnIndex{c, n, false})
+			res = append(res, newColumnIndex(c, n))
 		}
 	}
 	return res, nil
 }
 
+// isRowidName is true for the special column names which refer to the rowid, if
+// there is no real column with that name.
+func isRowidName(c string) bool {
+	cup := strings.ToUpper(c)
+	return cup == "ROWID" || cup == "OID" || cup == "_ROWID_"
+}
+
+// normCollate gives the lowercase name of a collate function, with the
+// default filled in.
+func normCollate(c string) string {
+	if c == "" {
+		return sdb.DefaultCollate
+	}
+	return strings.ToLower(c)
+}
+
 // given column names returns the index of this column in a row in the index (and
 // the column definition). For non-rowid tables the database order of the
 // columns depends on the primary key.
@@ -67,7 +210,7 @@ func toColumnIndexNonRowid(s *sdb.Schema, columns []string) ([]columnIndex, erro
 		if n < 0 {
 			return nil, fmt.Errorf("no such column: %q", c)
 		}
-		res = append(res, columnIndex{&s.Columns[n], stored[n], false})
+		res = append(res, newColumnIndex(&s.Columns[n], stored[n]))
 	}
 	return res, nil
 }
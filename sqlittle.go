@@ -1,14 +1,20 @@
 package sqlittle
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"sync"
 
 	sdb "github.com/hackborn/sqlittle/db"
 )
 
 type DB struct {
-	db *sdb.Database
+	db       *sdb.Database
+	mu       sync.Mutex    // for closed and watchers.Add()
+	closed   chan struct{} // closed by Close(), to stop the watchers
+	watchers sync.WaitGroup
+	scanOpts ScanOptions
 }
 
 // Open a sqlite file. It can be concurrently written to by SQLite in other
@@ -19,20 +25,87 @@ func Open(filename string) (*DB, error) {
 		return nil, err
 	}
 	return &DB{
-		db: db,
+		db:     db,
+		closed: make(chan struct{}),
 	}, nil
 }
 
-// Close the database file
+// Close the database file. It stops all Watch() channels first.
 func (db *DB) Close() error {
+	db.mu.Lock()
+	select {
+	case <-db.closed:
+	default:
+		close(db.closed)
+	}
+	db.mu.Unlock()
+	db.watchers.Wait()
 	return db.db.Close()
 }
 
+// SetReopen makes the database follow the file when it's replaced by a new
+// file which is renamed over the old path, as deploy tools often do. Without
+// it the database keeps reading the old file. cb, if not nil, is called after
+// every reopen. Set it before the database is used. See db.SetReopen() for the
+// details.
+func (db *DB) SetReopen(on bool, cb func()) {
+	db.db.SetReopen(on, cb)
+}
+
 // RowCB is the callback called for every matching row in the various
 // select-like functions. Use `Scan()` on the `Row` argument to read row
 // values.
 type RowCB func(Row)
 
+// Options for SelectWith(), IndexedSelectWith(), and IndexedSelectEqWith().
+// The zero value gives the same results as the functions without options.
+type Options struct {
+	// Where skips every row for which the filter isn't true.
+	//    Options{Where: Or(Like("word", "a%"), Gt("length", 10))}
+	Where Filter
+	// OrderBy sorts the rows, instead of returning them in table or index
+	// order. Values are ordered the way SQLite orders them: NULLs first, then
+	// numbers, then text (using the collate function), then blobs. Rows which
+	// sort the same keep their table or index order.
+	//    Options{OrderBy: []Order{Desc("length"), Asc("word")}}
+	OrderBy []Order
+	// SortMemory is roughly how many bytes of rows OrderBy keeps in memory.
+	// When there are more rows they are sorted in parts which are written
+	// to temporary files, and merged at the end. 0 means DefaultSortMemory.
+	SortMemory int
+	// Limit stops after this many rows. 0 means no limit.
+	Limit int
+	// After continues a previous select after its last row, using the token
+	// it gave in Next. The token must come from the same table, or for the
+	// indexed selects from the same index. This can't be used with OrderBy.
+	After Token
+	// Next, if not nil, is set to the token of the last row when Limit stopped
+	// the select, and to "" otherwise. Give it as After to get the next page,
+	// which can be empty.
+	//    var next Token
+	//    opts := Options{Limit: 100, Next: &next}
+	//    db.SelectWith("words", opts, cb, "word")
+	//    opts.After = next
+	//    db.SelectWith("words", opts, cb, "word")
+	Next *Token
+	// SkipScan is only used by IndexedSelectEqWith(). It's the number of
+	// leading index columns which are skipped: the key is compared against
+	// the index columns after them. For every distinct value of the skipped
+	// columns the index is searched for that value followed by the key, so
+	// this is fast when the skipped columns have few distinct values.
+	//    // index on (customer, created), all orders from a day
+	//    db.IndexedSelectEqWith("orders", "orders_customer", Key{day},
+	//        Options{SkipScan: 1}, cb, "id")
+	SkipScan int
+	// Named, if not nil, is called for every row after the callback, with
+	// the row as a NamedRow, which knows the names and declared types of
+	// its columns. The callback can be nil when this is set.
+	//    db.SelectWith("words", Options{Named: func(r NamedRow) {
+	//        fmt.Println(r.Get("word"))
+	//    }}, nil, "word", "length")
+	Named NamedRowCB
+}
+
 // Select the columns from every row from the given table. Order is the rowid
 // order for rowid tables, and the ordered primary key for non-rowid tables
 // (`WITHOUT ROWID`).
@@ -40,57 +113,127 @@ type RowCB func(Row)
 // For rowid tables the special values "rowid", "oid", and "_rowid_" will load
 // the rowid (unless there is a column with that name).
 func (db *DB) Select(table string, cb RowCB, columns ...string) error {
-	if err := db.db.RLock(); err != nil {
+	return db.SelectWith(table, Options{}, cb, columns...)
+}
+
+// SelectContext is Select() with a context. When the context is done the
+// select stops, the database lock is released, and ctx.Err() is returned. The
+// context is checked before every page read, so at most a page of rows is
+// given after the context is done.
+func (db *DB) SelectContext(ctx context.Context, table string, cb RowCB, columns ...string) error {
+	return db.SelectWithContext(ctx, table, Options{}, cb, columns...)
+}
+
+// SelectWith is Select() with options.
+func (db *DB) SelectWith(table string, opts Options, cb RowCB, columns ...string) error {
+	return db.SelectWithContext(context.Background(), table, opts, cb, columns...)
+}
+
+// SelectWithContext is SelectWith() with a context. See SelectContext().
+func (db *DB) SelectWithContext(ctx context.Context, table string, opts Options, cb RowCB, columns ...string) (err error) {
+	d, c, err := db.rlock(ctx, "Select", table, "")
+	if err != nil {
+		return err
+	}
+	defer c.end(&err)
+	cb = c.count(cb)
+
+	s, err := d.Schema(table)
+	if err != nil {
 		return err
 	}
-	defer db.db.RUnlock()
 
-	s, err := db.db.Schema(table)
+	e, all, err := newEmitter(s, opts, db.scanOpts, cb, columns)
 	if err != nil {
 		return err
 	}
+	defer e.close()
+	c.e = e
+	kind := tableToken(s)
+	if err := e.resume(opts, kind, s.Table, ""); err != nil {
+		return err
+	}
 
 	if s.WithoutRowid {
-		return selectNonRowid(db.db, s, cb, columns)
+		err = selectNonRowid(d, s, e, all)
 	} else {
-		return select_(db.db, s, cb, columns)
+		err = select_(d, s, e, all)
 	}
+	if err != nil {
+		return err
+	}
+	if err := e.next(opts, kind, s.Table, ""); err != nil {
+		return err
+	}
+	return e.flush()
 }
 
 // Select by rowid. Returns a nil row if the rowid isn't found.
 // Returns an error on a non-rowid table ('WITHOUT ROWID').
 func (db *DB) SelectRowid(table string, rowid int64, columns ...string) (Row, error) {
-	if err := db.db.RLock(); err != nil {
+	return db.SelectRowidContext(context.Background(), table, rowid, columns...)
+}
+
+// SelectRowidContext is SelectRowid() with a context. See SelectContext().
+func (db *DB) SelectRowidContext(ctx context.Context, table string, rowid int64, columns ...string) (row Row, err error) {
+	d, c, err := db.rlock(ctx, "SelectRowid", table, "")
+	if err != nil {
 		return nil, err
 	}
-	defer db.db.RUnlock()
+	defer c.end(&err)
 
-	s, err := db.db.Schema(table)
+	s, err := d.Schema(table)
 	if err != nil {
 		return nil, err
 	}
 	if s.WithoutRowid {
 		return nil, errors.New("can't use SelectRowid on a WITHOUT ROWID table")
 	}
-	return selectRowid(db.db, s, rowid, columns)
+	row, err = selectRowid(d, s, rowid, columns)
+	if row != nil {
+		c.rows = 1
+		row = c.withOptions(row)
+	}
+	return row, err
 }
 
 // Select all rows from the given table via the index. The order will be the
 // index order (every `DESC` field will iterate in descending order).
 //
-// `columns` are the name of the columns you want, their values always come
-// from the data table. Index columns can have expressions, but that doesn't do
-// anything (except maybe change the order).
+// `columns` are the name of the columns you want. If the index stores all
+// those columns (the rowid is always stored) the values are read from the index
+// alone, otherwise they come from the data table. Index columns can have
+// expressions, but that doesn't do anything (except maybe change the order).
 //
 // If the index has a WHERE expression only the rows matching that expression
 // will be matched.
 func (db *DB) IndexedSelect(table, index string, cb RowCB, columns ...string) error {
-	if err := db.db.RLock(); err != nil {
+	return db.IndexedSelectWith(table, index, Options{}, cb, columns...)
+}
+
+// IndexedSelectContext is IndexedSelect() with a context. See SelectContext().
+func (db *DB) IndexedSelectContext(ctx context.Context, table, index string, cb RowCB, columns ...string) error {
+	return db.IndexedSelectWithContext(ctx, table, index, Options{}, cb, columns...)
+}
+
+// IndexedSelectWith is IndexedSelect() with options. The columns used in
+// opts.Where count as requested columns when deciding whether the index has
+// all the columns.
+func (db *DB) IndexedSelectWith(table, index string, opts Options, cb RowCB, columns ...string) error {
+	return db.IndexedSelectWithContext(context.Background(), table, index, opts, cb, columns...)
+}
+
+// IndexedSelectWithContext is IndexedSelectWith() with a context. See
+// SelectContext().
+func (db *DB) IndexedSelectWithContext(ctx context.Context, table, index string, opts Options, cb RowCB, columns ...string) (err error) {
+	d, c, err := db.rlock(ctx, "IndexedSelect", table, index)
+	if err != nil {
 		return err
 	}
-	defer db.db.RUnlock()
+	defer c.end(&err)
+	cb = c.count(cb)
 
-	s, err := db.db.Schema(table)
+	s, err := d.Schema(table)
 	if err != nil {
 		return fmt.Errorf("schema err: %s", err)
 	}
@@ -100,11 +243,28 @@ func (db *DB) IndexedSelect(table, index string, cb RowCB, columns ...string) er
 		return fmt.Errorf("no such index: %q", index)
 	}
 
+	e, all, err := newEmitter(s, opts, db.scanOpts, cb, columns)
+	if err != nil {
+		return err
+	}
+	defer e.close()
+	c.e = e
+	if err := e.resume(opts, tokenIndex, s.Table, ind.Index); err != nil {
+		return err
+	}
+
 	if s.WithoutRowid {
-		return indexedSelectNonRowid(db.db, s, ind, cb, columns)
+		err = indexedSelectNonRowid(d, s, ind, e, all)
 	} else {
-		return indexedSelect(db.db, s, ind, cb, columns)
+		err = indexedSelect(d, s, ind, e, all)
+	}
+	if err != nil {
+		return err
 	}
+	if err := e.next(opts, tokenIndex, s.Table, ind.Index); err != nil {
+		return err
+	}
+	return e.flush()
 }
 
 // Select all rows matching key from the given table via the index. The order
@@ -129,12 +289,31 @@ func (db *DB) IndexedSelect(table, index string, cb RowCB, columns ...string) er
 // If the index has a WHERE expression only the rows matching that expression
 // will be matched.
 func (db *DB) IndexedSelectEq(table, index string, key Key, cb RowCB, columns ...string) error {
-	if err := db.db.RLock(); err != nil {
+	return db.IndexedSelectEqWith(table, index, key, Options{}, cb, columns...)
+}
+
+// IndexedSelectEqContext is IndexedSelectEq() with a context. See
+// SelectContext().
+func (db *DB) IndexedSelectEqContext(ctx context.Context, table, index string, key Key, cb RowCB, columns ...string) error {
+	return db.IndexedSelectEqWithContext(ctx, table, index, key, Options{}, cb, columns...)
+}
+
+// IndexedSelectEqWith is IndexedSelectEq() with options.
+func (db *DB) IndexedSelectEqWith(table, index string, key Key, opts Options, cb RowCB, columns ...string) error {
+	return db.IndexedSelectEqWithContext(context.Background(), table, index, key, opts, cb, columns...)
+}
+
+// IndexedSelectEqWithContext is IndexedSelectEqWith() with a context. See
+// SelectContext().
+func (db *DB) IndexedSelectEqWithContext(ctx context.Context, table, index string, key Key, opts Options, cb RowCB, columns ...string) (err error) {
+	d, c, err := db.rlock(ctx, "IndexedSelectEq", table, index)
+	if err != nil {
 		return err
 	}
-	defer db.db.RUnlock()
+	defer c.end(&err)
+	cb = c.count(cb)
 
-	s, err := db.db.Schema(table)
+	s, err := d.Schema(table)
 	if err != nil {
 		return fmt.Errorf("schema err: %s", err)
 	}
@@ -144,16 +323,43 @@ func (db *DB) IndexedSelectEq(table, index string, key Key, cb RowCB, columns ..
 		return fmt.Errorf("no such index: %q", index)
 	}
 
-	dbkey, err := asDbKey(key, ind.Columns)
+	e, all, err := newEmitter(s, opts, db.scanOpts, cb, columns)
 	if err != nil {
 		return err
 	}
+	defer e.close()
+	c.e = e
+	if opts.SkipScan > 0 && (opts.After != "" || opts.Next != nil) {
+		return errors.New("After and Next can't be used with SkipScan")
+	}
+	if err := e.resume(opts, tokenIndex, s.Table, ind.Index); err != nil {
+		return err
+	}
 
-	if s.WithoutRowid {
-		return indexedSelectEqNonRowid(db.db, s, ind, dbkey, cb, columns)
+	if opts.SkipScan > 0 {
+		err = indexedSkipScan(d, s, ind, opts.SkipScan, key, e, all)
 	} else {
-		return indexedSelectEq(db.db, s, ind, dbkey, cb, columns)
+		if key, err = keyValues(s, ind.Columns, key, e.scan); err != nil {
+			return err
+		}
+		var dbkey sdb.Key
+		dbkey, err = asDbKey(key, ind.Columns)
+		if err != nil {
+			return err
+		}
+		if s.WithoutRowid {
+			err = indexedSelectEqNonRowid(d, s, ind, dbkey, e, all)
+		} else {
+			err = indexedSelectEq(d, s, ind, dbkey, e, all)
+		}
+	}
+	if err != nil {
+		return err
 	}
+	if err := e.next(opts, tokenIndex, s.Table, ind.Index); err != nil {
+		return err
+	}
+	return e.flush()
 }
 
 // Select rows via a Primary Key lookup.
@@ -166,19 +372,191 @@ func (db *DB) IndexedSelectEq(table, index string, key Key, cb RowCB, columns ..
 // PKSelect is especially efficient for non-rowid tables (`WITHOUT ROWID`), and
 // for rowid tables which have a single 'integer primary key' column.
 func (db *DB) PKSelect(table string, key Key, cb RowCB, columns ...string) error {
-	if err := db.db.RLock(); err != nil {
+	return db.PKSelectContext(context.Background(), table, key, cb, columns...)
+}
+
+// PKSelectContext is PKSelect() with a context. See SelectContext().
+func (db *DB) PKSelectContext(ctx context.Context, table string, key Key, cb RowCB, columns ...string) (err error) {
+	d, c, err := db.rlock(ctx, "PKSelect", table, "")
+	if err != nil {
 		return err
 	}
-	defer db.db.RUnlock()
+	defer c.end(&err)
+	cb = c.count(cb)
 
-	s, err := db.db.Schema(table)
+	s, err := d.Schema(table)
 	if err != nil {
 		return err
 	}
 
+	e := &emitter{ncols: len(columns), cb: cb, scan: db.scanOpts}
+	c.e = e
 	if s.WithoutRowid {
-		return pkSelectNonRowid(db.db, s, key, cb, columns)
+		return pkSelectNonRowid(d, s, key, e, columns)
 	} else {
-		return pkSelect(db.db, s, key, cb, columns)
+		return pkSelect(d, s, key, e, columns)
+	}
+}
+
+// Indexes lists all indexes on a table which can be used with IndexedSelect()
+// and IndexedSelectEq(). Indexes SQLite creates for `PRIMARY KEY` and `UNIQUE`
+// constraints (the `sqlite_autoindex_...` ones) are included.
+//
+// An `integer primary key` column is an alias for the rowid, and doesn't have
+// an index. The primary key of a `WITHOUT ROWID` table is the table itself;
+// use PKSelect() for that one.
+func (db *DB) Indexes(table string) ([]IndexInfo, error) {
+	return db.IndexesContext(context.Background(), table)
+}
+
+// IndexesContext is Indexes() with a context. See SelectContext().
+func (db *DB) IndexesContext(ctx context.Context, table string) (ind []IndexInfo, err error) {
+	d, c, err := db.rlock(ctx, "Indexes", table, "")
+	if err != nil {
+		return nil, err
+	}
+	defer c.end(&err)
+
+	s, err := d.Schema(table)
+	if err != nil {
+		return nil, err
+	}
+	return indexes(s), nil
+}
+
+// FindIndex finds an index on the table which starts with the given columns,
+// in that order. Column names are case insensitive. Returns nil if there is no
+// such index.
+//
+// Partial indexes (indexes with a `WHERE`) are never returned, since they don't
+// have every row. If there are multiple candidates the index with the fewest
+// columns wins.
+//
+// This is useful to find the `sqlite_autoindex_...` indexes SQLite makes for
+// `PRIMARY KEY` and `UNIQUE` constraints:
+//    CREATE TABLE foo (a, b, UNIQUE(a, b))
+//    db.FindIndex("foo", "a", "b") // gives "sqlite_autoindex_foo_1"
+func (db *DB) FindIndex(table string, columns ...string) (*IndexInfo, error) {
+	return db.FindIndexContext(context.Background(), table, columns...)
+}
+
+// FindIndexContext is FindIndex() with a context. See SelectContext().
+func (db *DB) FindIndexContext(ctx context.Context, table string, columns ...string) (info *IndexInfo, err error) {
+	d, c, err := db.rlock(ctx, "FindIndex", table, "")
+	if err != nil {
+		return nil, err
+	}
+	defer c.end(&err)
+
+	s, err := d.Schema(table)
+	if err != nil {
+		return nil, err
+	}
+	ind := findIndex(s, columns)
+	if ind == nil {
+		return nil, nil
+	}
+	i := newIndexInfo(ind)
+	return &i, nil
+}
+
+// Where selects all rows matching all the conditions. The best index is picked
+// automatically: the rowid, the primary key, or any index on the table.
+// Collate functions are taken into account, and partial indexes (indexes with
+// a `WHERE`) are only used when the conditions guarantee that all matching rows
+// are in the index. If no index can be used this will do a full table scan.
+//
+// The order of the rows depends on which index is used. See Explain() to find
+// out which one that is.
+//
+//    db.Where("words", []Cond{Eq("length", 3), Gt("word", "b")}, cb, "word")
+func (db *DB) Where(table string, conds []Cond, cb RowCB, columns ...string) error {
+	return db.WhereContext(context.Background(), table, conds, cb, columns...)
+}
+
+// WhereContext is Where() with a context. See SelectContext().
+func (db *DB) WhereContext(ctx context.Context, table string, conds []Cond, cb RowCB, columns ...string) error {
+	return db.WhereWithContext(ctx, table, conds, Options{}, cb, columns...)
+}
+
+// WhereWith is Where() with options. Options.Where must be true as well as all
+// the conditions, but only the conditions are used to pick the index. After,
+// Next, and SkipScan can't be used, since the index isn't known in advance.
+func (db *DB) WhereWith(table string, conds []Cond, opts Options, cb RowCB, columns ...string) error {
+	return db.WhereWithContext(context.Background(), table, conds, opts, cb, columns...)
+}
+
+// WhereWithContext is WhereWith() with a context. See SelectContext().
+func (db *DB) WhereWithContext(ctx context.Context, table string, conds []Cond, opts Options, cb RowCB, columns ...string) (err error) {
+	if opts.After != "" || opts.Next != nil || opts.SkipScan != 0 {
+		return errors.New("After, Next, and SkipScan can't be used with Where")
+	}
+	d, c, err := db.rlock(ctx, "Where", table, "")
+	if err != nil {
+		return err
+	}
+	defer c.end(&err)
+	cb = c.count(cb)
+
+	s, err := d.Schema(table)
+	if err != nil {
+		return err
+	}
+	return where(d, s, c, conds, opts, cb, columns)
+}
+
+// where is WhereWith() on a locked database.
+func where(d *sdb.Database, s *sdb.Schema, c *call, conds []Cond, opts Options, cb RowCB, columns []string) error {
+	cs, err := resolveConds(s, conds, c.db.scanOpts)
+	if err != nil {
+		return err
+	}
+	f := condsFilter(conds)
+	if opts.Where != nil {
+		f = And(f, opts.Where)
+	}
+	opts.Where = f
+	e, all, err := newEmitter(s, opts, c.db.scanOpts, cb, columns)
+	if err != nil {
+		return err
+	}
+	defer e.close()
+	c.e = e
+	if err := makePlan(s, cs, all).run(d, e, all); err != nil {
+		return err
+	}
+	return e.flush()
+}
+
+// Explain describes how Where() would find the rows, in the same format as
+// SQLite's `EXPLAIN QUERY PLAN`. For example:
+//    SCAN words
+//    SEARCH words USING INDEX words_length (length=? AND word>?)
+//    SEARCH words USING INTEGER PRIMARY KEY (rowid=?)
+func (db *DB) Explain(table string, conds []Cond, columns ...string) (string, error) {
+	return db.ExplainContext(context.Background(), table, conds, columns...)
+}
+
+// ExplainContext is Explain() with a context. See SelectContext().
+func (db *DB) ExplainContext(ctx context.Context, table string, conds []Cond, columns ...string) (res string, err error) {
+	d, c, err := db.rlock(ctx, "Explain", table, "")
+	if err != nil {
+		return "", err
+	}
+	defer c.end(&err)
+
+	s, err := d.Schema(table)
+	if err != nil {
+		return "", err
+	}
+
+	cs, err := resolveConds(s, conds, db.scanOpts)
+	if err != nil {
+		return "", err
+	}
+	_, all, err := newEmitter(s, Options{Where: condsFilter(conds)}, db.scanOpts, nil, columns)
+	if err != nil {
+		return "", err
 	}
+	return makePlan(s, cs, all).String(), nil
 }
@@ -0,0 +1,237 @@
+package sqlittle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// structField is a struct field which maps to a column.
+type structField struct {
+	name   string // Go name, for errors
+	column string
+	index  []int // for reflect.Value.FieldByIndex()
+}
+
+// cache of struct types to their fields
+var structFields sync.Map // reflect.Type -> []structField
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// fieldsOf gives the columns of a struct type. A column is the `sqlittle:"col"`
+// tag, or the field name. Unexported fields and fields tagged `sqlittle:"-"`
+// are skipped, and the fields of embedded structs (or pointers to structs) are
+// included.
+func fieldsOf(t reflect.Type) []structField {
+	if fs, ok := structFields.Load(t); ok {
+		return fs.([]structField)
+	}
+	fs := appendFields(nil, t, nil)
+	structFields.Store(t, fs)
+	return fs
+}
+
+func appendFields(fs []structField, t reflect.Type, index []int) []structField {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("sqlittle")
+		if tag == "-" {
+			continue
+		}
+		idx := append(append([]int(nil), index...), i)
+		if f.Anonymous && tag == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr && f.PkgPath == "" {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && ft != timeType {
+				fs = appendFields(fs, ft, idx)
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			continue // unexported
+		}
+		column := tag
+		if column == "" {
+			column = f.Name
+		}
+		fs = append(fs, structField{name: f.Name, column: column, index: idx})
+	}
+	return fs
+}
+
+// structType gives the struct type v (a struct or a pointer to one) is.
+func structType(v interface{}) (reflect.Type, error) {
+	t := reflect.TypeOf(v)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("not a struct: %T", v)
+	}
+	return t, nil
+}
+
+// StructColumns gives the columns Row.ScanStruct() expects, in order, for a
+// struct or a pointer to a struct. See Row.ScanStruct() for how fields map to
+// columns.
+func StructColumns(v interface{}) ([]string, error) {
+	t, err := structType(v)
+	if err != nil {
+		return nil, err
+	}
+	fs := fieldsOf(t)
+	cs := make([]string, len(fs))
+	for i, f := range fs {
+		cs[i] = f.column
+	}
+	return cs, nil
+}
+
+// ScanStruct scans a row into the fields of the struct dst points to. The row
+// needs to be selected with the columns from StructColumns():
+//
+//	type Track struct {
+//	    ID     int64 `sqlittle:"id"`
+//	    Name   string
+//	    Length *int // NULL is nil
+//	}
+//	cols, err := sqlittle.StructColumns(Track{})
+//	...
+//	db.Select("tracks", func(r sqlittle.Row) {
+//	    var t Track
+//	    if err := r.ScanStruct(&t); err != nil {
+//	        ...
+//	    }
+//	}, cols...)
+//
+// A field maps to the column in its `sqlittle:"column"` tag, or to the column
+// with the field name, which SQLite compares case-insensitive. Fields tagged
+// with `sqlittle:"-"` and unexported fields are skipped. Fields of embedded
+// structs are used as if they were fields of the struct itself. A field can
+// be any type Scan() supports, so a pointer field is nil for NULL values. Times
+// are read with the same options as Scan() uses.
+func (r Row) ScanStruct(dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("not a pointer to a struct: %T", dst)
+	}
+	return r.scanStruct(r.options(), v.Elem(), fieldsOf(v.Elem().Type()))
+}
+
+func (r Row) scanStruct(o ScanOptions, v reflect.Value, fs []structField) error {
+	if len(r) != len(fs) {
+		return fmt.Errorf("row has %d columns, struct %s has %d fields", len(r), v.Type(), len(fs))
+	}
+	for i, f := range fs {
+		if err := r.scanField(o, i, fieldByIndex(v, f.index)); err != nil {
+			return fmt.Errorf("field %s: %s", f.name, err)
+		}
+	}
+	return nil
+}
+
+// fieldByIndex is v.FieldByIndex(), but it allocates nil embedded pointers.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// scanField scans column i into a struct field.
+func (r Row) scanField(o ScanOptions, i int, f reflect.Value) error {
+	return (Row{r[i]}).ScanWith(o, f.Addr().Interface())
+}
+
+// SelectInto reads all rows of a table into dst, which is a pointer to a
+// slice of structs, or of pointers to structs. The columns are the fields of
+// the struct; see Row.ScanStruct(). Rows are appended to the slice.
+//
+//	var tracks []Track
+//	err := db.SelectInto("tracks", &tracks)
+//
+// It's an error if a field has no column in the table. Times are read with the
+// options from SetScanOptions().
+func (db *DB) SelectInto(table string, dst interface{}) error {
+	return db.SelectIntoContext(context.Background(), table, dst)
+}
+
+// SelectIntoContext is SelectInto() with a context. See SelectContext().
+func (db *DB) SelectIntoContext(ctx context.Context, table string, dst interface{}) (err error) {
+	sv := reflect.ValueOf(dst)
+	if sv.Kind() != reflect.Ptr || sv.IsNil() || sv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("not a pointer to a slice: %T", dst)
+	}
+	sv = sv.Elem()
+	et := sv.Type().Elem()
+	ptr := et.Kind() == reflect.Ptr
+	if ptr {
+		et = et.Elem()
+	}
+	if et.Kind() != reflect.Struct {
+		return fmt.Errorf("not a slice of structs: %T", dst)
+	}
+	return db.selectStructs(ctx, "SelectInto", table, Options{}, et, func(v reflect.Value) bool {
+		if !ptr {
+			v = v.Elem()
+		}
+		sv.Set(reflect.Append(sv, v))
+		return true
+	})
+}
+
+// selectStructs selects the rows of a table into new structs of type t, and
+// passes a pointer to every struct to cb. The select stops when cb returns
+// false, or when a row can't be scanned.
+func (db *DB) selectStructs(ctx context.Context, method, table string, opts Options, t reflect.Type, cb func(reflect.Value) bool) error {
+	if opts.After != "" || opts.Next != nil || opts.SkipScan != 0 {
+		return errors.New("After, Next, and SkipScan can't be used with Where")
+	}
+	fs := fieldsOf(t)
+	if len(fs) == 0 {
+		return errors.New("struct has no fields")
+	}
+
+	var serr error
+	err := stoppable(ctx, func(ctx context.Context, rcb RowCB) (err error) {
+		d, c, err := db.rlock(ctx, method, table, "")
+		if err != nil {
+			return err
+		}
+		defer c.end(&err)
+
+		s, err := d.Schema(table)
+		if err != nil {
+			return err
+		}
+		columns := make([]string, len(fs))
+		for i, f := range fs {
+			if _, _, _, err := lookupColumn(s, f.column); err != nil {
+				return fmt.Errorf("field %s: %s", f.name, err)
+			}
+			columns[i] = f.column
+		}
+		return where(d, s, c, nil, opts, c.count(rcb), columns)
+	}, func(r Row) bool {
+		v := reflect.New(t)
+		if serr = r.scanStruct(db.scanOpts, v.Elem(), fs); serr != nil {
+			return false
+		}
+		return cb(v)
+	})
+	if serr != nil {
+		return serr
+	}
+	return err
+}
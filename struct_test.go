@@ -0,0 +1,163 @@
+package sqlittle
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/andreyvit/diff"
+	"github.com/davecgh/go-spew/spew"
+)
+
+type trackID struct {
+	ID int64 `sqlittle:"id"`
+}
+
+type Track struct {
+	trackID
+	Name    string
+	Length  *int
+	Album   int `sqlittle:"album"`
+	comment string
+	Skip    string `sqlittle:"-"`
+}
+
+func TestStructColumns(t *testing.T) {
+	type Base struct {
+		Created time.Time
+	}
+	type Row struct {
+		*Base
+		time.Time `sqlittle:"t"`
+		Tags      []byte `sqlittle:"tag_list"`
+	}
+	for _, c := range []struct {
+		v    interface{}
+		want []string
+	}{
+		{Track{}, []string{"id", "Name", "Length", "album"}},
+		{&Track{}, []string{"id", "Name", "Length", "album"}},
+		{Row{}, []string{"Created", "t", "tag_list"}},
+	} {
+		have, err := StructColumns(c.v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(have, c.want) {
+			t.Errorf("%T: have %v, want %v", c.v, have, c.want)
+		}
+	}
+
+	if _, err := StructColumns(42); !reflect.DeepEqual(err, errors.New("not a struct: int")) {
+		t.Errorf("have %v", err)
+	}
+}
+
+func TestScanStruct(t *testing.T) {
+	type Base struct {
+		Flag bool
+	}
+	type S struct {
+		*Base
+		N    *int64
+		F    *float64
+		B    []byte
+		When time.Time
+	}
+	l := int64(12)
+	f := 1.5
+	for _, c := range []struct {
+		row  Row
+		want S
+	}{
+		{
+			row:  Row{int64(1), int64(12), 1.5, []byte("hi"), "2018-01-02 03:04:05"},
+			want: S{&Base{true}, &l, &f, []byte("hi"), time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)},
+		},
+		{
+			row:  Row{int64(0), nil, nil, nil, nil},
+			want: S{Base: &Base{false}},
+		},
+	} {
+		var have S
+		if err := c.row.ScanStruct(&have); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(have, c.want) {
+			t.Errorf("diff:\n%s", diff.LineDiff(spew.Sdump(c.want), spew.Sdump(have)))
+		}
+	}
+
+	var s S
+	for _, c := range []struct {
+		row Row
+		dst interface{}
+		err error
+	}{
+		{Row{int64(1)}, &s, errors.New("row has 1 columns, struct sqlittle.S has 5 fields")},
+		{Row{int64(1), "foo", nil, nil, nil}, &s, errors.New(`field N: invalid number: "foo"`)},
+		{Row{}, s, errors.New("not a pointer to a struct: sqlittle.S")},
+		{Row{int64(1)}, &struct{ C chan int }{}, errors.New("field C: unsupported Scan() type: *chan int")},
+	} {
+		if have, want := c.row.ScanStruct(c.dst), c.err; !reflect.DeepEqual(have, want) {
+			t.Errorf("have %v, want %v", have, want)
+		}
+	}
+}
+
+func TestSelectInto(t *testing.T) {
+	db, err := Open("testdata/music.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var tracks []Track
+	if err := db.SelectInto("tracks", &tracks); err != nil {
+		t.Fatal(err)
+	}
+	l := func(n int) *int { return &n }
+	want := []Track{
+		{trackID{1}, "Drive My Car", l(145), 1, "", ""},
+		{trackID{2}, "Norwegian Wood", l(121), 1, "", ""},
+		{trackID{3}, "You Wont See Me", l(198), 1, "", ""},
+		{trackID{4}, "Come Together", l(259), 2, "", ""},
+		{trackID{5}, "Something", l(182), 2, "", ""},
+		{trackID{6}, "Maxwells Silver Hammer", l(207), 2, "", ""},
+	}
+	if !reflect.DeepEqual(tracks, want) {
+		t.Errorf("diff:\n%s", diff.LineDiff(spew.Sdump(want), spew.Sdump(tracks)))
+	}
+
+	type Album struct {
+		Rowid int
+		NAME  string
+	}
+	var albums []*Album
+	if err := db.SelectInto("albums", &albums); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := albums, []*Album{{1, "Rubber Soul"}, {2, "Abbey Road"}}; !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+
+	var s Track
+	for _, c := range []struct {
+		table string
+		dst   interface{}
+		err   error
+	}{
+		{"albums", &tracks, errors.New(`field Length: no such column: "Length"`)},
+		{"nosuch", &tracks, errors.New(`no such table: "nosuch"`)},
+		{"tracks", tracks, errors.New("not a pointer to a slice: []sqlittle.Track")},
+		{"tracks", &s, errors.New("not a pointer to a slice: *sqlittle.Track")},
+		{"tracks", &[]int{}, errors.New("not a slice of structs: *[]int")},
+		{"tracks", &[]struct{ a int }{}, errors.New("struct has no fields")},
+		{"tracks", &[]struct{ Name int }{}, errors.New(`field Name: invalid number: "Drive My Car"`)},
+	} {
+		if have, want := db.SelectInto(c.table, c.dst), c.err; !reflect.DeepEqual(have, want) {
+			t.Errorf("have %v, want %v", have, want)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package sqlittle
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	sdb "github.com/hackborn/sqlittle/db"
+)
+
+// how long Tail waits before it tries again when the database is locked
+const tailRetry = 10 * time.Millisecond
+
+// Tail follows a table which another process appends rows to, like
+// `tail -f`. It calls the callback for every row with a rowid larger than
+// fromRowid, and then waits for the database to change and calls it for every
+// newer row, until the context is done or the database is closed. It returns
+// ctx.Err() then.
+//
+// Only rows with a rowid larger than the largest rowid seen before are given,
+// so changed and deleted rows are not reported, and neither are new rows with
+// a smaller rowid. That's fine for tables which are only inserted into without
+// an explicit rowid.
+//
+// The database is only locked while the new rows are read, and Tail waits while
+// other processes write to it. Changes are noticed
+// the same way as Watch() does it, with DefaultWatchInterval. Returns an error
+// on a non-rowid table ('WITHOUT ROWID').
+//
+//	err := db.Tail(ctx, "log", 0, func(r sqlittle.Row) {
+//	    ...
+//	}, "rowid", "message")
+func (db *DB) Tail(ctx context.Context, table string, fromRowid int64, cb RowCB, columns ...string) error {
+	w, err := db.db.Watcher()
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	ctx, cancel, err := db.watch(ctx)
+	if err != nil {
+		return err
+	}
+	defer db.watchers.Done()
+	defer cancel()
+
+	last := fromRowid
+	for {
+		v, err := db.tail(ctx, table, &last, cb, columns)
+		if err == sdb.ErrLocked {
+			// another process is writing
+			if err := w.Wait(ctx, tailRetry); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		for {
+			if err := w.Wait(ctx, DefaultWatchInterval); err != nil {
+				return err
+			}
+			now, err := db.db.Version()
+			if err != nil {
+				return err
+			}
+			if now.ChangeCounter != v.ChangeCounter {
+				break
+			}
+		}
+	}
+}
+
+// tail gives all rows after rowid last, and updates last. It returns the
+// version of the database the rows came from.
+func (db *DB) tail(ctx context.Context, table string, last *int64, cb RowCB, columns []string) (v sdb.Version, err error) {
+	d, c, err := db.rlock(ctx, "Tail", table, "")
+	if err != nil {
+		return v, err
+	}
+	defer c.end(&err)
+	cb = c.count(cb)
+
+	s, err := d.Schema(table)
+	if err != nil {
+		return v, err
+	}
+	if s.WithoutRowid {
+		return v, errors.New("can't use Tail on a WITHOUT ROWID table")
+	}
+	ci, err := toColumnIndexRowid(s, columns)
+	if err != nil {
+		return v, err
+	}
+	t, err := d.Table(s.Table)
+	if err != nil {
+		return v, err
+	}
+	if *last < math.MaxInt64 {
+		if err := t.ScanMin(*last+1, func(rowid int64, r sdb.Record) bool {
+			cb(toRow(rowid, ci, r))
+			*last = rowid
+			return false
+		}); err != nil {
+			return v, err
+		}
+	}
+	// nothing can be committed while we have the lock, so this is the
+	// version of the rows we just read
+	return d.Version()
+}
@@ -0,0 +1,83 @@
+package sqlittle
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/andreyvit/diff"
+	"github.com/davecgh/go-spew/spew"
+)
+
+func TestTail(t *testing.T) {
+	db, err := Open("testdata/words.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var rows []Row
+	err = db.Tail(ctx, "words", 997, func(r Row) {
+		rows = append(rows, r)
+		if len(rows) == 3 {
+			// no new rows will come
+			cancel()
+		}
+	}, "rowid", "word")
+	if have, want := err, context.Canceled; have != want {
+		t.Errorf("have %v, want %v", have, want)
+	}
+	want := []Row{
+		{int64(998), "sensible"},
+		{int64(999), "boysenberry's"},
+		{int64(1000), "ideologist"},
+	}
+	if have := rows; !reflect.DeepEqual(have, want) {
+		t.Errorf("diff:\n%s", diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+	}
+}
+
+func TestTailClose(t *testing.T) {
+	db, err := Open("testdata/words.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- db.Tail(context.Background(), "words", 1000, func(r Row) {
+			t.Errorf("unexpected row: %v", r)
+		})
+	}()
+	time.Sleep(10 * time.Millisecond)
+	db.Close()
+	select {
+	case err := <-done:
+		if have, want := err, context.Canceled; have != want {
+			t.Errorf("have %v, want %v", have, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Tail didn't stop")
+	}
+}
+
+func TestTailErrors(t *testing.T) {
+	db, err := Open("testdata/withoutrowid.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	cb := func(r Row) {}
+	ctx := context.Background()
+	if have, want := db.Tail(ctx, "words", 0, cb), "can't use Tail on a WITHOUT ROWID table"; have == nil || have.Error() != want {
+		t.Errorf("have %v, want %v", have, want)
+	}
+	if have, want := db.Tail(ctx, "nosuch", 0, cb), errors.New(`no such table: "nosuch"`); !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+}
@@ -0,0 +1,305 @@
+package sqlittle
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ScanOptions change how Scan() reads times and durations. The zero value
+// reads integers as unix seconds, and uses UTC.
+type ScanOptions struct {
+	// Epoch is the unit of integer timestamps, since 1970-01-01 UTC:
+	// time.Second (the default, for 0), time.Millisecond, and so on.
+	Epoch time.Duration
+	// Location is the location of the time.Time values, and of text times
+	// without a timezone. Defaults to UTC.
+	Location *time.Location
+}
+
+func (o ScanOptions) epoch() time.Duration {
+	if o.Epoch <= 0 {
+		return time.Second
+	}
+	return o.Epoch
+}
+
+func (o ScanOptions) location() *time.Location {
+	if o.Location == nil {
+		return time.UTC
+	}
+	return o.Location
+}
+
+// SetScanOptions sets the options Row.Scan() uses for the rows from this
+// database, and which Rows.Scan(), SelectInto(), and All() use. Set it before
+// the database is used.
+func (db *DB) SetScanOptions(o ScanOptions) {
+	db.scanOpts = o
+}
+
+// ScanOptions gives the options set with SetScanOptions().
+func (db *DB) ScanOptions() ScanOptions {
+	return db.scanOpts
+}
+
+// scanOptions are the options of the database a Row came from. They're kept
+// in the slot after the last value of the Row, so they don't show up as a
+// column.
+type scanOptions struct {
+	ScanOptions
+}
+
+// withOptions gives the row with the options in the slot after its last
+// value. Rows from toRow() have a slot for them; other rows are copied, so
+// values after the end of the row are never overwritten.
+func (r Row) withOptions(o *scanOptions) Row {
+	if cap(r) > len(r) {
+		slot := r[:len(r)+1]
+		if _, ok := slot[len(r)].(*scanOptions); ok {
+			slot[len(r)] = o
+			return r
+		}
+	}
+	return append(r[:len(r):len(r)], o)[:len(r)]
+}
+
+// options gives the options of the database the row came from, or the zero
+// ScanOptions.
+func (r Row) options() ScanOptions {
+	if cap(r) > len(r) {
+		if o, ok := r[:len(r)+1][len(r)].(*scanOptions); ok && o != nil {
+			return o.ScanOptions
+		}
+	}
+	return ScanOptions{}
+}
+
+// fromEpoch gives the time n units after 1970.
+func fromEpoch(n int64, unit time.Duration) time.Time {
+	if unit >= time.Second {
+		return time.Unix(n*int64(unit/time.Second), 0)
+	}
+	per := int64(time.Second / unit)
+	return time.Unix(n/per, n%per*int64(unit))
+}
+
+// toEpoch gives the number of whole units since 1970. It's the inverse of
+// fromEpoch().
+func toEpoch(t time.Time, unit time.Duration) int64 {
+	if unit >= time.Second {
+		return t.Unix() / int64(unit/time.Second)
+	}
+	return t.Unix()*int64(time.Second/unit) + int64(t.Nanosecond())/int64(unit)
+}
+
+// julian day of 1970-01-01 00:00:00 UTC, in milliseconds
+const unixEpochJD = 210866760000000
+
+// fromJulian gives the time of a julian day number, with millisecond
+// precision, the same as SQLite.
+func fromJulian(jd float64) (time.Time, error) {
+	// SQLite only accepts days in the years 0000 to 9999
+	if !(jd >= 0 && jd <= 5373484.5) {
+		return time.Time{}, fmt.Errorf("invalid julian day: %g", jd)
+	}
+	ms := int64(jd*86400000+0.5) - unixEpochJD
+	return time.Unix(ms/1000, ms%1000*int64(time.Millisecond)), nil
+}
+
+// toJulian gives the julian day number of a time, with millisecond precision.
+func toJulian(t time.Time) float64 {
+	ms := t.Unix()*1000 + int64(t.Nanosecond())/int64(time.Millisecond)
+	return float64(ms+unixEpochJD) / 86400000
+}
+
+func (r Row) scanTime(o ScanOptions, i int) (time.Time, error) {
+	var (
+		t   time.Time
+		err error
+	)
+	switch rv := r[i].(type) {
+	case nil:
+		return time.Time{}, nil
+	case int64:
+		t = fromEpoch(rv, o.epoch())
+	case float64:
+		t, err = fromJulian(rv)
+	case string:
+		t, err = parseTime(rv, o.location())
+	case []byte:
+		return time.Time{}, errors.New("BLOB timestamps are invalid")
+	default:
+		panic("impossible")
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.In(o.location()), nil
+}
+
+// parseTime parses the time formats SQLite's date and time functions take:
+//
+//	YYYY-MM-DD
+//	YYYY-MM-DD HH:MM
+//	YYYY-MM-DD HH:MM:SS
+//	YYYY-MM-DD HH:MM:SS.SSS
+//	HH:MM
+//	HH:MM:SS
+//	HH:MM:SS.SSS
+//	DDDDDDDDDD
+//
+// The space can also be a "T", there can be any number of fractional digits,
+// and the time can be followed by "Z" or a "+HH:MM" or "-HH:MM" timezone. The
+// date for only a time is 2000-01-01. The last format is a julian day number.
+// Times without a timezone are in loc.
+func parseTime(s string, loc *time.Location) (time.Time, error) {
+	invalid := fmt.Errorf("invalid time: %q", s)
+	p := strings.TrimSpace(s)
+	if f, err := strconv.ParseFloat(p, 64); err == nil {
+		t, err := fromJulian(f)
+		if err != nil {
+			return time.Time{}, invalid
+		}
+		return t, nil
+	}
+
+	year, month, day := 2000, 1, 1
+	if len(p) >= 10 && p[4] == '-' && p[7] == '-' {
+		var ok bool
+		if year, ok = digits(p[0:4]); !ok {
+			return time.Time{}, invalid
+		}
+		if month, ok = digits(p[5:7]); !ok || month < 1 || month > 12 {
+			return time.Time{}, invalid
+		}
+		if day, ok = digits(p[8:10]); !ok || day < 1 || day > 31 {
+			return time.Time{}, invalid
+		}
+		p = p[10:]
+		if p == "" {
+			return time.Date(year, time.Month(month), day, 0, 0, 0, 0, loc), nil
+		}
+		if p[0] != ' ' && p[0] != 'T' {
+			return time.Time{}, invalid
+		}
+		p = strings.TrimLeft(p[1:], " ")
+	}
+
+	// HH:MM[:SS[.SSS]]
+	if len(p) < 5 || p[2] != ':' {
+		return time.Time{}, invalid
+	}
+	hour, ok := digits(p[0:2])
+	if !ok || hour > 24 {
+		return time.Time{}, invalid
+	}
+	min, ok := digits(p[3:5])
+	if !ok || min > 59 {
+		return time.Time{}, invalid
+	}
+	p = p[5:]
+	sec, nsec := 0, 0
+	if len(p) >= 3 && p[0] == ':' {
+		if sec, ok = digits(p[1:3]); !ok || sec > 59 {
+			return time.Time{}, invalid
+		}
+		p = p[3:]
+		if len(p) > 1 && p[0] == '.' {
+			n := 1
+			for n < len(p) && p[n] >= '0' && p[n] <= '9' {
+				n++
+			}
+			frac := (p[1:n] + "000000000")[:9]
+			nsec, _ = strconv.Atoi(frac)
+			p = p[n:]
+		}
+	}
+
+	// timezone
+	p = strings.TrimLeft(p, " ")
+	switch {
+	case p == "":
+		return time.Date(year, time.Month(month), day, hour, min, sec, nsec, loc), nil
+	case p == "Z" || p == "z":
+		return time.Date(year, time.Month(month), day, hour, min, sec, nsec, time.UTC), nil
+	case len(p) == 6 && (p[0] == '+' || p[0] == '-') && p[3] == ':':
+		h, ok1 := digits(p[1:3])
+		m, ok2 := digits(p[4:6])
+		if !ok1 || !ok2 || h > 14 || m > 59 {
+			return time.Time{}, invalid
+		}
+		offset := h*3600 + m*60
+		if p[0] == '-' {
+			offset = -offset
+		}
+		return time.Date(year, time.Month(month), day, hour, min, sec, nsec, time.FixedZone("", offset)), nil
+	default:
+		return time.Time{}, invalid
+	}
+}
+
+// digits parses a string of only digits.
+func digits(s string) (int, bool) {
+	n := 0
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		n = n*10 + int(c-'0')
+	}
+	return n, true
+}
+
+// scanDuration reads a duration. Integers are in the Epoch unit, reals are
+// seconds, and text is either a Go duration ("1h30m") or a time of the day
+// ("01:30:00").
+func (r Row) scanDuration(o ScanOptions, i int) (time.Duration, error) {
+	switch rv := r[i].(type) {
+	case nil:
+		return 0, nil
+	case int64:
+		unit := o.epoch()
+		if rv > math.MaxInt64/int64(unit) || rv < math.MinInt64/int64(unit) {
+			return 0, fmt.Errorf("duration out of range: %d", rv)
+		}
+		return time.Duration(rv) * unit, nil
+	case float64:
+		if math.Abs(rv) > math.MaxInt64/float64(time.Second) {
+			return 0, fmt.Errorf("duration out of range: %g", rv)
+		}
+		return time.Duration(rv * float64(time.Second)), nil
+	case string:
+		return parseDuration(rv)
+	case []byte:
+		return parseDuration(string(rv))
+	default:
+		panic("impossible")
+	}
+}
+
+func parseDuration(s string) (time.Duration, error) {
+	p := strings.TrimSpace(s)
+	if d, err := time.ParseDuration(p); err == nil {
+		return d, nil
+	}
+	neg := strings.HasPrefix(p, "-")
+	if neg {
+		p = p[1:]
+	}
+	if !strings.Contains(p, ":") || strings.ContainsAny(p, "TZz+-") {
+		return 0, fmt.Errorf("invalid duration: %q", s)
+	}
+	t, err := parseTime(p, time.UTC)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration: %q", s)
+	}
+	d := t.Sub(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC))
+	if neg {
+		d = -d
+	}
+	return d, nil
+}
@@ -0,0 +1,260 @@
+package sqlittle
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	ams, err := time.LoadLocation("Europe/Amsterdam")
+	if err != nil {
+		t.Skip(err)
+	}
+	for _, c := range []struct {
+		s    string
+		want time.Time
+	}{
+		{"2018-03-04", time.Date(2018, 3, 4, 0, 0, 0, 0, ams)},
+		{"2018-03-04 12:34", time.Date(2018, 3, 4, 12, 34, 0, 0, ams)},
+		{"2018-03-04 12:34:56", time.Date(2018, 3, 4, 12, 34, 56, 0, ams)},
+		{"2018-03-04T12:34:56.789", time.Date(2018, 3, 4, 12, 34, 56, 789000000, ams)},
+		{"2018-03-04 12:34:56.123456789123", time.Date(2018, 3, 4, 12, 34, 56, 123456789, ams)},
+		{" 2018-03-04 12:34:56.1 ", time.Date(2018, 3, 4, 12, 34, 56, 100000000, ams)},
+		{"2018-03-04T12:34:56Z", time.Date(2018, 3, 4, 12, 34, 56, 0, time.UTC)},
+		{"2018-03-04 12:34:56 +02:00", time.Date(2018, 3, 4, 10, 34, 56, 0, time.UTC)},
+		{"2018-03-04 12:34-05:30", time.Date(2018, 3, 4, 18, 4, 0, 0, time.UTC)},
+		{"12:34", time.Date(2000, 1, 1, 12, 34, 0, 0, ams)},
+		{"12:34:56.5", time.Date(2000, 1, 1, 12, 34, 56, 500000000, ams)},
+		{"2458181.5", time.Date(2018, 3, 4, 0, 0, 0, 0, time.UTC)},
+	} {
+		have, err := parseTime(c.s, ams)
+		if err != nil {
+			t.Fatalf("%q: %s", c.s, err)
+		}
+		if !have.Equal(c.want) {
+			t.Errorf("%q: have %v, want %v", c.s, have, c.want)
+		}
+	}
+
+	for _, s := range []string{
+		"",
+		"now",
+		"2018-3-4",
+		"2018-13-04",
+		"2018-03-00",
+		"2018-03-04X12:34",
+		"2018-03-04 12",
+		"2018-03-04 12:60",
+		"25:00",
+		"12:34:56 +2",
+		"12:34:56 CET",
+		"-1.5",
+	} {
+		if _, err := parseTime(s, time.UTC); !reflect.DeepEqual(err, errors.New(`invalid time: "`+s+`"`)) {
+			t.Errorf("%q: have %v", s, err)
+		}
+	}
+}
+
+func TestScanOptions(t *testing.T) {
+	ams, err := time.LoadLocation("Europe/Amsterdam")
+	if err != nil {
+		t.Skip(err)
+	}
+	row := Row{int64(1520166896789), "2018-03-04 12:34:56", float64(2458182.024259259), int64(90)}
+	var (
+		t1, t2, t3 time.Time
+		d          time.Duration
+	)
+	if err := row.ScanWith(ScanOptions{Epoch: time.Millisecond, Location: ams}, &t1, &t2, &t3, &d); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		have, want time.Time
+	}{
+		{t1, time.Date(2018, 3, 4, 12, 34, 56, 789000000, time.UTC)},
+		{t2, time.Date(2018, 3, 4, 11, 34, 56, 0, time.UTC)},
+		{t3, time.Date(2018, 3, 4, 12, 34, 56, 0, time.UTC)},
+	} {
+		if !c.have.Equal(c.want) {
+			t.Errorf("have %v, want %v", c.have, c.want)
+		}
+		if c.have.Location() != ams {
+			t.Errorf("have %v, want %v", c.have.Location(), ams)
+		}
+	}
+	if have, want := d, 90*time.Millisecond; have != want {
+		t.Errorf("have %v, want %v", have, want)
+	}
+
+	// defaults
+	if err := row.Scan(&t1, &t2); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := t1, time.Unix(1520166896789, 0).UTC(); have != want {
+		t.Errorf("have %v, want %v", have, want)
+	}
+	if have, want := t2, time.Date(2018, 3, 4, 12, 34, 56, 0, time.UTC); have != want {
+		t.Errorf("have %v, want %v", have, want)
+	}
+}
+
+func TestScanDuration(t *testing.T) {
+	for _, c := range []struct {
+		v    interface{}
+		want time.Duration
+	}{
+		{nil, 0},
+		{int64(90), 90 * time.Second},
+		{float64(1.5), 1500 * time.Millisecond},
+		{"1h30m", 90 * time.Minute},
+		{[]byte("-2s"), -2 * time.Second},
+		{"01:30", 90 * time.Minute},
+		{"-00:00:01.25", -1250 * time.Millisecond},
+	} {
+		var d time.Duration
+		if err := (Row{c.v}).Scan(&d); err != nil {
+			t.Fatalf("%v: %s", c.v, err)
+		}
+		if have, want := d, c.want; have != want {
+			t.Errorf("%v: have %v, want %v", c.v, have, want)
+		}
+	}
+
+	for _, c := range []struct {
+		v   interface{}
+		err error
+	}{
+		{"soon", errors.New(`invalid duration: "soon"`)},
+		{"2018-03-04 12:00", errors.New(`invalid duration: "2018-03-04 12:00"`)},
+		{"12:00Z", errors.New(`invalid duration: "12:00Z"`)},
+		{int64(1 << 40), errors.New("duration out of range: 1099511627776")},
+		{float64(1e20), errors.New("duration out of range: 1e+20")},
+	} {
+		var d time.Duration
+		if have, want := (Row{c.v}).Scan(&d), c.err; !reflect.DeepEqual(have, want) {
+			t.Errorf("%v: have %v, want %v", c.v, have, want)
+		}
+	}
+}
+
+func TestDBScanOptions(t *testing.T) {
+	db, err := Open("testdata/music.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetScanOptions(ScanOptions{Epoch: time.Millisecond})
+
+	rows, err := db.Query("SELECT length FROM tracks WHERE id = 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var d time.Duration
+	if !rows.Next() {
+		t.Fatal("no row")
+	}
+	if err := rows.Scan(&d); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := d, 145*time.Millisecond; have != want {
+		t.Errorf("have %v, want %v", have, want)
+	}
+
+	type Track struct {
+		Length time.Duration
+	}
+	var tracks []Track
+	if err := db.SelectInto("tracks", &tracks); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := tracks[1].Length, 121*time.Millisecond; have != want {
+		t.Errorf("have %v, want %v", have, want)
+	}
+
+	// rows given to a callback
+	var (
+		lengths []time.Duration
+		named   []time.Duration
+		copied  time.Duration
+	)
+	opts := Options{
+		Where: Eq("album", 1),
+		Named: func(r NamedRow) {
+			var d time.Duration
+			if err := r.Scan(&d); err != nil {
+				t.Fatal(err)
+			}
+			named = append(named, d)
+		},
+	}
+	if err := db.SelectWith("tracks", opts, func(r Row) {
+		var d time.Duration
+		if err := r.Scan(&d); err != nil {
+			t.Fatal(err)
+		}
+		lengths = append(lengths, d)
+		var tr Track
+		if err := r.ScanStruct(&tr); err == nil && tr.Length != d {
+			t.Errorf("have %v, want %v", tr.Length, d)
+		}
+		if err := copyRow(r).Scan(&copied); err != nil {
+			t.Fatal(err)
+		}
+	}, "length"); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := lengths[0], 145*time.Millisecond; have != want {
+		t.Errorf("have %v, want %v", have, want)
+	}
+	if have, want := named, lengths; !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+	// a copy doesn't know the database
+	if have, want := copied, time.Duration(lengths[len(lengths)-1]/time.Millisecond)*time.Second; have != want {
+		t.Errorf("have %v, want %v", have, want)
+	}
+
+	// a time read with the options finds the same row as a key
+	var id time.Time
+	if err := db.PKSelect("tracks", Key{2}, func(r Row) {
+		if err := r.Scan(&id); err != nil {
+			t.Fatal(err)
+		}
+	}, "id"); err != nil {
+		t.Fatal(err)
+	}
+	var found []string
+	if err := db.PKSelect("tracks", Key{id}, func(r Row) {
+		found = append(found, r.ScanStrings()[0])
+	}, "name"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SelectWith("tracks", Options{Where: Eq("id", id)}, func(r Row) {
+		found = append(found, r.ScanStrings()[0])
+	}, "name"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Where("tracks", []Cond{{Column: "id", Op: OpEq, Value: id}}, func(r Row) {
+		found = append(found, r.ScanStrings()[0])
+	}, "name"); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := found, []string{"Norwegian Wood", "Norwegian Wood", "Norwegian Wood"}; !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+
+	row, err := db.SelectRowid("albums", 1, "id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var at time.Time
+	if err := row.Scan(&at); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := at, time.UnixMilli(1).UTC(); !have.Equal(want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+}
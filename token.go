@@ -0,0 +1,119 @@
+package sqlittle
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	sdb "github.com/hackborn/sqlittle/db"
+)
+
+// Token is a position in a table or an index, for paging with Options.After
+// and Options.Next. It's opaque, but it's a printable (URL safe) string, so it
+// can be stored or given to a client.
+//
+// The token has the rowid, the primary key, or the index values of the last
+// row. Continuing from a token seeks directly to the row after that, so
+// changes made to the database in the meantime are fine: rows which are added
+// after the position will be found, and rows which are deleted won't be.
+type Token string
+
+// ErrInvalidToken is returned when a Token can't be decoded, or when it's not
+// from the same table or index.
+var ErrInvalidToken = errors.New("invalid token")
+
+const tokenVersion = "sqlittle1"
+
+// kinds of positions
+const (
+	tokenRowid = "r" // table scan: the rowid
+	tokenPK    = "p" // table scan on a WITHOUT ROWID table: primary key values
+	tokenIndex = "i" // index scan: all values from the index record
+)
+
+func newToken(kind, table, index string, pos sdb.Record) (Token, error) {
+	row := Row{tokenVersion, kind, strings.ToLower(table), strings.ToLower(index)}
+	var b bytes.Buffer
+	w := bufio.NewWriter(&b)
+	if err := writeRow(w, append(row, pos...)); err != nil {
+		return "", err
+	}
+	if err := w.Flush(); err != nil {
+		return "", err
+	}
+	return Token(base64.RawURLEncoding.EncodeToString(b.Bytes())), nil
+}
+
+// position decodes the token. It must come from the same kind of scan, on
+// the same table and index.
+func (t Token) position(kind, table, index string) (sdb.Record, error) {
+	b, err := base64.RawURLEncoding.DecodeString(string(t))
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	row, err := readRow(bufio.NewReader(bytes.NewReader(b)), uint64(len(b)))
+	if err != nil || len(row) < 5 ||
+		row[0] != tokenVersion ||
+		row[1] != kind ||
+		row[2] != strings.ToLower(table) ||
+		row[3] != strings.ToLower(index) {
+		return nil, ErrInvalidToken
+	}
+	return sdb.Record(row[4:]), nil
+}
+
+// resume sets the position to continue after from Options.After.
+func (e *emitter) resume(opts Options, kind, table, index string) error {
+	if opts.After == "" {
+		return nil
+	}
+	pos, err := opts.After.position(kind, table, index)
+	if err != nil {
+		return err
+	}
+	e.after = pos
+	return nil
+}
+
+// next sets Options.Next, if it's asked for. It's empty if the scan wasn't
+// stopped by the Limit.
+func (e *emitter) next(opts Options, kind, table, index string) error {
+	if opts.Next == nil {
+		return nil
+	}
+	*opts.Next = ""
+	if e.last == nil {
+		return nil
+	}
+	t, err := newToken(kind, table, index, e.last)
+	if err != nil {
+		return err
+	}
+	*opts.Next = t
+	return nil
+}
+
+// tableToken is the kind of position for table scans.
+func tableToken(s *sdb.Schema) string {
+	if s.WithoutRowid {
+		return tokenPK
+	}
+	return tokenRowid
+}
+
+// afterKey makes the key to continue an index scan after the position. The
+// position is the index record, which has the rowid as last value for rowid
+// tables. For WITHOUT ROWID tables call pkColumns() first, so the index knows
+// about the primary key columns it stores.
+func afterKey(s *sdb.Schema, index *sdb.SchemaIndex, pos sdb.Record) (sdb.Key, error) {
+	cols := index.Columns
+	if !s.WithoutRowid {
+		cols = append(cols[:len(cols):len(cols)], sdb.IndexColumn{})
+	}
+	if len(pos) != len(cols) {
+		return nil, ErrInvalidToken
+	}
+	return asDbKey(Key(pos), cols)
+}
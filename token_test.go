@@ -0,0 +1,190 @@
+package sqlittle
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/andreyvit/diff"
+	"github.com/davecgh/go-spew/spew"
+)
+
+func TestPaging(t *testing.T) {
+	for _, file := range []string{
+		"testdata/words.sqlite",
+		"testdata/withoutrowid.sqlite",
+	} {
+		db, err := Open(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		index := "words_index_2"
+		if file == "testdata/withoutrowid.sqlite" {
+			index = "words_l"
+		}
+		type sel func(opts Options, cb RowCB) error
+		for name, f := range map[string]sel{
+			"select": func(opts Options, cb RowCB) error {
+				return db.SelectWith("words", opts, cb, "word", "length")
+			},
+			"filter": func(opts Options, cb RowCB) error {
+				opts.Where = Like("word", "%e%")
+				return db.SelectWith("words", opts, cb, "word", "length")
+			},
+			"index": func(opts Options, cb RowCB) error {
+				return db.IndexedSelectWith("words", index, opts, cb, "word", "length")
+			},
+			"eq": func(opts Options, cb RowCB) error {
+				return db.IndexedSelectEqWith("words", index, Key{7}, opts, cb, "word", "length")
+			},
+		} {
+			var all [][]string
+			if err := f(Options{}, func(r Row) {
+				all = append(all, r.ScanStrings())
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			var (
+				paged [][]string
+				next  Token
+				pages int
+			)
+			opts := Options{Limit: 37, Next: &next}
+			for {
+				n := 0
+				if err := f(opts, func(r Row) {
+					paged = append(paged, r.ScanStrings())
+					n++
+				}); err != nil {
+					t.Fatalf("%s %s: %s", file, name, err)
+				}
+				if n > opts.Limit {
+					t.Fatalf("%s %s: page too long: %d", file, name, n)
+				}
+				pages++
+				if next == "" {
+					break
+				}
+				opts.After = next
+			}
+			if have, want := paged, all; !reflect.DeepEqual(have, want) {
+				t.Errorf("%s %s: diff:\n%s", file, name, diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+			}
+			if have, want := pages, len(all)/37+1; have != want {
+				t.Errorf("%s %s: have %d pages, want %d", file, name, have, want)
+			}
+		}
+
+		// tokens are for a single table or index
+		var next Token
+		if err := db.SelectWith("words", Options{Limit: 1, Next: &next}, func(Row) {}); err != nil {
+			t.Fatal(err)
+		}
+		if have, want := db.IndexedSelectWith("words", index, Options{After: next}, func(Row) {}), ErrInvalidToken; have != want {
+			t.Errorf("have %v, want %v", have, want)
+		}
+		if err := db.IndexedSelectEqWith("words", index, Key{7}, Options{Limit: 1, Next: &next}, func(Row) {}); err != nil {
+			t.Fatal(err)
+		}
+		if have, want := db.IndexedSelectEqWith("words", index, Key{8}, Options{After: next}, func(Row) {}), ErrInvalidToken; have != want {
+			t.Errorf("have %v, want %v", have, want)
+		}
+		if have, want := db.SelectWith("words", Options{After: "nonsense"}, func(Row) {}), ErrInvalidToken; have != want {
+			t.Errorf("have %v, want %v", have, want)
+		}
+		db.Close()
+	}
+}
+
+func TestLimit(t *testing.T) {
+	db, err := Open("testdata/words.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var words []string
+	cb := func(r Row) {
+		w, _ := r.ScanString()
+		words = append(words, w)
+	}
+	opts := Options{OrderBy: []Order{Desc("length")}, Limit: 3}
+	if err := db.SelectWith("words", opts, cb, "word"); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"bloodthirstiness's", "internationalism's", "unconsciousness's"}
+	if have := words; !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+
+	words = nil
+	opts = Options{SkipScan: 1, Limit: 2}
+	if err := db.IndexedSelectEqWith("words", "words_index_2", Key{"yard"}, opts, cb, "word"); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := words, []string{"yard"}; !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+
+	var next Token
+	for _, c := range []struct {
+		opts Options
+		err  error
+	}{
+		{Options{Limit: -1}, errors.New("invalid Limit: -1")},
+		{Options{OrderBy: []Order{Asc("word")}, Next: &next}, errors.New("After and Next can't be used with OrderBy")},
+	} {
+		if have, want := db.SelectWith("words", c.opts, cb, "word"), c.err; !reflect.DeepEqual(have, want) {
+			t.Errorf("have %v, want %v", have, want)
+		}
+	}
+	if have, want := db.IndexedSelectEqWith("words", "words_index_2", Key{"yard"}, Options{SkipScan: 1, Next: &next}, cb, "word"), errors.New("After and Next can't be used with SkipScan"); !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+}
+
+func TestToken(t *testing.T) {
+	tok, err := newToken(tokenIndex, "Words", "words_index_2", []interface{}{int64(4), "yard", nil, 1.5, []byte("x")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pos, err := tok.position(tokenIndex, "words", "WORDS_INDEX_2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{int64(4), "yard", nil, 1.5, []byte("x")}
+	if have := []interface{}(pos); !reflect.DeepEqual(have, want) {
+		t.Errorf("have %#v, want %#v", have, want)
+	}
+	for _, c := range []struct {
+		kind, table, index string
+	}{
+		{tokenRowid, "words", "words_index_2"},
+		{tokenIndex, "other", "words_index_2"},
+		{tokenIndex, "words", "words_index_1"},
+	} {
+		if _, err := tok.position(c.kind, c.table, c.index); err != ErrInvalidToken {
+			t.Errorf("%v: have %v, want %v", c, err, ErrInvalidToken)
+		}
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(string(tok))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a string with a length of 2^63, and a row with 2^32 columns
+	huge := append([]byte{1, tagText}, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x01)
+	for name, b := range map[string][]byte{
+		"truncated": raw[:len(raw)-3],
+		"length":    huge,
+		"columns":   {0x80, 0x80, 0x80, 0x80, 0x10},
+	} {
+		bad := Token(base64.RawURLEncoding.EncodeToString(b))
+		if _, err := bad.position(tokenIndex, "words", "words_index_2"); err != ErrInvalidToken {
+			t.Errorf("%s: have %v, want %v", name, err, ErrInvalidToken)
+		}
+	}
+}
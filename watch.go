@@ -0,0 +1,142 @@
+package sqlittle
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	sdb "github.com/hackborn/sqlittle/db"
+)
+
+// DefaultWatchInterval is used by Watch() when the interval is 0.
+const DefaultWatchInterval = time.Second
+
+var errClosed = errors.New("database is closed")
+
+// Change is a change to the database file, as found by Watch().
+type Change struct {
+	// Data is set when anything in the database changed. That includes
+	// schema changes.
+	Data bool
+	// Schema is set when a table or index was created, changed, or dropped.
+	Schema bool
+	// The new counters from the file header.
+	ChangeCounter uint32
+	SchemaCookie  uint32
+	// Err is set when the file can't be watched anymore. It's the last
+	// Change on the channel.
+	Err error
+}
+
+// Watch reports changes to the database file, made by any process. It checks
+// the change counter and the schema cookie from the file header every
+// interval. On Linux it also uses inotify, so changes are seen right after
+// the write. The channel is closed when the context is done, or by Close().
+//
+// Changes which come quickly after each other can be reported as a single
+// Change. Watch doesn't lock the database, so a Change can arrive while the
+// transaction is still being written; the next select waits for it to finish.
+//
+//	for c := range db.Watch(ctx, time.Second) {
+//	    if c.Schema {
+//	        ...
+//	    }
+//	}
+func (db *DB) Watch(ctx context.Context, interval time.Duration) <-chan Change {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	ch := make(chan Change, 1)
+	send := func(c Change) bool {
+		select {
+		case ch <- c:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	w, err := db.db.Watcher()
+	if err != nil {
+		ch <- Change{Err: err}
+		close(ch)
+		return ch
+	}
+	ctx, cancel, err := db.watch(ctx)
+	if err != nil {
+		w.Close()
+		ch <- Change{Err: err}
+		close(ch)
+		return ch
+	}
+	last, err := db.db.Version()
+	if err != nil {
+		cancel()
+		db.watchers.Done()
+		w.Close()
+		ch <- Change{Err: err}
+		close(ch)
+		return ch
+	}
+
+	go func() {
+		defer db.watchers.Done()
+		defer close(ch)
+		defer w.Close()
+		defer cancel()
+		for {
+			if err := w.Wait(ctx, interval); err != nil {
+				if ctx.Err() == nil {
+					send(Change{Err: err})
+				}
+				return
+			}
+			v, err := db.db.Version()
+			if err != nil {
+				send(Change{Err: err})
+				return
+			}
+			if v == last {
+				continue
+			}
+			if !send(newChange(last, v)) {
+				return
+			}
+			last = v
+		}
+	}()
+	return ch
+}
+
+func newChange(from, to sdb.Version) Change {
+	return Change{
+		Data:          from.ChangeCounter != to.ChangeCounter,
+		Schema:        from.SchemaCookie != to.SchemaCookie,
+		ChangeCounter: to.ChangeCounter,
+		SchemaCookie:  to.SchemaCookie,
+	}
+}
+
+// watch registers a goroutine which reads the database outside of the select
+// calls, so Close() waits for it. The context is canceled when the database is
+// closed. Call db.watchers.Done() when the goroutine stops.
+func (db *DB) watch(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	select {
+	case <-db.closed:
+		return nil, nil, errClosed
+	default:
+	}
+	db.watchers.Add(1)
+
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-db.closed:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel, nil
+}
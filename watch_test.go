@@ -0,0 +1,75 @@
+package sqlittle
+
+import (
+	"context"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	b, err := ioutil.ReadFile("testdata/words.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := ioutil.TempFile("", "sqlittle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	if _, err := f.Write(b); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := Open(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := db.Watch(ctx, 20*time.Millisecond)
+
+	// header fields: change counter at 24, schema cookie at 40
+	set := func(offset int64, v uint32) {
+		var buf [4]byte
+		binary.BigEndian.PutUint32(buf[:], v)
+		if _, err := f.WriteAt(buf[:], offset); err != nil {
+			t.Fatal(err)
+		}
+	}
+	next := func() Change {
+		select {
+		case c := <-ch:
+			return c
+		case <-time.After(5 * time.Second):
+			t.Fatal("no change")
+		}
+		return Change{}
+	}
+	counter := binary.BigEndian.Uint32(b[24:])
+	cookie := binary.BigEndian.Uint32(b[40:])
+
+	set(24, counter+1)
+	if have, want := next(), (Change{Data: true, ChangeCounter: counter + 1, SchemaCookie: cookie}); have != want {
+		t.Errorf("have %+v, want %+v", have, want)
+	}
+
+	set(40, cookie+1)
+	set(24, counter+2)
+	c := next()
+	if !c.Schema || c.SchemaCookie != cookie+1 {
+		t.Errorf("unexpected change: %+v", c)
+	}
+
+	cancel()
+	for c := range ch {
+		if c.Err != nil {
+			t.Errorf("unexpected error: %s", c.Err)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package sqlittle
+
+import (
+	"fmt"
+
+	sdb "github.com/hackborn/sqlittle/db"
+)
+
+// Op is the comparison in a Cond.
+type Op int
+
+const (
+	OpEq     Op = iota // column = value
+	OpLt               // column < value
+	OpLe               // column <= value
+	OpGt               // column > value
+	OpGe               // column >= value
+	OpIsNull           // column IS NULL. Value is ignored.
+	OpNe               // column != value
+)
+
+func (o Op) String() string {
+	switch o {
+	case OpEq:
+		return "="
+	case OpLt:
+		return "<"
+	case OpLe:
+		return "<="
+	case OpGt:
+		return ">"
+	case OpGe:
+		return ">="
+	case OpIsNull:
+		return "IS NULL"
+	case OpNe:
+		return "!="
+	default:
+		return "???"
+	}
+}
+
+// Cond is a condition on a single column, as used by DB.Where(). Values are
+// compared the way SQLite compares them: with the collate function of the
+// column, and NULL never matches anything (use OpIsNull for that).
+//
+// For rowid tables the column "rowid" (or "oid" or "_rowid_") can be used.
+//
+// A Cond is also a Filter.
+type Cond struct {
+	Column string
+	Op     Op
+	Value  interface{}
+}
+
+// Eq is a shortcut for Cond{column, OpEq, v}
+func Eq(column string, v interface{}) Cond { return Cond{column, OpEq, v} }
+
+// Ne is a shortcut for Cond{column, OpNe, v}
+func Ne(column string, v interface{}) Cond { return Cond{column, OpNe, v} }
+
+// Lt is a shortcut for Cond{column, OpLt, v}
+func Lt(column string, v interface{}) Cond { return Cond{column, OpLt, v} }
+
+// Le is a shortcut for Cond{column, OpLe, v}
+func Le(column string, v interface{}) Cond { return Cond{column, OpLe, v} }
+
+// Gt is a shortcut for Cond{column, OpGt, v}
+func Gt(column string, v interface{}) Cond { return Cond{column, OpGt, v} }
+
+// Ge is a shortcut for Cond{column, OpGe, v}
+func Ge(column string, v interface{}) Cond { return Cond{column, OpGe, v} }
+
+// IsNull is a shortcut for Cond{column, OpIsNull, nil}
+func IsNull(column string) Cond { return Cond{column, OpIsNull, nil} }
+
+// a Cond with everything looked up in the schema
+type cond struct {
+	Cond
+	column  string // name as in the schema
+	rowid   bool   // column is the rowid
+	collate string
+	value   interface{} // Value as a database value
+}
+
+func resolveConds(s *sdb.Schema, conds []Cond, o ScanOptions) ([]cond, error) {
+	res := make([]cond, 0, len(conds))
+	for _, c := range conds {
+		rc, err := resolveCond(s, c, o)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, rc)
+	}
+	return res, nil
+}
+
+func resolveCond(s *sdb.Schema, c Cond, o ScanOptions) (cond, error) {
+	if c.Op < OpEq || c.Op > OpNe {
+		return cond{}, fmt.Errorf("invalid operator: %d", c.Op)
+	}
+	column, rowid, collate, err := lookupColumn(s, c.Column)
+	if err != nil {
+		return cond{}, err
+	}
+	rc := cond{Cond: c, column: column, rowid: rowid, collate: collate}
+	if c.Op != OpIsNull {
+		v, err := toKeyValue(c.Value, columnAffinity(s, column), o)
+		if err != nil {
+			return cond{}, err
+		}
+		rc.value = v
+	}
+	return rc, nil
+}
+
+// lookupColumn finds a column by name. It gives the name as in the schema,
+// whether it's the rowid, and the collate function. For rowid tables the rowid
+// aliases are allowed.
+func lookupColumn(s *sdb.Schema, name string) (string, bool, string, error) {
+	if n := s.Column(name); n >= 0 {
+		col := &s.Columns[n]
+		collate := normCollate(col.Collate)
+		if _, ok := sdb.CollateFuncs[collate]; !ok {
+			return "", false, "", fmt.Errorf("unknown collate function: %q", collate)
+		}
+		return col.Column, col.Rowid, collate, nil
+	}
+	if !s.WithoutRowid && isRowidName(name) {
+		return name, true, sdb.DefaultCollate, nil
+	}
+	return "", false, "", fmt.Errorf("no such column: %q", name)
+}
+
+// test compares a database value
+func (c *cond) test(v interface{}) truth {
+	if c.Op == OpIsNull {
+		return truthOf(v == nil)
+	}
+	if v == nil || c.value == nil {
+		return isNull
+	}
+	n := sdb.Compare(v, c.value, c.collate)
+	switch c.Op {
+	case OpEq:
+		return truthOf(n == 0)
+	case OpLt:
+		return truthOf(n < 0)
+	case OpLe:
+		return truthOf(n <= 0)
+	case OpGt:
+		return truthOf(n > 0)
+	case OpGe:
+		return truthOf(n >= 0)
+	case OpNe:
+		return truthOf(n != 0)
+	default:
+		return isFalse
+	}
+}
+
+func (c Cond) compile(fc *filterCompiler) (matcher, error) {
+	rc, err := resolveCond(fc.schema, c, fc.scan)
+	if err != nil {
+		return nil, err
+	}
+	pos := fc.add(c.Column)
+	return func(rowid int64, ci []columnIndex, r sdb.Record) truth {
+		return rc.test(ci[pos].value(rowid, r))
+	}, nil
+}
+
+// condsFilter is the Filter for DB.Where(): all conditions need to be true.
+func condsFilter(conds []Cond) Filter {
+	fs := make([]Filter, 0, len(conds))
+	for _, c := range conds {
+		fs = append(fs, c)
+	}
+	return And(fs...)
+}
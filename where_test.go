@@ -0,0 +1,253 @@
+package sqlittle
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/andreyvit/diff"
+	"github.com/davecgh/go-spew/spew"
+)
+
+func TestWhere(t *testing.T) {
+	type cas struct {
+		file    string
+		table   string
+		conds   []Cond
+		columns []string
+		explain string
+		want    [][]string
+	}
+	for n, c := range []cas{
+		{
+			file:    "testdata/words.sqlite",
+			table:   "words",
+			conds:   []Cond{Eq("length", 3), Gt("word", "b")},
+			columns: []string{"word"},
+			explain: "SEARCH words USING COVERING INDEX words_index_2 (length=? AND word>?)",
+			want:    [][]string{{"big"}, {"fir"}, {"nab"}, {"pat"}},
+		},
+		{
+			file:    "testdata/words.sqlite",
+			table:   "words",
+			conds:   []Cond{Ge("rowid", 998)},
+			columns: []string{"rowid", "word"},
+			explain: "SEARCH words USING INTEGER PRIMARY KEY (rowid>=?)",
+			want:    [][]string{{"998", "sensible"}, {"999", "boysenberry's"}, {"1000", "ideologist"}},
+		},
+		{
+			file:    "testdata/words.sqlite",
+			table:   "words",
+			conds:   []Cond{Eq("OID", 42.0), Ge("length", 2)},
+			columns: []string{"word"},
+			explain: "SEARCH words USING INTEGER PRIMARY KEY (rowid=?)",
+			want:    [][]string{{"aniseed"}},
+		},
+		{
+			file:    "testdata/music.sqlite",
+			table:   "tracks",
+			conds:   []Cond{Gt("length", 180), Le("length", 200)},
+			columns: []string{"name"},
+			explain: "SEARCH tracks USING INDEX tracks_length (length>? AND length<=?)",
+			want:    [][]string{{"Something"}, {"You Wont See Me"}},
+		},
+		{
+			file:    "testdata/music.sqlite",
+			table:   "tracks",
+			conds:   []Cond{Eq("id", 3), Lt("length", 1000)},
+			columns: []string{"name"},
+			explain: "SEARCH tracks USING PRIMARY KEY (id=?)",
+			want:    [][]string{{"You Wont See Me"}},
+		},
+		{
+			file:    "testdata/withoutrowid.sqlite",
+			table:   "words",
+			conds:   []Cond{Gt("length", 17)},
+			columns: []string{"word", "length"},
+			explain: "SEARCH words USING COVERING INDEX words_l (length>?)",
+			want:    [][]string{{"bloodthirstiness's", "18"}, {"internationalism's", "18"}},
+		},
+		{
+			// partial index can be used
+			file:    "testdata/expr.sqlite",
+			table:   "expr",
+			conds:   []Cond{Gt("name", "goo")},
+			columns: []string{"name"},
+			explain: "SEARCH expr USING COVERING INDEX expr_where (name>?)",
+			want:    [][]string{{"longestnameever"}, {"qqq"}},
+		},
+		{
+			// partial index can't be used
+			file:    "testdata/expr.sqlite",
+			table:   "expr",
+			conds:   []Cond{Ge("name", "foo")},
+			columns: []string{"name"},
+			explain: "SCAN expr",
+			want:    [][]string{{"foo"}, {"qqq"}, {"longestnameever"}},
+		},
+		{
+			file:    "testdata/alter.sqlite",
+			table:   "words",
+			conds:   []Cond{Eq("word", "hangdog")},
+			columns: []string{"something"},
+			explain: "SCAN words",
+			want:    [][]string{{"42"}},
+		},
+		{
+			file:    "testdata/values.sqlite",
+			table:   "things",
+			conds:   []Cond{IsNull("c")},
+			columns: []string{"i"},
+			explain: "SCAN things",
+			want:    [][]string{{"0"}},
+		},
+		{
+			// NULL never matches
+			file:    "testdata/values.sqlite",
+			table:   "things",
+			conds:   []Cond{Eq("c", nil)},
+			columns: []string{"i"},
+			explain: "SCAN things",
+			want:    nil,
+		},
+	} {
+		db, err := Open(c.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		explain, err := db.Explain(c.table, c.conds, c.columns...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if have, want := explain, c.explain; have != want {
+			t.Errorf("case %d: have %q, want %q", n, have, want)
+		}
+
+		var rows [][]string
+		if err := db.Where(c.table, c.conds, func(r Row) {
+			rows = append(rows, r.ScanStrings())
+		}, c.columns...); err != nil {
+			t.Fatal(err)
+		}
+		if have, want := rows, c.want; !reflect.DeepEqual(have, want) {
+			t.Errorf("case %d: diff:\n%s", n, diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+		}
+		db.Close()
+	}
+}
+
+func TestWhereErrors(t *testing.T) {
+	db, err := Open("testdata/withoutrowid.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	cb := func(Row) {}
+	if have, want := db.Where("words", []Cond{Eq("nosuch", 1)}, cb, "word"), errors.New(`no such column: "nosuch"`); !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+	if have, want := db.Where("words", []Cond{Eq("rowid", 1)}, cb, "word"), errors.New(`no such column: "rowid"`); !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+	if have, want := db.Where("words", []Cond{Eq("word", struct{}{})}, cb, "word"), errors.New(`unknown Key datatype: struct {}`); !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+}
+
+func TestWhereWith(t *testing.T) {
+	db, err := Open("testdata/words.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	type cas struct {
+		conds  []Cond
+		opts   Options
+		column string
+		want   []string
+	}
+	for n, c := range []cas{
+		{
+			conds:  []Cond{Ge("rowid", 990)},
+			opts:   Options{Limit: 3},
+			column: "rowid",
+			want:   []string{"990", "991", "992"},
+		},
+		{
+			conds:  []Cond{Eq("length", 3)},
+			opts:   Options{OrderBy: []Order{Desc("word")}, Limit: 2},
+			column: "word",
+			want:   []string{"pat", "nab"},
+		},
+		{
+			conds:  []Cond{Eq("length", 3)},
+			opts:   Options{Where: Like("word", "b%")},
+			column: "word",
+			want:   []string{"Bic", "big"},
+		},
+	} {
+		var rows []string
+		if err := db.WhereWith("words", c.conds, c.opts, func(r Row) {
+			var v string
+			r.Scan(&v)
+			rows = append(rows, v)
+		}, c.column); err != nil {
+			t.Fatal(err)
+		}
+		if have, want := rows, c.want; !reflect.DeepEqual(have, want) {
+			t.Errorf("case %d: have %v, want %v", n, have, want)
+		}
+	}
+
+	if have, want := db.WhereWith("words", nil, Options{SkipScan: 1}, func(Row) {}), errors.New("After, Next, and SkipScan can't be used with Where"); !reflect.DeepEqual(have, want) {
+		t.Errorf("have %v, want %v", have, want)
+	}
+}
+
+func TestWhereDesc(t *testing.T) {
+	// range over a DESC index
+	db, err := Open("testdata/prefix.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	s, err := db.db.Schema("words")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := []Cond{Ge("prefix", "thi"), Lt("prefix", "tho")}
+	conds, err := resolveConds(s, cs, ScanOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := indexPlan(s, s.NamedIndex("words_prefix_desc"), conds)
+	if have, want := p.String(), "SEARCH words USING INDEX words_prefix_desc (prefix>=? AND prefix<?)"; have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+
+	// the plan finds "tho" rows as well, the conditions filter those
+	var words []string
+	e, all, err := newEmitter(s, Options{Where: condsFilter(cs)}, ScanOptions{}, func(r Row) {
+		w, _ := r.ScanString()
+		words = append(words, w)
+	}, []string{"word"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.run(db.db, e, all); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"thinking",
+		"thirteen",
+		"thickest",
+		"third's",
+	}
+	if have, want := words, want; !reflect.DeepEqual(have, want) {
+		t.Errorf("diff:\n%s", diff.LineDiff(spew.Sdump(want), spew.Sdump(have)))
+	}
+}